@@ -0,0 +1,300 @@
+// Package bingo provides the Bingo NoSQL chemical database using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : bingo.go
+// @Software: GoLand
+package bingo
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo -lbingo-nosql
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo -lbingo-nosql
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+#include "bingo-nosql.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+	"github.com/cx-luo/go-indigo/reaction"
+)
+
+// Database type constants
+const (
+	DB_MOLECULE = "molecule"
+	DB_REACTION = "reaction"
+)
+
+// Database represents a Bingo NoSQL database
+// A database is bound to the session of the molecules and reactions it stores, and every call runs in that session
+type Database struct {
+	Handle  int
+	Closed  bool
+	session *session.Session
+}
+
+// Version returns the version of the Bingo library
+func Version() string {
+	cStr := C.bingoVersion()
+	if cStr == nil {
+		return ""
+	}
+	return C.GoString(cStr)
+}
+
+// CreateDatabaseFile creates a new database in the given directory
+// s: the session of the stored objects, see core.Indigo.Session; nil uses the session active on the thread
+// dbType: DB_MOLECULE or DB_REACTION
+// options: e.g. "id: <property-name>" to read record ids from a property, empty for defaults
+func CreateDatabaseFile(s *session.Session, location string, dbType string, options string) (*Database, error) {
+	if err := session.Enter(s); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cLocation := C.CString(location)
+	defer C.free(unsafe.Pointer(cLocation))
+
+	cType := C.CString(dbType)
+	defer C.free(unsafe.Pointer(cType))
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoCreateDatabaseFile(cLocation, cType, cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(0, "create database %s", location)
+	}
+
+	return newDatabase(handle, s), nil
+}
+
+// LoadDatabaseFile opens an existing database from the given directory
+// s: the session of the stored objects, see core.Indigo.Session; nil uses the session active on the thread
+func LoadDatabaseFile(s *session.Session, location string, options string) (*Database, error) {
+	if err := session.Enter(s); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cLocation := C.CString(location)
+	defer C.free(unsafe.Pointer(cLocation))
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoLoadDatabaseFile(cLocation, cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(0, "load database %s", location)
+	}
+
+	return newDatabase(handle, s), nil
+}
+
+// Session returns the session the database belongs to, nil if unbound
+func (db *Database) Session() *session.Session {
+	return db.session
+}
+
+// Close closes the database and flushes it to disk
+// If the session was already closed, the database is gone with it and only marked closed
+func (db *Database) Close() error {
+	if db.Closed || db.Handle < 0 {
+		return nil
+	}
+
+	if db.session.Closed() {
+		db.Closed = true
+		db.Handle = -1
+		return nil
+	}
+	if err := session.Enter(db.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.bingoCloseDatabase(C.int(db.Handle)))
+	if ret < 0 {
+		return session.NativeError(db.Handle, "close database")
+	}
+
+	db.Closed = true
+	db.Handle = -1
+	return nil
+}
+
+// Insert adds a *molecule.Molecule or *reaction.Reaction to the database
+// Returns the id assigned to the new record
+func (db *Database) Insert(obj interface{}) (int, error) {
+	objHandle, _, err := db.enterWith(obj)
+	if err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	id := int(C.bingoInsertRecordObj(C.int(db.Handle), C.int(objHandle)))
+	if id < 0 {
+		return 0, session.NativeError(db.Handle, "insert record")
+	}
+
+	return id, nil
+}
+
+// InsertWithID adds a *molecule.Molecule or *reaction.Reaction to the database under the given id
+func (db *Database) InsertWithID(obj interface{}, id int) (int, error) {
+	objHandle, _, err := db.enterWith(obj)
+	if err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	ret := int(C.bingoInsertRecordObjWithId(C.int(db.Handle), C.int(objHandle), C.int(id)))
+	if ret < 0 {
+		return 0, session.NativeError(db.Handle, "insert record %d", id)
+	}
+
+	return ret, nil
+}
+
+// Delete removes the record with the given id
+func (db *Database) Delete(id int) error {
+	if err := db.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.bingoDeleteRecord(C.int(db.Handle), C.int(id)))
+	if ret < 0 {
+		return session.NativeError(db.Handle, "delete record %d", id)
+	}
+
+	return nil
+}
+
+// GetMolecule loads the record with the given id from a molecule database
+func (db *Database) GetMolecule(id int) (*molecule.Molecule, error) {
+	if err := db.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.bingoGetRecordObj(C.int(db.Handle), C.int(id)))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "get record %d", id)
+	}
+
+	return molecule.FromHandle(handle, db.session), nil
+}
+
+// GetReaction loads the record with the given id from a reaction database
+func (db *Database) GetReaction(id int) (*reaction.Reaction, error) {
+	if err := db.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.bingoGetRecordObj(C.int(db.Handle), C.int(id)))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "get record %d", id)
+	}
+
+	return reaction.FromHandle(handle, db.session), nil
+}
+
+// Optimize rebuilds the database indexes for faster searching
+func (db *Database) Optimize() error {
+	if err := db.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.bingoOptimize(C.int(db.Handle)))
+	if ret < 0 {
+		return session.NativeError(db.Handle, "optimize database")
+	}
+
+	return nil
+}
+
+// enter pins the calling goroutine to the database's session; pair it with session.Exit
+func (db *Database) enter() error {
+	if db.Closed {
+		return indigoerr.Closed("database")
+	}
+	return session.Enter(db.session)
+}
+
+// enterWith enters the database's session for a call on obj, which must belong to the same session
+// An unbound database runs in the session of obj. Returns the handle of obj and the session entered;
+// pair a successful call with session.Exit
+func (db *Database) enterWith(obj interface{}) (int, *session.Session, error) {
+	if db.Closed {
+		return 0, nil, indigoerr.Closed("database")
+	}
+
+	objHandle, objSession, err := objectHandle(obj)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := session.Same(db.session, objSession); err != nil {
+		return 0, nil, err
+	}
+
+	s := db.session
+	if s == nil {
+		s = objSession
+	}
+	if err := session.Enter(s); err != nil {
+		return 0, nil, err
+	}
+	return objHandle, s, nil
+}
+
+// objectHandle returns the Indigo handle and the session of a molecule or reaction
+func objectHandle(obj interface{}) (int, *session.Session, error) {
+	switch v := obj.(type) {
+	case *molecule.Molecule:
+		if v == nil || v.Closed {
+			return 0, nil, indigoerr.Closed("molecule")
+		}
+		return v.Handle, v.Session(), nil
+	case *reaction.Reaction:
+		if v == nil || v.Closed {
+			return 0, nil, indigoerr.Closed("reaction")
+		}
+		return v.Handle, v.Session(), nil
+	default:
+		return 0, nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "unsupported object type %T", obj)
+	}
+}
+
+// newDatabase is a helper function to create a Database object from a handle
+// It sets up the finalizer to ensure proper cleanup
+func newDatabase(handle int, s *session.Session) *Database {
+	db := &Database{
+		Handle:  handle,
+		Closed:  false,
+		session: s,
+	}
+	runtime.SetFinalizer(db, (*Database).Close)
+	return db
+}
@@ -0,0 +1,333 @@
+// Package bingo provides Bingo database search functionality using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : bingo_search.go
+// @Software: GoLand
+package bingo
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo -lbingo-nosql
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo -lbingo-nosql
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -lbingo-nosql -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+#include "bingo-nosql.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+	"github.com/cx-luo/go-indigo/reaction"
+)
+
+// SearchResult iterates over the records found by a database search
+//
+//	res, err := db.SearchSub(query, "")
+//	if err != nil { ... }
+//	defer res.Close()
+//	for res.Next() {
+//		id, err := res.ID()
+//		if err != nil { ... }
+//		mol, err := res.Molecule()
+//		...
+//	}
+//	if err := res.Err(); err != nil { ... }
+type SearchResult struct {
+	handle  int
+	closed  bool
+	err     error
+	session *session.Session // session of the search, shared by the returned records
+}
+
+// SearchSub searches for records containing the query substructure
+// query: a query *molecule.Molecule or *reaction.Reaction
+func (db *Database) SearchSub(query interface{}, options string) (*SearchResult, error) {
+	queryHandle, s, err := db.enterWith(query)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoSearchSub(C.int(db.Handle), C.int(queryHandle), cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "run substructure search")
+	}
+
+	return newSearchResult(handle, s), nil
+}
+
+// SearchExact searches for records exactly matching the query
+func (db *Database) SearchExact(query interface{}, options string) (*SearchResult, error) {
+	queryHandle, s, err := db.enterWith(query)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoSearchExact(C.int(db.Handle), C.int(queryHandle), cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "run exact search")
+	}
+
+	return newSearchResult(handle, s), nil
+}
+
+// SearchSim searches for records with similarity to the query between min and max
+// options: similarity metric, e.g. "tanimoto", "tversky 0.5 0.5" or "euclid-sub"
+func (db *Database) SearchSim(query interface{}, min float32, max float32, options string) (*SearchResult, error) {
+	queryHandle, s, err := db.enterWith(query)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoSearchSim(C.int(db.Handle), C.int(queryHandle), C.float(min), C.float(max), cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "run similarity search")
+	}
+
+	return newSearchResult(handle, s), nil
+}
+
+// SearchSimTopN returns at most limit records most similar to the query with similarity of at least min
+func (db *Database) SearchSimTopN(query interface{}, limit int, min float32, options string) (*SearchResult, error) {
+	queryHandle, s, err := db.enterWith(query)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoSearchSimTopN(C.int(db.Handle), C.int(queryHandle), C.int(limit), C.float(min), cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "run top-N similarity search")
+	}
+
+	return newSearchResult(handle, s), nil
+}
+
+// SearchMolFormula searches for molecules matching a gross formula, e.g. "C6 H6"
+func (db *Database) SearchMolFormula(formula string, options string) (*SearchResult, error) {
+	if err := db.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cFormula := C.CString(formula)
+	defer C.free(unsafe.Pointer(cFormula))
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.bingoSearchMolFormula(C.int(db.Handle), cFormula, cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "run formula search")
+	}
+
+	return newSearchResult(handle, db.session), nil
+}
+
+// EnumerateIDs returns a result iterating over every record in the database
+func (db *Database) EnumerateIDs() (*SearchResult, error) {
+	if err := db.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.bingoEnumerateId(C.int(db.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(db.Handle, "enumerate ids")
+	}
+
+	return newSearchResult(handle, db.session), nil
+}
+
+// Next advances to the next found record
+// Returns false at the end of the results or on error; check Err() afterwards
+func (s *SearchResult) Next() bool {
+	if s.closed || s.err != nil {
+		return false
+	}
+	if err := s.enter(); err != nil {
+		s.err = err
+		return false
+	}
+	defer session.Exit()
+
+	ret := int(C.bingoNext(C.int(s.handle)))
+	if ret < 0 {
+		s.err = session.NativeError(s.handle, "get next result")
+		return false
+	}
+
+	return ret > 0
+}
+
+// Err returns the error that stopped the iteration, if any
+func (s *SearchResult) Err() error {
+	return s.err
+}
+
+// ID returns the id of the current record
+func (s *SearchResult) ID() (int, error) {
+	if err := s.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	id := int(C.bingoGetCurrentId(C.int(s.handle)))
+	if id < 0 {
+		return 0, session.NativeError(s.handle, "get current id")
+	}
+
+	return id, nil
+}
+
+// Similarity returns the similarity value of the current record (similarity searches only)
+func (s *SearchResult) Similarity() (float64, error) {
+	if err := s.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	sim := float64(C.bingoGetCurrentSimilarityValue(C.int(s.handle)))
+	if sim < 0 {
+		return 0, session.NativeError(s.handle, "get current similarity")
+	}
+
+	return sim, nil
+}
+
+// Molecule returns an independent copy of the current record as a molecule
+// The molecule belongs to the session of the search
+func (s *SearchResult) Molecule() (*molecule.Molecule, error) {
+	handle, err := s.currentObject()
+	if err != nil {
+		return nil, err
+	}
+
+	return molecule.FromHandle(handle, s.session), nil
+}
+
+// Reaction returns an independent copy of the current record as a reaction
+// The reaction belongs to the session of the search
+func (s *SearchResult) Reaction() (*reaction.Reaction, error) {
+	handle, err := s.currentObject()
+	if err != nil {
+		return nil, err
+	}
+
+	return reaction.FromHandle(handle, s.session), nil
+}
+
+// EstimateRemaining returns an estimate of the number of results not yet visited
+func (s *SearchResult) EstimateRemaining() (int, error) {
+	if err := s.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	count := int(C.bingoEstimateRemainingResultsCount(C.int(s.handle)))
+	if count < 0 {
+		return 0, session.NativeError(s.handle, "estimate remaining results")
+	}
+
+	return count, nil
+}
+
+// Close ends the search and frees the search object
+// If the session was already closed, the search is gone with it and only marked closed
+func (s *SearchResult) Close() error {
+	if s.closed || s.handle < 0 {
+		return nil
+	}
+
+	if s.session.Closed() {
+		s.closed = true
+		s.handle = -1
+		return nil
+	}
+	if err := session.Enter(s.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.bingoEndSearch(C.int(s.handle)))
+	if ret < 0 {
+		return session.NativeError(s.handle, "end search")
+	}
+
+	s.closed = true
+	s.handle = -1
+	return nil
+}
+
+// enter pins the calling goroutine to the session of the search; pair it with session.Exit
+func (s *SearchResult) enter() error {
+	if s.closed {
+		return indigoerr.Closed("search result")
+	}
+	return session.Enter(s.session)
+}
+
+// currentObject clones the current record so it outlives the next call to Next
+func (s *SearchResult) currentObject() (int, error) {
+	if err := s.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	objHandle := int(C.bingoGetObject(C.int(s.handle)))
+	if objHandle < 0 {
+		return 0, session.NativeError(s.handle, "get current object")
+	}
+	defer C.indigoFree(C.int(objHandle))
+
+	handle := int(C.indigoClone(C.int(objHandle)))
+	if handle < 0 {
+		return 0, session.NativeError(s.handle, "clone current object")
+	}
+
+	return handle, nil
+}
+
+// newSearchResult is a helper function to create a SearchResult object from a handle
+// It sets up the finalizer to ensure proper cleanup
+func newSearchResult(handle int, s *session.Session) *SearchResult {
+	res := &SearchResult{
+		handle:  handle,
+		closed:  false,
+		session: s,
+	}
+	runtime.SetFinalizer(res, (*SearchResult).Close)
+	return res
+}
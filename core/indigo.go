@@ -27,11 +27,12 @@ package core
 */
 import "C"
 import (
-	"errors"
-	"fmt"
+	"log/slog"
 	"runtime"
 	"unsafe"
 
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 	"github.com/cx-luo/go-indigo/molecule"
 )
 
@@ -40,9 +41,14 @@ import (
 
 // Indigo represents a session-bound handle to Indigo C library.
 type Indigo struct {
-	sid uint64
+	sid     uint64
+	session *session.Session // shared by every object created through this instance
 }
 
+// Session identifies the native session owning molecules, reactions and renderers
+// Obtain it with Indigo.Session, e.g. for molecule.FromHandle or render.NewRenderer
+type Session = session.Session
+
 // IndigoObject is a lightweight wrapper around Indigo object handle.
 type IndigoObject struct {
 	id     int
@@ -54,13 +60,21 @@ func IndigoInit() (*Indigo, error) {
 	sid := C.indigoAllocSessionId()
 	if sid == 0 {
 		// try to read last error if available
-		if errStr := lastErrorString(); errStr != "" {
-			return nil, errors.New(errStr)
+		if errStr := session.NativeMessage(); errStr != "" {
+			return nil, indigoerr.Native("alloc session id", errStr, 0)
 		}
-		return nil, fmt.Errorf("indigo: failed to alloc session id, got %v", sid)
+		return nil, indigoerr.New(indigoerr.CATEGORY_INTERNAL, "indigo: failed to alloc session id, got %v", sid)
+	}
+
+	in := &Indigo{sid: uint64(sid), session: session.New(uint64(sid))}
+	if err := in.enter(); err != nil {
+		C.indigoReleaseSessionId(sid)
+		return nil, err
 	}
-	C.indigoSetSessionId(sid)
-	return &Indigo{sid: uint64(sid)}, nil
+	defer session.Exit()
+
+	session.InstallErrorHandler(in.sid)
+	return in, nil
 }
 
 // Close releases session id; call when done with the Indigo instance.
@@ -69,8 +83,14 @@ func (in *Indigo) Close() {
 		return
 	}
 	if in.sid != 0 {
-		in.setSession()
+		if err := in.enter(); err != nil {
+			return
+		}
+		// Objects of the session fail with session.ErrClosed from now on
+		session.Close(in.session)
+		session.SetErrorHandler(in.sid, nil)
 		C.indigoReleaseSessionId(C.ulonglong(in.sid))
+		session.Exit()
 		in.sid = 0
 	}
 }
@@ -79,26 +99,69 @@ func (in *Indigo) GetSessionID() uint64 {
 	return in.sid
 }
 
-// setSession sets the internal session id on the native library for next calls.
-func (in *Indigo) setSession() {
-	// wrap call to C to set session id for this goroutine call
-	C.indigoSetSessionId(C.ulonglong(in.sid))
+// Session returns the session shared by every object created through this instance
+func (in *Indigo) Session() *Session {
+	return in.session
 }
 
-// helper to read last error string from Indigo C API
-func lastErrorString() string {
-	ptr := C.indigoGetLastError()
-	if ptr == nil {
-		return ""
+// resetOptions restores the default values of all options of the session
+func (in *Indigo) resetOptions() error {
+	if err := in.enter(); err != nil {
+		return err
 	}
-	return C.GoString(ptr)
+	defer session.Exit()
+
+	if int(C.indigoResetOptions()) < 0 {
+		return session.NativeError(0, "reset options")
+	}
+	return nil
+}
+
+// freeAllObjects frees every object of the session and rebinds it to a fresh session,
+// so that objects created before fail with session.ErrClosed instead of reaching reused handles
+func (in *Indigo) freeAllObjects() error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	session.Close(in.session)
+	in.session = session.New(in.sid)
+	if int(C.indigoFreeAllObjects()) < 0 {
+		return session.NativeError(0, "free session objects")
+	}
+	return nil
+}
+
+// SetErrorHandler sets a handler receiving every native error raised in this session, nil removes it
+// The handler runs synchronously on the goroutine of the failing call, before the call returns its error,
+// and must not call back into Indigo. Panics of the handler are discarded
+//
+//	in.SetErrorHandler(core.LogErrorHandler(slog.Default()))
+func (in *Indigo) SetErrorHandler(handler func(msg string)) {
+	session.SetErrorHandler(in.sid, handler)
+}
+
+// LogErrorHandler returns an error handler that logs native errors to logger, slog.Default() if nil
+func LogErrorHandler(logger *slog.Logger) func(msg string) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(msg string) {
+		logger.Error("indigo error", "message", msg)
+	}
+}
+
+// enter pins the calling goroutine to the session of this instance; pair it with session.Exit
+func (in *Indigo) enter() error {
+	return session.Enter(in.session)
 }
 
 // checkResultInt checks integer return values (assume 0 means error in this API).
 // Adjust condition depending on actual Indigo C API contract.
 func checkResultInt(res C.int) (int, error) {
 	if res == 0 {
-		return 0, errors.New(lastErrorString())
+		return 0, indigoerr.Native("", session.NativeMessage(), 0)
 	}
 	return int(res), nil
 }
@@ -106,14 +169,18 @@ func checkResultInt(res C.int) (int, error) {
 // checkResultInt64 for functions returning long/int handles
 func checkResultLong(res C.long) (int, error) {
 	if res == 0 {
-		return 0, errors.New(lastErrorString())
+		return 0, indigoerr.Native("", session.NativeMessage(), 0)
 	}
 	return int(res), nil
 }
 
 // NameToStructure converts a chemical name into a structure using Indigo's parser options
 func (in *Indigo) NameToStructure(name string, params string) (*molecule.Molecule, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 
@@ -125,17 +192,21 @@ func (in *Indigo) NameToStructure(name string, params string) (*molecule.Molecul
 
 	handle := int(C.indigoNameToStructure(cName, cParams))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to convert name to structure: %s", lastErrorString())
+		return nil, session.NativeError(0, "convert name to structure")
 	}
 
-	return newMolecule(handle), nil
+	return in.newMolecule(handle), nil
 }
 
 // Deserialize creates molecule/reaction object from binary serialized CMF format.
 func (in *Indigo) Deserialize(arr []byte) (*IndigoObject, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
 	if len(arr) == 0 {
-		return nil, errors.New("indigo: empty buffer")
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "empty buffer")
 	}
 	cbuf := C.CBytes(arr)
 	defer C.free(cbuf)
@@ -149,7 +220,11 @@ func (in *Indigo) Deserialize(arr []byte) (*IndigoObject, error) {
 
 // SetOption sets option value. This mirrors the Python setOption behavior.
 func (in *Indigo) SetOption(option string, v1 interface{}, v2 interface{}, v3 interface{}) error {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	copt := C.CString(option)
 	defer C.free(unsafe.Pointer(copt))
 
@@ -158,7 +233,7 @@ func (in *Indigo) SetOption(option string, v1 interface{}, v2 interface{}, v3 in
 		if f2, ok2 := v2.(float64); ok2 {
 			if f3, ok3 := v3.(float64); ok3 {
 				if C.indigoSetOptionColor(copt, C.float(f1), C.float(f2), C.float(f3)) == 0 {
-					return errors.New(lastErrorString())
+					return session.NativeError(0, "set option %s", option)
 				}
 				return nil
 			}
@@ -169,7 +244,7 @@ func (in *Indigo) SetOption(option string, v1 interface{}, v2 interface{}, v3 in
 	if i1, ok1 := v1.(int); ok1 {
 		if i2, ok2 := v2.(int); ok2 && v3 == nil {
 			if C.indigoSetOptionXY(copt, C.int(i1), C.int(i2)) == 0 {
-				return errors.New(lastErrorString())
+				return session.NativeError(0, "set option %s", option)
 			}
 			return nil
 		}
@@ -182,17 +257,17 @@ func (in *Indigo) SetOption(option string, v1 interface{}, v2 interface{}, v3 in
 			cval := C.CString(val)
 			defer C.free(unsafe.Pointer(cval))
 			if C.indigoSetOption(copt, cval) == 0 {
-				return errors.New(lastErrorString())
+				return session.NativeError(0, "set option %s", option)
 			}
 			return nil
 		case int:
 			if C.indigoSetOptionInt(copt, C.int(val)) == 0 {
-				return errors.New(lastErrorString())
+				return session.NativeError(0, "set option %s", option)
 			}
 			return nil
 		case float64:
 			if C.indigoSetOptionFloat(copt, C.float(val)) == 0 {
-				return errors.New(lastErrorString())
+				return session.NativeError(0, "set option %s", option)
 			}
 			return nil
 		case bool:
@@ -201,38 +276,48 @@ func (in *Indigo) SetOption(option string, v1 interface{}, v2 interface{}, v3 in
 				b = 1
 			}
 			if C.indigoSetOptionBool(copt, b) == 0 {
-				return errors.New(lastErrorString())
+				return session.NativeError(0, "set option %s", option)
 			}
 			return nil
 		default:
-			return errors.New("indigo: bad option value type")
+			return indigoerr.New(indigoerr.CATEGORY_OPTION, "indigo: bad option value type")
 		}
 	}
 
-	return errors.New("indigo: bad option parameter combination")
+	return indigoerr.New(indigoerr.CATEGORY_OPTION, "indigo: bad option parameter combination")
 }
 
 // CreateArray creates an Indigo array for rendering multiple objects
 func (in *Indigo) CreateArray() (int, error) {
+	if err := in.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	handle := int(C.indigoCreateArray())
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to create array: %s", lastErrorString())
+		return 0, session.NativeError(0, "create array")
 	}
 	return handle, nil
 }
 
 // ArrayAdd adds an object to an array
 func (in *Indigo) ArrayAdd(arrayHandle int, objectHandle int) error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	if arrayHandle < 0 {
-		return fmt.Errorf("invalid array handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid array handle")
 	}
 	if objectHandle < 0 {
-		return fmt.Errorf("invalid object handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid object handle")
 	}
 
 	ret := int(C.indigoArrayAdd(C.int(arrayHandle), C.int(objectHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to add object to array: %s", lastErrorString())
+		return session.NativeError(0, "add object to array")
 	}
 
 	return nil
@@ -240,13 +325,18 @@ func (in *Indigo) ArrayAdd(arrayHandle int, objectHandle int) error {
 
 // FreeObject frees an Indigo object (array, buffer, etc.)
 func (in *Indigo) FreeObject(handle int) error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	if handle < 0 {
 		return nil // Already invalid
 	}
 
 	ret := int(C.indigoFree(C.int(handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to free object: %s", lastErrorString())
+		return session.NativeError(0, "free object")
 	}
 
 	return nil
@@ -254,9 +344,14 @@ func (in *Indigo) FreeObject(handle int) error {
 
 // CreateWriteBuffer creates an output buffer for rendering
 func (in *Indigo) CreateWriteBuffer() (int, error) {
+	if err := in.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	handle := int(C.indigoWriteBuffer())
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to create write buffer: %s", lastErrorString())
+		return 0, session.NativeError(0, "create write buffer")
 	}
 
 	runtime.SetFinalizer(&handle, func(h *int) {
@@ -270,15 +365,20 @@ func (in *Indigo) CreateWriteBuffer() (int, error) {
 
 // GetBufferData retrieves data from a write buffer
 func (in *Indigo) GetBufferData(bufferHandle int) ([]byte, error) {
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
 	if bufferHandle < 0 {
-		return nil, fmt.Errorf("invalid buffer handle")
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid buffer handle")
 	}
 
 	var size C.int
 	var dataPtr *C.char
 	ret := C.indigoToBuffer(C.int(bufferHandle), &dataPtr, &size)
 	if ret < 0 || dataPtr == nil {
-		return nil, fmt.Errorf("failed to get buffer data: %s", lastErrorString())
+		return nil, session.NativeError(0, "get buffer data")
 	}
 
 	// Copy C data to Go slice
@@ -289,74 +389,102 @@ func (in *Indigo) GetBufferData(bufferHandle int) ([]byte, error) {
 
 // GetOption returns option value as string.
 func (in *Indigo) GetOption(option string) (string, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
 	copt := C.CString(option)
 	defer C.free(unsafe.Pointer(copt))
 	ptr := C.indigoGetOption(copt)
 	if ptr == nil {
-		return "", errors.New(lastErrorString())
+		return "", session.NativeError(0, "get option %s", option)
 	}
 	return C.GoString(ptr), nil
 }
 
 // GetOptionInt returns integer option value.
 func (in *Indigo) GetOptionInt(option string) (int, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	copt := C.CString(option)
 	defer C.free(unsafe.Pointer(copt))
 	var out C.int
 	if C.indigoGetOptionInt(copt, &out) == 0 {
-		return 0, errors.New(lastErrorString())
+		return 0, session.NativeError(0, "get option %s", option)
 	}
 	return int(out), nil
 }
 
 // Next obtains the next element from an iterator, returns 0 if there is no next element
 func (in *Indigo) Next(iterHandle int) (int, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	result := int(C.indigoNext(C.int(iterHandle)))
 	if result < 0 {
-		return 0, fmt.Errorf("failed to get next element: %s", lastErrorString())
+		return 0, session.NativeError(0, "get next element")
 	}
 	return result, nil
 }
 
 // HasNext checks if there is a next element without obtaining it
 func (in *Indigo) HasNext(iterHandle int) (bool, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
 	result := int(C.indigoHasNext(C.int(iterHandle)))
 	if result < 0 {
-		return false, fmt.Errorf("failed to check next element: %s", lastErrorString())
+		return false, session.NativeError(0, "check next element")
 	}
 	return result != 0, nil
 }
 
 // Index returns the index of an element
 func (in *Indigo) Index(itemHandle int) (int, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	result := int(C.indigoIndex(C.int(itemHandle)))
 	if result < 0 {
-		return 0, fmt.Errorf("failed to get index: %s", lastErrorString())
+		return 0, session.NativeError(0, "get index")
 	}
 	return result, nil
 }
 
 // Remove removes an item from its container (usually a molecule)
 func (in *Indigo) Remove(itemHandle int) error {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	result := int(C.indigoRemove(C.int(itemHandle)))
 	if result < 0 {
-		return fmt.Errorf("failed to remove item: %s", lastErrorString())
+		return session.NativeError(0, "remove item")
 	}
 	return nil
 }
 
 // GetOriginalFormat returns the original format of an item
 func (in *Indigo) GetOriginalFormat(itemHandle int) (string, error) {
-	in.setSession()
+	if err := in.enter(); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
 	cStr := C.indigoGetOriginalFormat(C.int(itemHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get original format: %s", lastErrorString())
+		return "", session.NativeError(0, "get original format")
 	}
 	return C.GoString(cStr), nil
 }
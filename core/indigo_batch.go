@@ -0,0 +1,374 @@
+// Package core provides parallel batch processing over session pools
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_batch.go
+// @Software: GoLand
+package core
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+// BatchSource yields the inputs of a batch, e.g. SMILES strings or molfiles
+type BatchSource interface {
+	// Next returns the next input, or false once the source is exhausted
+	Next() (string, bool)
+	// Err returns the error that stopped the source, if any
+	Err() error
+}
+
+// RecordBatchSource is a BatchSource whose inputs are records, see RecordSource
+// Sources wrapping a RecordSource can implement it to keep the records in the results
+type RecordBatchSource interface {
+	BatchSource
+	// Record returns the record of the input last returned by Next
+	Record() *Record
+}
+
+// BatchFunc processes a single input on a borrowed session
+// The returned value should not hold objects of the session, as the session may be
+// recycled by the pool once the call returns
+type BatchFunc func(in *Indigo, index int, input string) (interface{}, error)
+
+// BatchOptions configures a batch run
+type BatchOptions struct {
+	Workers int  // number of concurrent workers, defaults to the pool size
+	Ordered bool // deliver results in input order
+	Window  int  // maximal number of inputs in flight, defaults to twice the number of workers
+}
+
+// BatchResult is the outcome of a single input
+type BatchResult struct {
+	Index  int // zero-based position in the source
+	Input  string
+	Value  interface{}
+	Err    error
+	Record *Record // record of a RecordSource input, with its data fields
+}
+
+// BatchIterator delivers the results of a batch run
+//
+//	it := core.Batch(ctx, pool, core.SliceSource(smiles), func(in *core.Indigo, i int, s string) (interface{}, error) {
+//		mol, err := in.LoadMoleculeFromString(s)
+//		if err != nil { return nil, err }
+//		defer mol.Close()
+//		return mol.MolecularWeight()
+//	}, &core.BatchOptions{Ordered: true})
+//	defer it.Close()
+//	for it.Next() {
+//		res := it.Result()
+//		// res.Err is set for inputs that failed
+//	}
+//	if err := it.Err(); err != nil { ... }
+type BatchIterator struct {
+	parent  context.Context // context of the caller, as opposed to the cancellation by Close
+	ctx     context.Context
+	cancel  context.CancelFunc
+	results chan BatchResult
+	slots   chan struct{} // one token per input in flight
+	ordered bool
+	pending map[int]BatchResult // ordered results received ahead of their turn
+	next    int
+	result  *BatchResult
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex
+	srcErr error
+}
+
+// Batch runs fn on every input of src, using sessions borrowed from pool
+// An error of fn is reported in the result of its input and does not stop the batch;
+// cancelling ctx or closing the iterator stops it. A nil ctx runs without deadline, opts may be nil for defaults
+func Batch(ctx context.Context, pool *SessionPool, src BatchSource, fn BatchFunc, opts *BatchOptions) *BatchIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if pool == nil || src == nil || fn == nil {
+		return failedBatch(indigoerr.New(indigoerr.CATEGORY_INVALID, "batch needs a pool, a source and a function"))
+	}
+
+	var options BatchOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Workers <= 0 {
+		options.Workers = pool.Stats().Size
+		if options.Workers <= 0 {
+			options.Workers = 1
+		}
+	}
+	if options.Window <= 0 {
+		options.Window = 2 * options.Workers
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	it := &BatchIterator{
+		parent:  parent,
+		ctx:     ctx,
+		cancel:  cancel,
+		results: make(chan BatchResult, options.Window),
+		slots:   make(chan struct{}, options.Window),
+		ordered: options.Ordered,
+		pending: make(map[int]BatchResult),
+	}
+
+	jobs := make(chan BatchResult)
+	it.wg.Add(1)
+	go it.feed(src, jobs)
+
+	var workers sync.WaitGroup
+	for i := 0; i < options.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				// Sends never block: results has room for every input in flight
+				it.results <- runBatchJob(ctx, pool, fn, job)
+			}
+		}()
+	}
+
+	it.wg.Add(1)
+	go func() {
+		defer it.wg.Done()
+		workers.Wait()
+		close(it.results)
+	}()
+
+	return it
+}
+
+// failedBatch returns an iterator without results whose Err reports err
+func failedBatch(err error) *BatchIterator {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan BatchResult)
+	close(results)
+	return &BatchIterator{parent: ctx, ctx: ctx, cancel: cancel, results: results, srcErr: err}
+}
+
+// Next waits for the next result
+// Returns false once all inputs were processed or the batch was stopped; check Err() afterwards
+func (it *BatchIterator) Next() bool {
+	it.result = nil
+	if !it.ordered {
+		res, ok := <-it.results
+		if !ok {
+			return false
+		}
+		<-it.slots
+		it.result = &res
+		return true
+	}
+
+	for {
+		if res, ok := it.pending[it.next]; ok {
+			delete(it.pending, it.next)
+			it.next++
+			<-it.slots
+			it.result = &res
+			return true
+		}
+
+		res, ok := <-it.results
+		if !ok {
+			return false
+		}
+		it.pending[res.Index] = res
+	}
+}
+
+// Result returns the current result
+func (it *BatchIterator) Result() *BatchResult {
+	return it.result
+}
+
+// Err returns the error that stopped the batch: a source error or the context error
+func (it *BatchIterator) Err() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	if it.srcErr != nil {
+		return it.srcErr
+	}
+	return it.parent.Err()
+}
+
+// Close stops the batch and waits for the running calls to finish
+func (it *BatchIterator) Close() error {
+	it.cancel()
+	for range it.results {
+	}
+	it.wg.Wait()
+	it.result = nil
+	return nil
+}
+
+// Collect returns the remaining results and closes the iterator
+func (it *BatchIterator) Collect() ([]BatchResult, error) {
+	defer it.Close()
+
+	var results []BatchResult
+	for it.Next() {
+		results = append(results, *it.Result())
+	}
+	return results, it.Err()
+}
+
+// feed dispatches the inputs of src while there is room in the window
+func (it *BatchIterator) feed(src BatchSource, jobs chan<- BatchResult) {
+	defer it.wg.Done()
+	defer close(jobs)
+
+	for index := 0; ; index++ {
+		select {
+		case it.slots <- struct{}{}:
+		case <-it.ctx.Done():
+			return
+		}
+
+		input, ok := src.Next()
+		if !ok {
+			<-it.slots
+			if err := src.Err(); err != nil {
+				it.mu.Lock()
+				it.srcErr = err
+				it.mu.Unlock()
+			}
+			return
+		}
+
+		job := BatchResult{Index: index, Input: input}
+		if rs, ok := src.(RecordBatchSource); ok {
+			job.Record = rs.Record()
+		}
+
+		select {
+		case jobs <- job:
+		case <-it.ctx.Done():
+			<-it.slots
+			return
+		}
+	}
+}
+
+// runBatchJob calls fn on a borrowed session, turning panics into errors
+func runBatchJob(ctx context.Context, pool *SessionPool, fn BatchFunc, job BatchResult) (res BatchResult) {
+	res = job
+	if job.Record != nil && job.Record.Err != nil {
+		res.Err = job.Record.Err
+		return res
+	}
+	in, err := pool.Get(ctx)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer pool.Put(in)
+
+	defer func() {
+		if r := recover(); r != nil {
+			res.Err = indigoerr.New(indigoerr.CATEGORY_INTERNAL, "batch input %d panicked: %v", job.Index, r)
+		}
+	}()
+
+	res.Value, res.Err = fn(in, job.Index, job.Input)
+	return res
+}
+
+// sliceSource yields the elements of a slice
+type sliceSource struct {
+	inputs []string
+	pos    int
+}
+
+// SliceSource creates a batch source over a slice of inputs
+func SliceSource(inputs []string) BatchSource {
+	return &sliceSource{inputs: inputs}
+}
+
+func (s *sliceSource) Next() (string, bool) {
+	if s.pos >= len(s.inputs) {
+		return "", false
+	}
+	s.pos++
+	return s.inputs[s.pos-1], true
+}
+
+func (s *sliceSource) Err() error {
+	return nil
+}
+
+// lineSource yields the non-empty lines of a reader
+type lineSource struct {
+	scanner *bufio.Scanner
+}
+
+// LineSource creates a batch source over the non-empty lines of r, e.g. a SMILES file
+func LineSource(r io.Reader) BatchSource {
+	return &lineSource{scanner: bufio.NewScanner(r)}
+}
+
+func (s *lineSource) Next() (string, bool) {
+	for s.scanner.Scan() {
+		if line := strings.TrimSpace(s.scanner.Text()); line != "" {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+func (s *lineSource) Err() error {
+	return s.scanner.Err()
+}
+
+// recordSource yields the raw text of the records of a RecordReader
+type recordSource struct {
+	rd *RecordReader
+}
+
+// RecordSource creates a batch source over the raw records of rd, e.g. the molfiles of an SD file
+// The records are parsed once, by the workers in their own sessions, so rd stops building
+// Molecule and Reaction objects; each result carries its record, and a record that could not
+// be read is reported with its error without calling the BatchFunc
+// The returned source implements RecordBatchSource; if rd is nil or reads binary CDX records,
+// which have no raw text, it yields nothing and Err reports it
+func RecordSource(rd *RecordReader) BatchSource {
+	if rd != nil && rd.format != FORMAT_CDX {
+		rd.rawOnly = true
+	}
+	return &recordSource{rd: rd}
+}
+
+func (s *recordSource) Next() (string, bool) {
+	if s.rd == nil || !s.rd.rawOnly || !s.rd.Next() {
+		return "", false
+	}
+	return s.rd.Record().Raw, true
+}
+
+func (s *recordSource) Err() error {
+	if s.rd == nil {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "record source needs a reader")
+	}
+	if !s.rd.rawOnly {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "record source cannot pass binary %s records", s.rd.format)
+	}
+	return s.rd.Err()
+}
+
+func (s *recordSource) Record() *Record {
+	if s.rd == nil {
+		return nil
+	}
+	return s.rd.Record()
+}
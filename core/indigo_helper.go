@@ -8,28 +8,359 @@
 // @Software: GoLand
 package core
 
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+// ErrPoolClosed is returned by SessionPool.Get once the pool is closed
+// It matches indigoerr.ErrClosed with errors.Is
+var ErrPoolClosed = indigoerr.Closed("session pool")
+
+// SessionOption is an option applied to every session of a pool, see Indigo.SetOption
+// Values holds one to three values, e.g. three floats for a color
+type SessionOption struct {
+	Name   string
+	Values []interface{}
+}
+
+// PoolOptions configures a SessionPool
+type PoolOptions struct {
+	// Presets are applied to new sessions and reapplied, after resetting all options, on Put
+	Presets []SessionOption
+	// MaxUses is the number of Get/Put cycles after which all objects of a session are freed, 0 for no limit
+	// Objects created in the session must not be used after it was put back
+	MaxUses int
+}
+
+// PoolStats is a snapshot of the usage of a SessionPool
+type PoolStats struct {
+	Size     int    // sessions owned by the pool
+	Missing  int    // sessions lost to failures and not recreated yet, retried by Get
+	Idle     int    // sessions waiting in the pool
+	InUse    int    // sessions currently borrowed
+	Gets     uint64 // successful Get calls
+	Waits    uint64 // Get calls that had to wait for a session
+	Recycles uint64 // sessions whose objects were freed after MaxUses
+	Failures uint64 // sessions dropped because they could not be reset
+}
+
+// SessionPool shares a fixed number of Indigo sessions between goroutines
+//
+//	pool, err := core.NewSessionPool(4, &core.PoolOptions{MaxUses: 1000})
+//	defer pool.Close()
+//
+//	in, err := pool.Get(ctx)
+//	if err != nil { ... }
+//	defer pool.Put(in)
 type SessionPool struct {
-	pool chan *Indigo
-	size int
+	pool    chan *Indigo
+	done    chan struct{}
+	options PoolOptions
+
+	mu      sync.Mutex
+	uses    map[*Indigo]int      // sessions owned by the pool and their uses since the last recycle
+	out     map[*Indigo]struct{} // sessions currently borrowed
+	missing int                  // sessions that could not be replaced, see replace
+	closed  bool
+	stats   PoolStats
 }
 
-func NewSessionPool(size int) *SessionPool {
-	pool := make(chan *Indigo, size)
+// NewSessionPool creates a pool of size sessions
+// opts may be nil for no presets and no use limit
+func NewSessionPool(size int, opts *PoolOptions) (*SessionPool, error) {
+	if size <= 0 {
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid session pool size: %d", size)
+	}
+
+	p := &SessionPool{
+		pool: make(chan *Indigo, size),
+		done: make(chan struct{}),
+		uses: make(map[*Indigo]int, size),
+		out:  make(map[*Indigo]struct{}, size),
+	}
+	if opts != nil {
+		p.options = *opts
+	}
+
 	for i := 0; i < size; i++ {
-		indigo, _ := IndigoInit()
-		pool <- indigo
+		in, err := p.newSession()
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to create session %d: %w", i, err)
+		}
+		p.uses[in] = 0
+		p.pool <- in
 	}
-	return &SessionPool{pool: pool, size: size}
-}
 
-func (p *SessionPool) Get() *Indigo {
-	return <-p.pool
+	return p, nil
 }
 
-func (p *SessionPool) Put(indigo *Indigo) {
+// Get borrows a session, waiting until one is available, ctx is done or the pool is closed
+// The session must be returned with Put. A nil ctx waits without deadline.
+// If sessions were lost, Get first tries to recreate one; when the pool has no session left,
+// the creation error is returned instead of waiting
+func (p *SessionPool) Get(ctx context.Context) (*Indigo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	select {
-	case p.pool <- indigo:
+	case in := <-p.pool:
+		return p.borrowed(in, false)
 	default:
-		indigo.Close() // 池满时直接释放
 	}
+
+	if in, err := p.recreate(); in != nil || err != nil {
+		return in, err
+	}
+
+	select {
+	case in := <-p.pool:
+		return p.borrowed(in, true)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.done:
+		return nil, ErrPoolClosed
+	}
+}
+
+// Put returns a borrowed session to the pool
+// Its options are reset to the presets, and its objects are freed once it reached MaxUses.
+// Sessions not created by the pool, and all sessions after Close, are released instead.
+// Putting back a session of the pool that is not borrowed, e.g. a second time, does nothing
+func (p *SessionPool) Put(in *Indigo) {
+	if in == nil {
+		return
+	}
+
+	p.mu.Lock()
+	uses, owned := p.uses[in]
+	if _, borrowed := p.out[in]; owned && !borrowed {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.out, in)
+	if !owned || p.closed {
+		delete(p.uses, in)
+		p.mu.Unlock()
+		in.Close()
+		return
+	}
+	uses++
+	p.uses[in] = uses
+	p.mu.Unlock()
+
+	recycle := p.options.MaxUses > 0 && uses >= p.options.MaxUses
+	if err := p.reset(in, recycle); err != nil {
+		p.replace(in)
+		return
+	}
+
+	p.mu.Lock()
+	if recycle {
+		p.uses[in] = 0
+		p.stats.Recycles++
+	}
+	p.mu.Unlock()
+
+	p.release(in)
+}
+
+// Close releases the idle sessions; sessions still borrowed are released when put back
+// Pending and later Get calls return ErrPoolClosed
+func (p *SessionPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	p.drain()
+	return nil
+}
+
+// Stats returns a snapshot of the pool usage
+func (p *SessionPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Size = len(p.uses)
+	stats.Missing = p.missing
+	stats.Idle = len(p.pool)
+	stats.InUse = stats.Size - stats.Idle
+	return stats
+}
+
+// borrowed hands out a session taken from the channel
+func (p *SessionPool) borrowed(in *Indigo, waited bool) (*Indigo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		// Lost the race with Close, which only drains what is still in the channel
+		delete(p.uses, in)
+		in.Close()
+		return nil, ErrPoolClosed
+	}
+
+	p.out[in] = struct{}{}
+	p.stats.Gets++
+	if waited {
+		p.stats.Waits++
+	}
+	return in, nil
+}
+
+// release puts a session back into the channel, or closes it if the pool was closed meanwhile
+func (p *SessionPool) release(in *Indigo) {
+	p.mu.Lock()
+	closed := p.closed
+	if closed {
+		delete(p.uses, in)
+	}
+	p.mu.Unlock()
+	if closed {
+		in.Close()
+		return
+	}
+
+	// Never blocks: the channel has room for every session owned by the pool
+	p.pool <- in
+
+	p.mu.Lock()
+	closed = p.closed
+	p.mu.Unlock()
+	if closed {
+		// Close may have drained the channel before the send
+		p.drain()
+	}
+}
+
+// drain closes the sessions waiting in the channel
+func (p *SessionPool) drain() {
+	for {
+		select {
+		case in := <-p.pool:
+			p.mu.Lock()
+			delete(p.uses, in)
+			p.mu.Unlock()
+			in.Close()
+		default:
+			return
+		}
+	}
+}
+
+// replace drops a session that could not be reset and tries to add a fresh one instead
+func (p *SessionPool) replace(in *Indigo) {
+	p.mu.Lock()
+	delete(p.uses, in)
+	p.stats.Failures++
+	p.mu.Unlock()
+	in.Close()
+
+	fresh, err := p.newSession()
+	if err != nil {
+		// Get tries again later
+		p.mu.Lock()
+		p.missing++
+		p.mu.Unlock()
+		return
+	}
+
+	p.mu.Lock()
+	p.uses[fresh] = 0
+	p.mu.Unlock()
+	p.release(fresh)
+}
+
+// recreate creates a borrowed session in place of one lost by replace
+// Returns nil and no error if no session is missing or the creation failed while other sessions are left
+func (p *SessionPool) recreate() (*Indigo, error) {
+	p.mu.Lock()
+	if p.closed || p.missing == 0 {
+		p.mu.Unlock()
+		return nil, nil
+	}
+	p.missing--
+	p.mu.Unlock()
+
+	in, err := p.newSession()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.missing++
+		if len(p.uses) == 0 {
+			return nil, fmt.Errorf("session pool has no session left: %w", err)
+		}
+		return nil, nil
+	}
+	if p.closed {
+		in.Close()
+		return nil, ErrPoolClosed
+	}
+
+	p.uses[in] = 0
+	p.out[in] = struct{}{}
+	p.stats.Gets++
+	return in, nil
+}
+
+// newSession creates a session with the presets applied
+func (p *SessionPool) newSession() (*Indigo, error) {
+	in, err := IndigoInit()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.applyPresets(in); err != nil {
+		in.Close()
+		return nil, err
+	}
+	return in, nil
+}
+
+// reset restores the presets of a session and, if recycle is set, frees all its objects
+// The error handler and the tautomer rules of the previous borrower are removed as well
+func (p *SessionPool) reset(in *Indigo, recycle bool) error {
+	if recycle {
+		if err := in.freeAllObjects(); err != nil {
+			return err
+		}
+	}
+
+	in.SetErrorHandler(nil)
+	if err := in.ClearTautomerRules(); err != nil {
+		return err
+	}
+	if err := in.resetOptions(); err != nil {
+		return err
+	}
+	return p.applyPresets(in)
+}
+
+// applyPresets sets the preset options on a session
+func (p *SessionPool) applyPresets(in *Indigo) error {
+	for _, opt := range p.options.Presets {
+		if len(opt.Values) == 0 || len(opt.Values) > 3 {
+			return indigoerr.New(indigoerr.CATEGORY_OPTION, "invalid number of values for option %s: %d", opt.Name, len(opt.Values))
+		}
+
+		values := make([]interface{}, 3)
+		copy(values, opt.Values)
+		if err := in.SetOption(opt.Name, values[0], values[1], values[2]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
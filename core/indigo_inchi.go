@@ -32,11 +32,14 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 	"github.com/cx-luo/go-indigo/molecule"
 )
 
 type IndigoInchi struct {
 	sid              uint64
+	session          *session.Session
 	inchiInitialized bool
 }
 
@@ -49,15 +52,25 @@ func getInchiLastError() string {
 	return C.GoString(errMsg)
 }
 
+// inchiError builds the error of a failed InChI call from the InChI log
+func inchiError(handle int, op string, args ...interface{}) error {
+	return indigoerr.Native(fmt.Sprintf(op, args...), getInchiLastError(), handle)
+}
+
 // InchiInit initializes the InChI module for the current session
 // This should be called before using InChI functions
 func (in *Indigo) InchiInit() (*IndigoInchi, error) {
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoInchiInit(C.ulonglong(in.sid)))
 	if ret < 0 {
-		return nil, fmt.Errorf("failed to initialize InChI: %s", getInchiLastError())
+		return nil, inchiError(0, "initialize InChI")
 	}
 
-	return &IndigoInchi{sid: in.sid, inchiInitialized: true}, nil
+	return &IndigoInchi{sid: in.sid, session: in.session, inchiInitialized: true}, nil
 }
 
 // InchiDispose disposes the InChI module
@@ -67,9 +80,19 @@ func (ii *IndigoInchi) InchiDispose() error {
 		return nil // Not initialized
 	}
 
+	if ii.session.Closed() {
+		// Disposed together with the session
+		ii.inchiInitialized = false
+		return nil
+	}
+	if err := session.Enter(ii.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoInchiDispose(C.qword(ii.sid)))
 	if ret < 0 {
-		return fmt.Errorf("failed to dispose InChI: %s", getInchiLastError())
+		return inchiError(0, "dispose InChI")
 	}
 
 	ii.inchiInitialized = false
@@ -78,9 +101,14 @@ func (ii *IndigoInchi) InchiDispose() error {
 
 // ResetInChIOptions resets InChI options to default
 func (ii *IndigoInchi) ResetInChIOptions() error {
+	if err := session.Enter(ii.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoInchiResetOptions())
 	if ret < 0 {
-		return fmt.Errorf("failed to reset InChI options: %s", ii.InChILog())
+		return inchiError(0, "reset InChI options")
 	}
 	return nil
 }
@@ -88,19 +116,27 @@ func (ii *IndigoInchi) ResetInChIOptions() error {
 // GenerateInChI converts the molecule to InChI format
 // This uses Indigo's InChI plugin
 func (ii *IndigoInchi) GenerateInChI(m *molecule.Molecule) (string, error) {
+	if err := session.Enter(ii.session); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
 	if m == nil {
-		return "", fmt.Errorf("molecule is nil")
+		return "", indigoerr.New(indigoerr.CATEGORY_INVALID, "molecule is nil")
 	}
 	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+		return "", indigoerr.Closed("molecule")
 	}
 	if m.Handle < 0 {
-		return "", fmt.Errorf("invalid molecule handle")
+		return "", indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid molecule handle")
+	}
+	if err := session.Same(ii.session, m.Session()); err != nil {
+		return "", err
 	}
 
 	cStr := C.indigoInchiGetInchi(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to InChI: %s", ii.InChILog())
+		return "", inchiError(m.Handle, "convert to InChI")
 	}
 
 	return C.GoString(cStr), nil
@@ -108,8 +144,13 @@ func (ii *IndigoInchi) GenerateInChI(m *molecule.Molecule) (string, error) {
 
 // InChIToKey converts an InChI string to InChIKey
 func (ii *IndigoInchi) InChIToKey(inchi string) (string, error) {
+	if err := session.Enter(ii.session); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
 	if inchi == "" {
-		return "", fmt.Errorf("empty InChI string")
+		return "", indigoerr.New(indigoerr.CATEGORY_INVALID, "empty InChI string")
 	}
 
 	cInchi := C.CString(inchi)
@@ -117,7 +158,7 @@ func (ii *IndigoInchi) InChIToKey(inchi string) (string, error) {
 
 	cKey := C.indigoInchiGetInchiKey(cInchi)
 	if cKey == nil {
-		return "", fmt.Errorf("failed to generate InChI Key: %s", ii.InChILog())
+		return "", inchiError(0, "generate InChI Key")
 	}
 
 	return C.GoString(cKey), nil
@@ -129,6 +170,11 @@ func (ii *IndigoInchi) GetInchiSessionID() uint64 {
 
 // InChIVersion returns the version of the InChI library
 func (ii *IndigoInchi) InChIVersion() string {
+	if session.Enter(ii.session) != nil {
+		return ""
+	}
+	defer session.Exit()
+
 	cStr := C.indigoInchiVersion()
 	if cStr == nil {
 		return ""
@@ -138,6 +184,11 @@ func (ii *IndigoInchi) InChIVersion() string {
 
 // InChIWarning returns any warnings from the last InChI generation
 func (ii *IndigoInchi) InChIWarning() string {
+	if session.Enter(ii.session) != nil {
+		return ""
+	}
+	defer session.Exit()
+
 	cStr := C.indigoInchiGetWarning()
 	if cStr == nil {
 		return ""
@@ -147,6 +198,11 @@ func (ii *IndigoInchi) InChIWarning() string {
 
 // InChILog returns log messages from the last InChI generation
 func (ii *IndigoInchi) InChILog() string {
+	if session.Enter(ii.session) != nil {
+		return ""
+	}
+	defer session.Exit()
+
 	cStr := C.indigoInchiGetLog()
 	if cStr == nil {
 		return ""
@@ -156,6 +212,11 @@ func (ii *IndigoInchi) InChILog() string {
 
 // InChIAuxInfo returns auxiliary information from the last InChI generation
 func (ii *IndigoInchi) InChIAuxInfo() string {
+	if session.Enter(ii.session) != nil {
+		return ""
+	}
+	defer session.Exit()
+
 	cStr := C.indigoInchiGetAuxInfo()
 	if cStr == nil {
 		return ""
@@ -165,8 +226,13 @@ func (ii *IndigoInchi) InChIAuxInfo() string {
 
 // LoadFromInChI loads a molecule from InChI string, return molecule handle
 func (ii *IndigoInchi) LoadFromInChI(inchi string) (int, error) {
+	if err := session.Enter(ii.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	if inchi == "" {
-		return 0, fmt.Errorf("empty InChI string")
+		return 0, indigoerr.New(indigoerr.CATEGORY_INVALID, "empty InChI string")
 	}
 
 	cInchi := C.CString(inchi)
@@ -174,7 +240,7 @@ func (ii *IndigoInchi) LoadFromInChI(inchi string) (int, error) {
 
 	handle := int(C.indigoInchiLoadMolecule(cInchi))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to load molecule from InChI: %s", ii.InChILog())
+		return 0, inchiError(0, "load molecule from InChI")
 	}
 
 	return handle, nil
@@ -191,6 +257,11 @@ type InChIResult struct {
 
 // GenerateInChIWithInfo converts the molecule to InChI format and returns detailed information
 func (ii *IndigoInchi) GenerateInChIWithInfo(m *molecule.Molecule) (*InChIResult, error) {
+	if err := session.Enter(ii.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
 	inchi, err := ii.GenerateInChI(m)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,54 @@
+// Package core provides core functions for Indigo C API library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_pka.go
+// @Software: GoLand
+package core
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows: link against import libraries (.lib)
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux: use $ORIGIN for runtime library search
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS: use @loader_path (not @executable_path) for shared libraries
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// BuildPkaModel builds the advanced pKa model of the session from an SDF training set
+// Each record needs acid and basic pKa data; maxLevel limits the atom environment depth
+// and threshold is the minimal accuracy for a model level to be kept
+// Select the model afterwards with SetOption("pKa-model", "advanced", nil, nil)
+func (in *Indigo) BuildPkaModel(maxLevel int, threshold float32, sdfFile string) error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	cFilename := C.CString(sdfFile)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	ret := int(C.indigoBuildPkaModel(C.int(maxLevel), C.float(threshold), cFilename))
+	if ret < 0 {
+		return session.NativeError(0, "build pKa model from %s", sdfFile)
+	}
+
+	return nil
+}
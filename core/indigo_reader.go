@@ -0,0 +1,363 @@
+// Package core provides streaming readers for multi-record files using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_reader.go
+// @Software: GoLand
+package core
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows: link against import libraries (.lib)
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux: use $ORIGIN for runtime library search
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS: use @loader_path (not @executable_path) for shared libraries
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"io"
+	"runtime"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/property"
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+	"github.com/cx-luo/go-indigo/reaction"
+)
+
+// Multi-record file formats
+const (
+	FORMAT_SDF    = "sdf"
+	FORMAT_RDF    = "rdf"
+	FORMAT_SMILES = "smiles"
+	FORMAT_CML    = "cml"
+	FORMAT_CDX    = "cdx"
+)
+
+// Record is a single entry of a multi-record file
+// Molecule is set for SDF, SMILES, CML and CDX records, Reaction for RDF records;
+// both are owned by the caller
+type Record struct {
+	Index      int               // zero-based position in the file
+	Raw        string            // original text of the record, empty for binary CDX records
+	Properties map[string]string // SDF/RDF data fields
+	Molecule   *molecule.Molecule
+	Reaction   *reaction.Reaction
+	Err        error // set if the record could not be parsed
+}
+
+// RecordReader reads the records of a multi-record file one by one
+// A record that fails to parse is returned with Err set and does not stop the iteration
+//
+//	rd, err := indigo.IterateSDFile("catalog.sdf")
+//	defer rd.Close()
+//	for rd.Next() {
+//		rec := rd.Record()
+//		if rec.Err != nil { continue }
+//		// use rec.Molecule, rec.Properties ...
+//		rec.Molecule.Close()
+//	}
+//	if err := rd.Err(); err != nil { ... }
+type RecordReader struct {
+	session *session.Session // the session the iterator was created in, closed when the pool recycles it
+	iter    int
+	reader  int // native reader holding the content of an io.Reader, 0 for files
+	format  string
+	index   int
+	record  *Record
+	err     error
+	rawOnly bool // records keep their raw text only, see RecordSource
+	closed  bool
+}
+
+// IterateSDFile reads molecules from an SD file
+func (in *Indigo) IterateSDFile(filename string) (*RecordReader, error) {
+	return in.iterateFile(filename, FORMAT_SDF)
+}
+
+// IterateRDFile reads reactions from an RD file
+func (in *Indigo) IterateRDFile(filename string) (*RecordReader, error) {
+	return in.iterateFile(filename, FORMAT_RDF)
+}
+
+// IterateSmilesFile reads molecules from a file with one SMILES per line
+func (in *Indigo) IterateSmilesFile(filename string) (*RecordReader, error) {
+	return in.iterateFile(filename, FORMAT_SMILES)
+}
+
+// IterateCMLFile reads molecules from a CML file
+func (in *Indigo) IterateCMLFile(filename string) (*RecordReader, error) {
+	return in.iterateFile(filename, FORMAT_CML)
+}
+
+// IterateCDXFile reads molecules from a CDX file
+func (in *Indigo) IterateCDXFile(filename string) (*RecordReader, error) {
+	return in.iterateFile(filename, FORMAT_CDX)
+}
+
+// IterateSDF reads molecules in SDF format from r
+// The content is read into memory, use IterateSDFile for large files
+func (in *Indigo) IterateSDF(r io.Reader) (*RecordReader, error) {
+	return in.iterateReader(r, FORMAT_SDF)
+}
+
+// IterateRDF reads reactions in RDF format from r
+// The content is read into memory, use IterateRDFile for large files
+func (in *Indigo) IterateRDF(r io.Reader) (*RecordReader, error) {
+	return in.iterateReader(r, FORMAT_RDF)
+}
+
+// IterateSmiles reads molecules with one SMILES per line from r
+// The content is read into memory, use IterateSmilesFile for large files
+func (in *Indigo) IterateSmiles(r io.Reader) (*RecordReader, error) {
+	return in.iterateReader(r, FORMAT_SMILES)
+}
+
+// IterateCML reads molecules in CML format from r
+// The content is read into memory, use IterateCMLFile for large files
+func (in *Indigo) IterateCML(r io.Reader) (*RecordReader, error) {
+	return in.iterateReader(r, FORMAT_CML)
+}
+
+// IterateCDX reads molecules in CDX format from r
+// The content is read into memory, use IterateCDXFile for large files
+func (in *Indigo) IterateCDX(r io.Reader) (*RecordReader, error) {
+	return in.iterateReader(r, FORMAT_CDX)
+}
+
+// Next reads the next record
+// Returns false at the end of the file or on a stream error; check Err() afterwards
+func (rd *RecordReader) Next() bool {
+	rd.record = nil
+	if rd.closed || rd.err != nil {
+		return false
+	}
+
+	if err := session.Enter(rd.session); err != nil {
+		rd.err = err
+		return false
+	}
+	defer session.Exit()
+
+	item := int(C.indigoNext(C.int(rd.iter)))
+	if item < 0 {
+		rd.err = session.NativeError(0, "read record %d", rd.index)
+		return false
+	}
+	if item == 0 {
+		return false
+	}
+	defer C.indigoFree(C.int(item))
+
+	rd.record = rd.readRecord(item)
+	rd.index++
+	return true
+}
+
+// Record returns the current record
+func (rd *RecordReader) Record() *Record {
+	return rd.record
+}
+
+// Err returns the stream error that stopped the iteration, if any
+func (rd *RecordReader) Err() error {
+	return rd.err
+}
+
+// Close frees the iterator and the underlying reader
+func (rd *RecordReader) Close() error {
+	if rd.closed {
+		return nil
+	}
+
+	rd.record = nil
+
+	if rd.session.Closed() {
+		// The iterator and the reader were released with the session
+		rd.closed = true
+		return nil
+	}
+	if err := session.Enter(rd.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoFree(C.int(rd.iter)))
+	if rd.reader != 0 && int(C.indigoFree(C.int(rd.reader))) < 0 && ret >= 0 {
+		ret = -1
+	}
+
+	rd.closed = true
+	if ret < 0 {
+		return session.NativeError(0, "free record reader")
+	}
+	return nil
+}
+
+// iterateFile creates a record reader over a file
+func (in *Indigo) iterateFile(filename string, format string) (*RecordReader, error) {
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	var iter int
+	switch format {
+	case FORMAT_SDF:
+		iter = int(C.indigoIterateSDFile(cFilename))
+	case FORMAT_RDF:
+		iter = int(C.indigoIterateRDFile(cFilename))
+	case FORMAT_SMILES:
+		iter = int(C.indigoIterateSmilesFile(cFilename))
+	case FORMAT_CML:
+		iter = int(C.indigoIterateCMLFile(cFilename))
+	case FORMAT_CDX:
+		iter = int(C.indigoIterateCDXFile(cFilename))
+	default:
+		return nil, indigoerr.New(indigoerr.CATEGORY_OPTION, "unsupported format %q", format)
+	}
+	if iter < 0 {
+		return nil, session.NativeError(0, "open %s file %s", format, filename)
+	}
+
+	return newRecordReader(in, iter, format), nil
+}
+
+// iterateReader creates a record reader over the content of r
+// The content is loaded into a native reader, which the iterator then scans record by record
+func (in *Indigo) iterateReader(r io.Reader, format string) (*RecordReader, error) {
+	if r == nil {
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "nil %s reader", format)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &indigoerr.IndigoError{Op: "read " + format + " data", Message: err.Error(), Category: indigoerr.CATEGORY_IO, Err: err}
+	}
+
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	reader, err := loadBuffer(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var iter int
+	switch format {
+	case FORMAT_SDF:
+		iter = int(C.indigoIterateSDF(C.int(reader)))
+	case FORMAT_RDF:
+		iter = int(C.indigoIterateRDF(C.int(reader)))
+	case FORMAT_SMILES:
+		iter = int(C.indigoIterateSmiles(C.int(reader)))
+	case FORMAT_CML:
+		iter = int(C.indigoIterateCML(C.int(reader)))
+	case FORMAT_CDX:
+		iter = int(C.indigoIterateCDX(C.int(reader)))
+	default:
+		C.indigoFree(C.int(reader))
+		return nil, indigoerr.New(indigoerr.CATEGORY_OPTION, "unsupported format %q", format)
+	}
+	if iter < 0 {
+		err := session.NativeError(0, "iterate %s data", format)
+		C.indigoFree(C.int(reader))
+		return nil, err
+	}
+
+	rd := newRecordReader(in, iter, format)
+	rd.reader = reader
+	return rd, nil
+}
+
+// loadBuffer creates a native reader over a copy of data
+// The caller must free the returned handle
+func loadBuffer(data []byte) (int, error) {
+	if len(data) == 0 {
+		cEmpty := C.CString("")
+		defer C.free(unsafe.Pointer(cEmpty))
+
+		reader := int(C.indigoLoadString(cEmpty))
+		if reader < 0 {
+			return 0, session.NativeError(0, "load data")
+		}
+		return reader, nil
+	}
+
+	cData := C.CBytes(data)
+	defer C.free(cData)
+
+	reader := int(C.indigoLoadBuffer((*C.char)(cData), C.int(len(data))))
+	if reader < 0 {
+		return 0, session.NativeError(0, "load data")
+	}
+	return reader, nil
+}
+
+// readRecord builds a record from an iterator item; parse errors are kept in the record
+func (rd *RecordReader) readRecord(item int) *Record {
+	rec := &Record{Index: rd.index}
+
+	// indigoRawData has no length, so the binary data of CDX records would be cut at the first NUL byte
+	if rd.format != FORMAT_CDX {
+		if cRaw := C.indigoRawData(C.int(item)); cRaw != nil {
+			rec.Raw = C.GoString(cRaw)
+		}
+	}
+
+	// Only SDF and RDF records carry data fields; for the other formats properties are best-effort
+	props, err := property.All(item)
+	switch {
+	case err == nil:
+		rec.Properties = props
+	case rd.format == FORMAT_SDF || rd.format == FORMAT_RDF:
+		rec.Err = err
+		return rec
+	}
+
+	if rd.rawOnly {
+		if rec.Raw == "" {
+			rec.Err = indigoerr.New(indigoerr.CATEGORY_INVALID, "record %d has no raw data", rd.index)
+		}
+		return rec
+	}
+
+	// Cloning forces the record to be parsed
+	handle := int(C.indigoClone(C.int(item)))
+	if handle < 0 {
+		rec.Err = session.NativeError(0, "parse record %d", rd.index)
+		return rec
+	}
+
+	if rd.format == FORMAT_RDF {
+		rec.Reaction = reaction.FromHandle(handle, rd.session)
+	} else {
+		rec.Molecule = molecule.FromHandle(handle, rd.session)
+	}
+	return rec
+}
+
+// newRecordReader is a helper function to create a RecordReader
+// It sets up the finalizer to ensure proper cleanup
+func newRecordReader(in *Indigo, iter int, format string) *RecordReader {
+	rd := &RecordReader{session: in.session, iter: iter, format: format}
+	runtime.SetFinalizer(rd, (*RecordReader).Close)
+	return rd
+}
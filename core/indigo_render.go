@@ -28,8 +28,7 @@ package core
 */
 import "C"
 import (
-	"fmt"
-
+	"github.com/cx-luo/go-indigo/internal/session"
 	"github.com/cx-luo/go-indigo/render"
 )
 
@@ -53,10 +52,15 @@ func defaultRenderOptions() *render.RenderOptions {
 // InitRenderer initializes the Indigo renderer with default options for the current session
 // This should be called before using rendering functions
 func (in *Indigo) InitRenderer() (*render.Renderer, error) {
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoRendererInit(C.ulonglong(in.sid)))
 	if ret < 0 {
-		return nil, fmt.Errorf("failed to initialize renderer: %s", lastErrorString())
+		return nil, session.NativeError(0, "initialize renderer")
 	}
 
-	return &render.Renderer{Sid: in.sid, Options: defaultRenderOptions(), RendererInitialized: true}, nil
+	return render.NewRenderer(in.session, defaultRenderOptions()), nil
 }
@@ -0,0 +1,86 @@
+// Package core provides core functions for Indigo C API library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_tautomer.go
+// @Software: GoLand
+package core
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows: link against import libraries (.lib)
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux: use $ORIGIN for runtime library search
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS: use @loader_path (not @executable_path) for shared libraries
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// SetTautomerRule defines or replaces the tautomer rule with the given id
+// begin and end list the allowed elements at the two ends of the tautomeric chain,
+// separated by commas; a leading '1' means aromatic and '0' aliphatic, e.g. "1C,N"
+func (in *Indigo) SetTautomerRule(id int, begin string, end string) error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	cBegin := C.CString(begin)
+	defer C.free(unsafe.Pointer(cBegin))
+
+	cEnd := C.CString(end)
+	defer C.free(unsafe.Pointer(cEnd))
+
+	ret := int(C.indigoSetTautomerRule(C.int(id), cBegin, cEnd))
+	if ret < 0 {
+		return session.NativeError(0, "set tautomer rule %d", id)
+	}
+
+	return nil
+}
+
+// RemoveTautomerRule removes the tautomer rule with the given id
+func (in *Indigo) RemoveTautomerRule(id int) error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoRemoveTautomerRule(C.int(id)))
+	if ret < 0 {
+		return session.NativeError(0, "remove tautomer rule %d", id)
+	}
+
+	return nil
+}
+
+// ClearTautomerRules removes all tautomer rules of the session
+func (in *Indigo) ClearTautomerRules() error {
+	if err := in.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoClearTautomerRules())
+	if ret < 0 {
+		return session.NativeError(0, "clear tautomer rules")
+	}
+
+	return nil
+}
@@ -0,0 +1,454 @@
+// Package core provides streaming writers for multi-record files using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_writer.go
+// @Software: GoLand
+package core
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows: link against import libraries (.lib)
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux: use $ORIGIN for runtime library search
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS: use @loader_path (not @executable_path) for shared libraries
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+	"github.com/cx-luo/go-indigo/reaction"
+)
+
+// recordWriter writes records either to an io.Writer or to a native file saver
+// For an io.Writer, every record, header and footer is rendered into its own native buffer, which is
+// copied to the io.Writer and freed right away, so memory use does not grow with the number of records.
+// File writers append to a saver created with indigoCreateFileSaver, which writes headers and footers itself
+type recordWriter struct {
+	session *session.Session // the session the writer was created in, closed when the pool recycles it
+	w       io.Writer        // nil for file writers
+	format  string
+	saver   int // native file saver, 0 for io.Writer writers
+	count   int
+	closed  bool
+}
+
+// SDFWriter writes molecules with their data fields to an SD file
+//
+//	w, err := indigo.NewSDFWriter(file)
+//	if err != nil { ... }
+//	for _, mol := range mols {
+//		if err := w.Write(mol, map[string]string{"ID": id}); err != nil { ... }
+//	}
+//	w.Close()
+type SDFWriter struct {
+	rw *recordWriter
+}
+
+// RDFWriter writes reactions with their data fields to an RD file
+type RDFWriter struct {
+	rw *recordWriter
+}
+
+// SmilesWriter writes molecules to a SMILES file, one per line
+type SmilesWriter struct {
+	rw *recordWriter
+}
+
+// CMLWriter writes molecules to a CML document
+type CMLWriter struct {
+	rw *recordWriter
+}
+
+// NewSDFWriter creates a writer of SDF records to w
+func (in *Indigo) NewSDFWriter(w io.Writer) (*SDFWriter, error) {
+	rw, err := in.newRecordWriter(w, FORMAT_SDF)
+	if err != nil {
+		return nil, err
+	}
+	return &SDFWriter{rw: rw}, nil
+}
+
+// NewSDFFileWriter creates a writer of SDF records to a file
+func (in *Indigo) NewSDFFileWriter(filename string) (*SDFWriter, error) {
+	rw, err := in.newRecordFileWriter(filename, FORMAT_SDF)
+	if err != nil {
+		return nil, err
+	}
+	return &SDFWriter{rw: rw}, nil
+}
+
+// NewRDFWriter creates a writer of RDF records to w and writes the RDF header
+func (in *Indigo) NewRDFWriter(w io.Writer) (*RDFWriter, error) {
+	rw, err := in.newRecordWriter(w, FORMAT_RDF)
+	if err != nil {
+		return nil, err
+	}
+	return &RDFWriter{rw: rw}, nil
+}
+
+// NewRDFFileWriter creates a writer of RDF records to a file and writes the RDF header
+func (in *Indigo) NewRDFFileWriter(filename string) (*RDFWriter, error) {
+	rw, err := in.newRecordFileWriter(filename, FORMAT_RDF)
+	if err != nil {
+		return nil, err
+	}
+	return &RDFWriter{rw: rw}, nil
+}
+
+// NewSmilesWriter creates a writer of SMILES lines to w
+func (in *Indigo) NewSmilesWriter(w io.Writer) (*SmilesWriter, error) {
+	rw, err := in.newRecordWriter(w, FORMAT_SMILES)
+	if err != nil {
+		return nil, err
+	}
+	return &SmilesWriter{rw: rw}, nil
+}
+
+// NewSmilesFileWriter creates a writer of SMILES lines to a file
+func (in *Indigo) NewSmilesFileWriter(filename string) (*SmilesWriter, error) {
+	rw, err := in.newRecordFileWriter(filename, FORMAT_SMILES)
+	if err != nil {
+		return nil, err
+	}
+	return &SmilesWriter{rw: rw}, nil
+}
+
+// NewCMLWriter creates a writer of CML molecules to w and writes the CML header
+// Close must be called to write the closing tags
+func (in *Indigo) NewCMLWriter(w io.Writer) (*CMLWriter, error) {
+	rw, err := in.newRecordWriter(w, FORMAT_CML)
+	if err != nil {
+		return nil, err
+	}
+	return &CMLWriter{rw: rw}, nil
+}
+
+// NewCMLFileWriter creates a writer of CML molecules to a file and writes the CML header
+// Close must be called to write the closing tags
+func (in *Indigo) NewCMLFileWriter(filename string) (*CMLWriter, error) {
+	rw, err := in.newRecordFileWriter(filename, FORMAT_CML)
+	if err != nil {
+		return nil, err
+	}
+	return &CMLWriter{rw: rw}, nil
+}
+
+// Write appends a molecule with the given data fields
+// The properties are written in key order; the molecule itself is not modified
+func (sw *SDFWriter) Write(m *molecule.Molecule, properties map[string]string) error {
+	if m == nil {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "molecule is nil")
+	}
+	if m.Closed {
+		return indigoerr.Closed("molecule")
+	}
+	return sw.rw.writeItem(m.Handle, m.Session(), properties)
+}
+
+// Count returns the number of records written
+func (sw *SDFWriter) Count() int {
+	return sw.rw.count
+}
+
+// Close finishes the writer; the underlying io.Writer is not closed
+func (sw *SDFWriter) Close() error {
+	return sw.rw.close()
+}
+
+// Write appends a reaction with the given data fields
+// The properties are written in key order; the reaction itself is not modified
+func (rw *RDFWriter) Write(r *reaction.Reaction, properties map[string]string) error {
+	if r == nil {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "reaction is nil")
+	}
+	if r.Closed {
+		return indigoerr.Closed("reaction")
+	}
+	return rw.rw.writeItem(r.Handle, r.Session(), properties)
+}
+
+// Count returns the number of records written
+func (rw *RDFWriter) Count() int {
+	return rw.rw.count
+}
+
+// Close finishes the writer; the underlying io.Writer is not closed
+func (rw *RDFWriter) Close() error {
+	return rw.rw.close()
+}
+
+// Write appends a molecule as a SMILES line
+// The molecule name, if set, is written after the SMILES
+func (sw *SmilesWriter) Write(m *molecule.Molecule) error {
+	if m == nil {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "molecule is nil")
+	}
+	if m.Closed {
+		return indigoerr.Closed("molecule")
+	}
+	return sw.rw.writeItem(m.Handle, m.Session(), nil)
+}
+
+// Count returns the number of records written
+func (sw *SmilesWriter) Count() int {
+	return sw.rw.count
+}
+
+// Close finishes the writer; the underlying io.Writer is not closed
+func (sw *SmilesWriter) Close() error {
+	return sw.rw.close()
+}
+
+// Write appends a molecule with the given properties
+// The properties are written in key order; the molecule itself is not modified
+func (cw *CMLWriter) Write(m *molecule.Molecule, properties map[string]string) error {
+	if m == nil {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "molecule is nil")
+	}
+	if m.Closed {
+		return indigoerr.Closed("molecule")
+	}
+	return cw.rw.writeItem(m.Handle, m.Session(), properties)
+}
+
+// Count returns the number of records written
+func (cw *CMLWriter) Count() int {
+	return cw.rw.count
+}
+
+// Close writes the CML footer; the underlying io.Writer is not closed
+func (cw *CMLWriter) Close() error {
+	return cw.rw.close()
+}
+
+// newRecordWriter creates a writer to w and writes the header of the format, if any
+func (in *Indigo) newRecordWriter(w io.Writer, format string) (*recordWriter, error) {
+	rw := &recordWriter{session: in.session, w: w, format: format}
+
+	var err error
+	switch format {
+	case FORMAT_RDF:
+		err = rw.writeChunk("header", func(buffer C.int) C.int {
+			return C.indigoRdfHeader(buffer)
+		})
+	case FORMAT_CML:
+		err = rw.writeChunk("header", func(buffer C.int) C.int {
+			return C.indigoCmlHeader(buffer)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// newRecordFileWriter creates a writer appending to a native saver on a file
+func (in *Indigo) newRecordFileWriter(filename string, format string) (*recordWriter, error) {
+	if err := in.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cFilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cFilename))
+
+	cFormat := C.CString(format)
+	defer C.free(unsafe.Pointer(cFormat))
+
+	saver := int(C.indigoCreateFileSaver(cFilename, cFormat))
+	if saver < 0 {
+		return nil, session.NativeError(0, "create %s saver for %s", format, filename)
+	}
+
+	rw := &recordWriter{session: in.session, format: format, saver: saver}
+	runtime.SetFinalizer(rw, (*recordWriter).release)
+	return rw, nil
+}
+
+// writeItem writes a single record, attaching the properties to a copy of the item
+func (rw *recordWriter) writeItem(handle int, owner *session.Session, properties map[string]string) error {
+	if rw.closed {
+		return indigoerr.New(indigoerr.CATEGORY_CLOSED, "%s writer is closed", rw.format)
+	}
+
+	if err := session.Enter(rw.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	if err := session.Same(rw.session, owner); err != nil {
+		return err
+	}
+
+	item := handle
+	if len(properties) > 0 {
+		item = int(C.indigoClone(C.int(handle)))
+		if item < 0 {
+			return session.NativeError(0, "copy record %d", rw.count)
+		}
+		defer C.indigoFree(C.int(item))
+
+		if err := setItemProperties(item, properties); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if rw.saver != 0 {
+		if int(C.indigoAppend(C.int(rw.saver), C.int(item))) < 0 {
+			err = session.NativeError(0, "write %s record %d", rw.format, rw.count)
+		}
+	} else {
+		err = rw.writeChunk(fmt.Sprintf("record %d", rw.count), func(buffer C.int) C.int {
+			return appendRecord(rw.format, buffer, C.int(item))
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	rw.count++
+	return nil
+}
+
+// close writes the footer of the format and frees the saver of a file writer
+func (rw *recordWriter) close() error {
+	if rw.closed {
+		return nil
+	}
+
+	if rw.saver == 0 {
+		var err error
+		if rw.format == FORMAT_CML {
+			err = rw.writeChunk("footer", func(buffer C.int) C.int {
+				return C.indigoCmlFooter(buffer)
+			})
+		}
+		rw.closed = true
+		return err
+	}
+
+	rw.closed = true
+	if rw.session.Closed() {
+		// Freed together with the session
+		return nil
+	}
+	if err := session.Enter(rw.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	var err error
+	if int(C.indigoClose(C.int(rw.saver))) < 0 {
+		err = session.NativeError(0, "close %s saver", rw.format)
+	}
+	C.indigoFree(C.int(rw.saver))
+	return err
+}
+
+// release frees the saver of a file writer that was not closed
+func (rw *recordWriter) release() {
+	if rw.closed || rw.saver == 0 || rw.session.Closed() {
+		return
+	}
+	rw.closed = true
+
+	if err := session.Enter(rw.session); err != nil {
+		return
+	}
+	defer session.Exit()
+
+	C.indigoFree(C.int(rw.saver))
+}
+
+// writeChunk renders a chunk into a fresh native buffer, copies it to the io.Writer and frees the buffer
+func (rw *recordWriter) writeChunk(what string, fn func(buffer C.int) C.int) error {
+	if rw.closed {
+		return indigoerr.New(indigoerr.CATEGORY_CLOSED, "%s writer is closed", rw.format)
+	}
+
+	if err := session.Enter(rw.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	buffer := int(C.indigoWriteBuffer())
+	if buffer < 0 {
+		return session.NativeError(0, "create write buffer")
+	}
+	defer C.indigoFree(C.int(buffer))
+
+	if int(fn(C.int(buffer))) < 0 {
+		return session.NativeError(0, "write %s %s", rw.format, what)
+	}
+
+	var cBuf *C.char
+	var size C.int
+	if int(C.indigoToBuffer(C.int(buffer), &cBuf, &size)) < 0 {
+		return session.NativeError(0, "get buffer content")
+	}
+	if size == 0 {
+		return nil
+	}
+
+	if _, err := rw.w.Write(C.GoBytes(unsafe.Pointer(cBuf), size)); err != nil {
+		return &indigoerr.IndigoError{Op: "write " + rw.format + " " + what, Message: err.Error(), Category: indigoerr.CATEGORY_IO, Err: err}
+	}
+	return nil
+}
+
+// appendRecord renders a single item in the given format into a buffer
+func appendRecord(format string, buffer C.int, item C.int) C.int {
+	switch format {
+	case FORMAT_SDF:
+		return C.indigoSdfAppend(buffer, item)
+	case FORMAT_RDF:
+		return C.indigoRdfAppend(buffer, item)
+	case FORMAT_SMILES:
+		return C.indigoSmilesAppend(buffer, item)
+	case FORMAT_CML:
+		return C.indigoCmlAppend(buffer, item)
+	}
+	return -1
+}
+
+// setItemProperties sets the properties of an item in key order
+func setItemProperties(item int, properties map[string]string) error {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cName := C.CString(name)
+		cValue := C.CString(properties[name])
+		ret := int(C.indigoSetProperty(C.int(item), cName, cValue))
+		C.free(unsafe.Pointer(cName))
+		C.free(unsafe.Pointer(cValue))
+		if ret < 0 {
+			return session.NativeError(0, "set property %s", name)
+		}
+	}
+	return nil
+}
@@ -114,13 +114,17 @@ func main() {
 			continue
 		}
 
-		order, _ := molecule.BondOrder(bond)
-		index, _ := molecule.BondIndex(bond)
-		source, _ := molecule.BondSource(bond)
-		dest, _ := molecule.BondDestination(bond)
+		order, _ := bond.Order()
+		index, _ := bond.Index()
+		srcAtom, err := bond.Begin()
+		if err != nil {
+			continue
+		}
+		dstAtom, err := bond.End()
+		if err != nil {
+			continue
+		}
 
-		srcAtom, _ := mol.GetAtom(source)
-		dstAtom, _ := mol.GetAtom(dest)
 		srcSymbol, _ := srcAtom.Symbol()
 		dstSymbol, _ := dstAtom.Symbol()
 
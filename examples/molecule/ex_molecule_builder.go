@@ -178,7 +178,7 @@ func buildAcetate() *molecule.Molecule {
 	m.AddBond(c2, o2, molecule.BOND_SINGLE)
 
 	// Set charge on O2
-	molecule.SetCharge(o2, -1)
+	m.SetCharge(o2, -1)
 
 	return m
 }
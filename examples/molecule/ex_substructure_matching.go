@@ -12,7 +12,6 @@ import (
 	"log"
 
 	"github.com/cx-luo/go-indigo/core"
-	"github.com/cx-luo/go-indigo/molecule"
 )
 
 func main() {
@@ -83,8 +82,10 @@ func main() {
 			fmt.Printf("Failed to get first atom from mol1: %v\n", err)
 		} else {
 			// Use atom handle from queryAtom to map
-			mappedAtomHandle := molecule.MapAtom(mapping2, queryAtom.Handle)
-			if mappedAtomHandle <= 0 {
+			mappedAtomHandle, err := mol1.MapAtom(mapping2, queryAtom.Handle)
+			if err != nil {
+				fmt.Printf("Failed to map atom: %v\n", err)
+			} else if mappedAtomHandle == 0 {
 				fmt.Printf("  CCO matches OCC: true (atom not mapped)\n")
 			} else {
 				targetAtomIndex, err := indigoInit.Index(mappedAtomHandle)
@@ -0,0 +1,142 @@
+// Package indigoerr provides the structured errors returned by the go-indigo packages
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigoerr.go
+// @Software: GoLand
+package indigoerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Category classifies an IndigoError
+type Category string
+
+// Error categories
+const (
+	CATEGORY_INTERNAL  Category = "internal"  // unclassified native failure
+	CATEGORY_PARSE     Category = "parse"     // malformed input, e.g. bad SMILES or molfile
+	CATEGORY_VALENCE   Category = "valence"   // chemically invalid structure
+	CATEGORY_CLOSED    Category = "closed"    // object or session already closed
+	CATEGORY_NOT_FOUND Category = "not-found" // no match, missing property, index out of range
+	CATEGORY_OPTION    Category = "option"    // unknown option or bad option value
+	CATEGORY_IO        Category = "io"        // file or buffer access
+	CATEGORY_INVALID   Category = "invalid"   // invalid handle or argument, object of another session
+)
+
+// Sentinel errors, one per category, to be used with errors.Is
+// ErrNoMatch and ErrWrongSession are more specific causes within CATEGORY_NOT_FOUND and CATEGORY_INVALID
+var (
+	ErrInternal = errors.New("indigo internal error")
+	ErrParse    = errors.New("indigo parse error")
+	ErrValence  = errors.New("indigo valence error")
+	ErrClosed   = errors.New("indigo object is closed")
+	ErrNotFound = errors.New("indigo object not found")
+	ErrOption   = errors.New("indigo option error")
+	ErrIO       = errors.New("indigo i/o error")
+	ErrInvalid  = errors.New("invalid indigo handle or argument")
+
+	ErrNoMatch      = errors.New("no match found")
+	ErrWrongSession = errors.New("object belongs to a different indigo session")
+)
+
+var categoryErrors = map[Category]error{
+	CATEGORY_INTERNAL:  ErrInternal,
+	CATEGORY_PARSE:     ErrParse,
+	CATEGORY_VALENCE:   ErrValence,
+	CATEGORY_CLOSED:    ErrClosed,
+	CATEGORY_NOT_FOUND: ErrNotFound,
+	CATEGORY_OPTION:    ErrOption,
+	CATEGORY_IO:        ErrIO,
+	CATEGORY_INVALID:   ErrInvalid,
+}
+
+// IndigoError describes a failed operation
+//
+//	var ierr *indigoerr.IndigoError
+//	if errors.As(err, &ierr) && ierr.Category == indigoerr.CATEGORY_PARSE { ... }
+//	if errors.Is(err, indigoerr.ErrClosed) { ... }
+type IndigoError struct {
+	Op       string // operation that failed, e.g. "load molecule from string"; empty for plain errors
+	Message  string // native error message, or the description of the error
+	Handle   int    // handle of the object involved, 0 if none
+	Category Category
+	Err      error // more specific cause, e.g. ErrNoMatch
+}
+
+// Error formats the error as "failed to <op>: <message>"
+func (e *IndigoError) Error() string {
+	switch {
+	case e.Op == "":
+		return e.Message
+	case e.Message == "":
+		return "failed to " + e.Op
+	}
+	return "failed to " + e.Op + ": " + e.Message
+}
+
+// Unwrap returns the specific cause, if any
+func (e *IndigoError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of the category
+func (e *IndigoError) Is(target error) bool {
+	return target == categoryErrors[e.Category]
+}
+
+// Native creates the error of a failed native call from the native error message
+// The category is derived from the message
+func Native(op string, message string, handle int) *IndigoError {
+	return &IndigoError{Op: op, Message: message, Handle: handle, Category: Classify(message)}
+}
+
+// New creates an error of the given category
+func New(category Category, format string, args ...interface{}) *IndigoError {
+	return &IndigoError{Message: fmt.Sprintf(format, args...), Category: category}
+}
+
+// Closed creates the error for using a closed object, e.g. Closed("molecule")
+func Closed(what string) *IndigoError {
+	return &IndigoError{Message: what + " is closed", Category: CATEGORY_CLOSED}
+}
+
+// NoMatch creates the error for a search without result
+func NoMatch(handle int) *IndigoError {
+	return &IndigoError{Message: ErrNoMatch.Error(), Handle: handle, Category: CATEGORY_NOT_FOUND, Err: ErrNoMatch}
+}
+
+// Classify derives the category of a native error message
+func Classify(message string) Category {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "option"):
+		return CATEGORY_OPTION
+	case containsAny(msg, "can not open", "cannot open", "no such file", "i/o", "output"):
+		return CATEGORY_IO
+	case strings.Contains(msg, "valence"):
+		return CATEGORY_VALENCE
+	case containsAny(msg, "loader", "parse", "syntax", "unexpected", "smiles", "smarts", "molfile", "rxnfile", "scanner", "inchi"):
+		return CATEGORY_PARSE
+	case containsAny(msg, "not found", "no such", "out of range", "does not exist"):
+		return CATEGORY_NOT_FOUND
+	case containsAny(msg, "invalid object", "can not access", "not an object", "object type"):
+		return CATEGORY_INVALID
+	}
+	return CATEGORY_INTERNAL
+}
+
+// containsAny checks if s contains any of the substrings
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
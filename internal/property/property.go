@@ -0,0 +1,128 @@
+// Package property reads and parses the properties of Indigo objects, shared by molecules, reactions and records
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : property.go
+// @Software: GoLand
+package property
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// All returns every property of the object with the given handle, such as SD file data fields
+// The session of the object must be active on the calling thread
+func All(handle int) (map[string]string, error) {
+	iter := int(C.indigoIterateProperties(C.int(handle)))
+	if iter < 0 {
+		return nil, session.NativeError(handle, "iterate properties")
+	}
+	defer C.indigoFree(C.int(iter))
+
+	props := make(map[string]string)
+	for {
+		item := int(C.indigoNext(C.int(iter)))
+		if item == 0 {
+			return props, nil
+		}
+		if item < 0 {
+			return nil, session.NativeError(handle, "get next property")
+		}
+
+		name, value, err := read(handle, item)
+		C.indigoFree(C.int(item))
+		if err != nil {
+			return nil, err
+		}
+		props[name] = value
+	}
+}
+
+// read returns the name and the value of a property item
+func read(handle int, item int) (string, string, error) {
+	// Names and values share a temporary buffer, copy each before the next call
+	cName := C.indigoName(C.int(item))
+	if cName == nil {
+		return "", "", session.NativeError(handle, "get property name")
+	}
+	name := C.GoString(cName)
+
+	cValue := C.indigoRawData(C.int(item))
+	if cValue == nil {
+		return "", "", session.NativeError(handle, "get property %s", name)
+	}
+
+	return name, C.GoString(cValue), nil
+}
+
+// ParseFloat parses the value of property prop as a float, ignoring surrounding whitespace
+func ParseFloat(prop string, value string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, parseError(prop, value, "a number", err)
+	}
+	return f, nil
+}
+
+// ParseInt parses the value of property prop as an integer, ignoring surrounding whitespace
+func ParseInt(prop string, value string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, parseError(prop, value, "an integer", err)
+	}
+	return i, nil
+}
+
+// ParseBool parses the value of property prop as a boolean, ignoring surrounding whitespace
+// Accepts the strconv.ParseBool forms as well as yes/no, case-insensitive
+func ParseBool(prop string, value string) (bool, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	switch v {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, parseError(prop, value, "a boolean", err)
+	}
+	return b, nil
+}
+
+// parseError builds the error of a property value that cannot be parsed, keeping the strconv cause
+func parseError(prop string, value string, what string, err error) error {
+	return &indigoerr.IndigoError{
+		Op:       "parse property " + prop,
+		Message:  fmt.Sprintf("%q is not %s", value, what),
+		Category: indigoerr.CATEGORY_PARSE,
+		Err:      err,
+	}
+}
@@ -0,0 +1,46 @@
+// Package session routes native Indigo errors to Go handlers
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : errors.go
+// @Software: GoLand
+package session
+
+// The preamble of a file with //export may only hold declarations, the C side lives in session.go
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+import "sync"
+
+// handlers maps native session ids to the Go error handlers set with SetErrorHandler
+var handlers sync.Map
+
+// SetErrorHandler sets the handler receiving the native errors of a session, nil removes it
+// The session must have the native handler installed with InstallErrorHandler
+func SetErrorHandler(id uint64, handler func(msg string)) {
+	if handler == nil {
+		handlers.Delete(id)
+		return
+	}
+	handlers.Store(id, handler)
+}
+
+// goindigoDispatchError is called by the native error handler on the thread of the failing call
+//
+//export goindigoDispatchError
+func goindigoDispatchError(message *C.char, sid C.ulonglong) {
+	h, ok := handlers.Load(uint64(sid))
+	if !ok {
+		return
+	}
+
+	// A panic must not unwind through the native frames
+	defer func() {
+		_ = recover()
+	}()
+	h.(func(string))(C.GoString(message))
+}
@@ -0,0 +1,263 @@
+// Package session pins goroutines to native Indigo sessions
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : session.go
+// @Software: GoLand
+package session
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include <string.h>
+#include <stdint.h>
+#include "indigo.h"
+
+// Implemented in Go, see errors.go
+extern void goindigoDispatchError(char* message, unsigned long long sid);
+
+// The last native error raised on the current thread since the outermost Enter
+static __thread char goindigo_error[4096];
+static __thread int goindigo_has_error = 0;
+
+static void goindigo_error_handler(const char* message, void* context) {
+	if (message == NULL) {
+		message = "";
+	}
+	strncpy(goindigo_error, message, sizeof(goindigo_error) - 1);
+	goindigo_error[sizeof(goindigo_error) - 1] = 0;
+	goindigo_has_error = 1;
+	goindigoDispatchError((char*)message, (unsigned long long)(uintptr_t)context);
+}
+
+// Routes the errors of the current session to goindigo_error_handler
+static void goindigo_install_error_handler(unsigned long long sid) {
+	indigoSetErrorHandler(goindigo_error_handler, (void*)(uintptr_t)sid);
+}
+
+static void goindigo_clear_error(void) {
+	goindigo_has_error = 0;
+}
+
+static const char* goindigo_last_error(void) {
+	return goindigo_has_error ? goindigo_error : NULL;
+}
+
+// The session active on the current thread, the nesting depth of Enter calls
+// and the depth at which the session was entered
+static __thread unsigned long long goindigo_sid = 0;
+static __thread int goindigo_depth = 0;
+static __thread int goindigo_sid_depth = 0;
+
+static int goindigo_enter(unsigned long long sid) {
+	if (sid != 0) {
+		if (goindigo_sid != 0 && goindigo_sid != sid) {
+			return -1;
+		}
+		if (goindigo_sid == 0) {
+			goindigo_sid = sid;
+			goindigo_sid_depth = goindigo_depth;
+		}
+		indigoSetSessionId(sid);
+	}
+	if (goindigo_depth == 0) {
+		goindigo_has_error = 0;
+	}
+	goindigo_depth++;
+	return 0;
+}
+
+static void goindigo_exit(void) {
+	goindigo_depth--;
+	if (goindigo_sid != 0 && goindigo_depth == goindigo_sid_depth) {
+		goindigo_sid = 0;
+	}
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+var (
+	// ErrClosed is returned when an object is used after its session was closed
+	ErrClosed = &indigoerr.IndigoError{Message: "indigo session is closed", Category: indigoerr.CATEGORY_CLOSED}
+	// ErrWrongSession is returned when objects of different sessions are used together
+	ErrWrongSession = &indigoerr.IndigoError{
+		Message:  indigoerr.ErrWrongSession.Error(),
+		Category: indigoerr.CATEGORY_INVALID,
+		Err:      indigoerr.ErrWrongSession,
+	}
+)
+
+// Session is a native Indigo session shared by all objects created in it
+// It is exposed as core.Session. A nil *Session stands for objects not bound to any session; they use the session active on the thread
+type Session struct {
+	id     uint64
+	closed atomic.Bool
+}
+
+// New wraps a native session id
+func New(id uint64) *Session {
+	return &Session{id: id}
+}
+
+// ID returns the native session id
+func (s *Session) ID() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.id
+}
+
+// Closed checks if the session was closed
+func (s *Session) Closed() bool {
+	return s != nil && s.closed.Load()
+}
+
+// Close marks the session as closed; the caller releases the native session
+// It is a function rather than a method, so that the public alias core.Session cannot close sessions
+func Close(s *Session) {
+	if s != nil {
+		s.closed.Store(true)
+	}
+}
+
+// Enter pins the calling goroutine to its OS thread and activates the session on it
+// Every successful Enter must be paired with Exit. Nested calls for the same session are allowed,
+// while entering a different session inside an active one returns ErrWrongSession
+func Enter(s *Session) error {
+	if s.Closed() {
+		return ErrClosed
+	}
+
+	runtime.LockOSThread()
+	if int(C.goindigo_enter(C.ulonglong(s.ID()))) < 0 {
+		runtime.UnlockOSThread()
+		return ErrWrongSession
+	}
+	return nil
+}
+
+// Exit undoes the matching Enter
+func Exit() {
+	C.goindigo_exit()
+	runtime.UnlockOSThread()
+}
+
+// InstallErrorHandler routes the native errors of the session active on the thread to
+// LastError and to the handler set with SetErrorHandler; the session must be active on the thread
+func InstallErrorHandler(id uint64) {
+	C.goindigo_install_error_handler(C.ulonglong(id))
+}
+
+// LastError returns the native error raised on the current thread since the outermost Enter,
+// or "" if there was none or the session has no error handler installed
+func LastError() string {
+	msg := C.goindigo_last_error()
+	if msg == nil {
+		return ""
+	}
+	return C.GoString(msg)
+}
+
+// NativeMessage returns the message of the last native error, "" if there is none
+// The message captured for the current call is preferred over the one kept by indigoGetLastError
+func NativeMessage() string {
+	if msg := LastError(); msg != "" {
+		return msg
+	}
+
+	msg := C.indigoGetLastError()
+	if msg == nil {
+		return ""
+	}
+	return C.GoString(msg)
+}
+
+// NativeError builds the error of a failed native call on the object with the given handle
+// (0 if none) from the last native error; op describes the call, formatted with args
+func NativeError(handle int, op string, args ...interface{}) error {
+	msg := NativeMessage()
+	if msg == "" {
+		msg = "unknown error"
+	}
+	return indigoerr.Native(fmt.Sprintf(op, args...), msg, handle)
+}
+
+// The session used for work that is not bound to any object, e.g. checking a loose SMILES string
+var (
+	defaultOnce    sync.Once
+	defaultSession *Session
+	defaultErr     error
+	defaultMu      sync.Mutex // a native session must not be used by two threads at once
+)
+
+// EnterDefault enters the default session, allocating it on first use
+// The default session is owned by this package, so it is never shared with a pool or recycled.
+// Calls are serialized and must not be nested; every successful EnterDefault must be paired with ExitDefault
+func EnterDefault() error {
+	defaultOnce.Do(func() {
+		id := uint64(C.indigoAllocSessionId())
+		if id == 0 {
+			defaultErr = indigoerr.New(indigoerr.CATEGORY_INTERNAL, "failed to alloc default session")
+			return
+		}
+		defaultSession = New(id)
+		if defaultErr = Enter(defaultSession); defaultErr == nil {
+			InstallErrorHandler(id)
+			Exit()
+		}
+	})
+	if defaultErr != nil {
+		return defaultErr
+	}
+
+	defaultMu.Lock()
+	if err := Enter(defaultSession); err != nil {
+		defaultMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ExitDefault undoes the matching EnterDefault
+func ExitDefault() {
+	Exit()
+	defaultMu.Unlock()
+}
+
+// ClearError forgets the native error captured on the current thread
+// Use it before native calls whose result cannot tell success from failure, then check LastError
+func ClearError() {
+	C.goindigo_clear_error()
+}
+
+// Same checks that two objects can be used together
+// Unbound objects are compatible with any session
+func Same(a *Session, b *Session) error {
+	if a != nil && b != nil && a != b {
+		return ErrWrongSession
+	}
+	return nil
+}
@@ -27,9 +27,11 @@ package molecule
 */
 import "C"
 import (
-	"fmt"
 	"runtime"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // Radical constants
@@ -47,20 +49,44 @@ const (
 )
 
 // Molecule represents a chemical molecule in Indigo
+// A molecule is bound to the session that created it, and every call runs in that session
 type Molecule struct {
-	Handle int
-	Closed bool
+	Handle  int
+	Closed  bool
+	session *session.Session
+}
+
+// FromHandle wraps a molecule handle created in the session s, see core.Indigo.Session
+// s may be nil for unbound molecules
+func FromHandle(handle int, s *session.Session) *Molecule {
+	return newMolecule(handle, s)
+}
+
+// Session returns the session the molecule belongs to, nil if unbound
+func (m *Molecule) Session() *session.Session {
+	return m.session
 }
 
 // Close frees the Indigo molecule object
+// If the session was already closed, the handle is gone with it and only the molecule is marked closed
 func (m *Molecule) Close() error {
 	if m.Closed || m.Handle < 0 {
 		return nil
 	}
 
+	if m.session.Closed() {
+		m.Closed = true
+		m.Handle = -1
+		return nil
+	}
+	if err := session.Enter(m.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoFree(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to free molecule: %s", getLastError())
+		return session.NativeError(m.Handle, "free molecule")
 	}
 
 	m.Closed = true
@@ -70,33 +96,29 @@ func (m *Molecule) Close() error {
 
 // Clone creates a deep copy of the molecule
 func (m *Molecule) Clone() (*Molecule, error) {
-	if m.Closed {
-		return nil, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	newHandle := int(C.indigoClone(C.int(m.Handle)))
 	if newHandle < 0 {
-		return nil, fmt.Errorf("failed to clone molecule: %s", getLastError())
-	}
-
-	newMol := &Molecule{
-		Handle: newHandle,
-		Closed: false,
+		return nil, session.NativeError(m.Handle, "clone molecule")
 	}
 
-	runtime.SetFinalizer(newMol, (*Molecule).Close)
-	return newMol, nil
+	return newMolecule(newHandle, m.session), nil
 }
 
 // CountAtoms returns the number of atoms in the molecule
 func (m *Molecule) CountAtoms() (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountAtoms(C.int(m.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count atoms: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "count atoms")
 	}
 
 	return count, nil
@@ -104,13 +126,14 @@ func (m *Molecule) CountAtoms() (int, error) {
 
 // CountBonds returns the number of bonds in the molecule
 func (m *Molecule) CountBonds() (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountBonds(C.int(m.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count bonds: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "count bonds")
 	}
 
 	return count, nil
@@ -118,13 +141,14 @@ func (m *Molecule) CountBonds() (int, error) {
 
 // CountHeavyAtoms returns the number of heavy (non-hydrogen) atoms
 func (m *Molecule) CountHeavyAtoms() (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountHeavyAtoms(C.int(m.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count heavy atoms: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "count heavy atoms")
 	}
 
 	return count, nil
@@ -132,13 +156,14 @@ func (m *Molecule) CountHeavyAtoms() (int, error) {
 
 // Aromatize performs aromatization of the molecule
 func (m *Molecule) Aromatize() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoAromatize(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to aromatize: %s", getLastError())
+		return session.NativeError(m.Handle, "aromatize")
 	}
 
 	return nil
@@ -146,13 +171,14 @@ func (m *Molecule) Aromatize() error {
 
 // Dearomatize removes aromaticity from the molecule
 func (m *Molecule) Dearomatize() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoDearomatize(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to dearomatize: %s", getLastError())
+		return session.NativeError(m.Handle, "dearomatize")
 	}
 
 	return nil
@@ -160,13 +186,14 @@ func (m *Molecule) Dearomatize() error {
 
 // FoldHydrogens folds hydrogens in the molecule
 func (m *Molecule) FoldHydrogens() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoFoldHydrogens(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to fold hydrogens: %s", getLastError())
+		return session.NativeError(m.Handle, "fold hydrogens")
 	}
 
 	return nil
@@ -174,13 +201,14 @@ func (m *Molecule) FoldHydrogens() error {
 
 // UnfoldHydrogens unfolds hydrogens in the molecule
 func (m *Molecule) UnfoldHydrogens() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoUnfoldHydrogens(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to unfold hydrogens: %s", getLastError())
+		return session.NativeError(m.Handle, "unfold hydrogens")
 	}
 
 	return nil
@@ -188,13 +216,14 @@ func (m *Molecule) UnfoldHydrogens() error {
 
 // Layout performs 2D layout of the molecule
 func (m *Molecule) Layout() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoLayout(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to layout: %s", getLastError())
+		return session.NativeError(m.Handle, "layout")
 	}
 
 	return nil
@@ -202,13 +231,14 @@ func (m *Molecule) Layout() error {
 
 // Clean2D performs 2D cleaning of the molecule
 func (m *Molecule) Clean2D() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoClean2d(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to clean2d: %s", getLastError())
+		return session.NativeError(m.Handle, "clean2d")
 	}
 
 	return nil
@@ -217,16 +247,17 @@ func (m *Molecule) Clean2D() error {
 // Normalize normalizes the molecule structure
 // It neutralizes charges, resolves 5-valence Nitrogen, removes hydrogens, etc.
 func (m *Molecule) Normalize(options string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cOptions := C.CString(options)
 	defer C.free(unsafe.Pointer(cOptions))
 
 	ret := int(C.indigoNormalize(C.int(m.Handle), cOptions))
 	if ret < 0 {
-		return fmt.Errorf("failed to normalize: %s", getLastError())
+		return session.NativeError(m.Handle, "normalize")
 	}
 
 	return nil
@@ -234,13 +265,14 @@ func (m *Molecule) Normalize(options string) error {
 
 // Standardize standardizes the molecule structure
 func (m *Molecule) Standardize() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoStandardize(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to standardize: %s", getLastError())
+		return session.NativeError(m.Handle, "standardize")
 	}
 
 	return nil
@@ -248,13 +280,14 @@ func (m *Molecule) Standardize() error {
 
 // Ionize ionizes the molecule at specified pH and pH tolerance
 func (m *Molecule) Ionize(pH float32, pHTolerance float32) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoIonize(C.int(m.Handle), C.float(pH), C.float(pHTolerance)))
 	if ret < 0 {
-		return fmt.Errorf("failed to ionize: %s", getLastError())
+		return session.NativeError(m.Handle, "ionize")
 	}
 
 	return nil
@@ -262,13 +295,14 @@ func (m *Molecule) Ionize(pH float32, pHTolerance float32) error {
 
 // CountComponents returns the number of connected components
 func (m *Molecule) CountComponents() (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountComponents(C.int(m.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count components: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "count components")
 	}
 
 	return count, nil
@@ -276,13 +310,14 @@ func (m *Molecule) CountComponents() (int, error) {
 
 // CountSSSR returns the number of smallest set of smallest rings
 func (m *Molecule) CountSSSR() (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountSSSR(C.int(m.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count SSSR: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "count SSSR")
 	}
 
 	return count, nil
@@ -290,20 +325,73 @@ func (m *Molecule) CountSSSR() (int, error) {
 
 // newMolecule is a helper function to create a Molecule object from a handle
 // It sets up the finalizer to ensure proper cleanup
-func newMolecule(handle int) *Molecule {
+func newMolecule(handle int, s *session.Session) *Molecule {
 	m := &Molecule{
-		Handle: handle,
-		Closed: false,
+		Handle:  handle,
+		Closed:  false,
+		session: s,
 	}
 	runtime.SetFinalizer(m, (*Molecule).Close)
 	return m
 }
 
-// getLastError retrieves the last error message from Indigo
-func getLastError() string {
-	errMsg := C.indigoGetLastError()
-	if errMsg == nil {
-		return "unknown error"
+// enter pins the calling goroutine to the molecule's session; pair it with session.Exit
+func (m *Molecule) enter() error {
+	if m.Closed {
+		return indigoerr.Closed("molecule")
+	}
+	return session.Enter(m.session)
+}
+
+// commonSession returns the session shared by the molecules
+// Unbound molecules are compatible with any session
+func commonSession(mols []*Molecule) (*session.Session, error) {
+	var s *session.Session
+	for i, mol := range mols {
+		if mol == nil || mol.Closed {
+			return nil, indigoerr.New(indigoerr.CATEGORY_CLOSED, "molecule %d is closed", i)
+		}
+		if err := session.Same(s, mol.session); err != nil {
+			return nil, err
+		}
+		if s == nil {
+			s = mol.session
+		}
+	}
+	return s, nil
+}
+
+// iterateHandles walks an Indigo iterator, calling fn for each element
+// Both the iterator and every element handle are freed before returning
+func iterateHandles(iterHandle int, fn func(item int) error) error {
+	defer C.indigoFree(C.int(iterHandle))
+
+	for {
+		item := int(C.indigoNext(C.int(iterHandle)))
+		if item == 0 {
+			return nil
+		}
+		if item < 0 {
+			return session.NativeError(0, "get next element")
+		}
+
+		err := fn(item)
+		C.indigoFree(C.int(item))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// nativeFloat runs a native call whose error value -1 is also a valid result, e.g. logP or pKa,
+// telling failures by a new error from the session handler or indigoGetLastError
+func nativeFloat(handle int, op string, call func() C.double) (float64, error) {
+	session.ClearError()
+	before := C.GoString(C.indigoGetLastError())
+
+	value := float64(call())
+	if value == -1 && (session.LastError() != "" || C.GoString(C.indigoGetLastError()) != before) {
+		return 0, session.NativeError(handle, op)
 	}
-	return C.GoString(errMsg)
+	return value, nil
 }
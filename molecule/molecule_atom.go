@@ -28,230 +28,650 @@ package molecule
 */
 import "C"
 import (
-	"fmt"
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Bond topology constants
+const (
+	TOPOLOGY_CHAIN = 9
+	TOPOLOGY_RING  = 10
+)
+
+// Atom hybridization constants
+const (
+	HYBRIDIZATION_S     = 1
+	HYBRIDIZATION_SP    = 2
+	HYBRIDIZATION_SP2   = 3
+	HYBRIDIZATION_SP3   = 4
+	HYBRIDIZATION_SP3D  = 5
+	HYBRIDIZATION_SP3D2 = 6
+	HYBRIDIZATION_SP3D3 = 7
+	HYBRIDIZATION_SP3D4 = 8
+	HYBRIDIZATION_SP2D  = 9
 )
 
 // Atom represents an atom in a molecule
 type Atom struct {
-	Handle int
+	Handle   int
+	session  *session.Session
+	borrowed bool // the handle is owned by an iterator, Close leaves it alone
+}
+
+// Bond represents a bond in a molecule
+type Bond struct {
+	Handle   int
+	session  *session.Session
+	borrowed bool // the handle is owned by an iterator, Close leaves it alone
+}
+
+// Neighbor is an atom adjacent to another atom together with the connecting bond
+// Both own their handles; call Close on the neighbor when done, their Index methods give the positions in the molecule
+type Neighbor struct {
+	Atom *Atom
+	Bond *Bond
+}
+
+// Close frees the handles of the neighbor atom and bond
+func (n Neighbor) Close() error {
+	atomErr := n.Atom.Close()
+	if err := n.Bond.Close(); err != nil {
+		return err
+	}
+	return atomErr
 }
 
 // GetAtom returns an atom by its index
+// Call Close on the atom when done to free its handle
 func (m *Molecule) GetAtom(index int) (*Atom, error) {
-	if m.Closed {
-		return nil, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoGetAtom(C.int(m.Handle), C.int(index)))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to get atom at index %d: %s", index, getLastError())
+		return nil, session.NativeError(m.Handle, "get atom at index %d", index)
 	}
 
-	return &Atom{Handle: handle}, nil
+	return &Atom{Handle: handle, session: m.session}, nil
 }
 
 // GetBond returns a bond by its index
-func (m *Molecule) GetBond(index int) (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+// Call Close on the bond when done to free its handle
+func (m *Molecule) GetBond(index int) (*Bond, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoGetBond(C.int(m.Handle), C.int(index)))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to get bond at index %d: %s", index, getLastError())
+		return nil, session.NativeError(m.Handle, "get bond at index %d", index)
 	}
 
-	return handle, nil
+	return &Bond{Handle: handle, session: m.session}, nil
+}
+
+// Close frees the atom handle; the atom itself stays in the molecule
+// Closing an atom yielded by an iterator does nothing, the iterator frees it
+func (a *Atom) Close() error {
+	if a.Handle < 0 || a.borrowed {
+		return nil
+	}
+
+	if a.session.Closed() {
+		a.Handle = -1
+		return nil
+	}
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoFree(C.int(a.Handle)))
+	if ret < 0 {
+		return session.NativeError(a.Handle, "free atom")
+	}
+
+	a.Handle = -1
+	return nil
 }
 
 // Symbol returns the element symbol of an atom
 func (a *Atom) Symbol() (string, error) {
+	if err := session.Enter(a.session); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
 	cStr := C.indigoSymbol(C.int(a.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get atom symbol: %s", getLastError())
+		return "", session.NativeError(a.Handle, "get atom symbol")
 	}
 	return C.GoString(cStr), nil
 }
 
 // Degree returns the number of neighbors of an atom
 func (a *Atom) Degree() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	degree := int(C.indigoDegree(C.int(a.Handle)))
 	if degree < 0 {
-		return 0, fmt.Errorf("failed to get atom degree: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get atom degree")
 	}
 	return degree, nil
 }
 
 // Index returns the index of an atom in its molecule
 func (a *Atom) Index() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	index := int(C.indigoAtomIndex(C.int(a.Handle)))
 	if index < 0 {
-		return 0, fmt.Errorf("failed to get atom index: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get atom index")
 	}
 	return index, nil
 }
 
 // AtomicNumber returns the atomic number of an atom
 func (a *Atom) AtomicNumber() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	number := int(C.indigoAtomicNumber(C.int(a.Handle)))
 	if number < 0 {
-		return 0, fmt.Errorf("failed to get atomic number: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get atomic number")
 	}
 	return number, nil
 }
 
 // Charge returns the charge of an atom
 func (a *Atom) Charge() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	var charge C.int
 	ret := int(C.indigoGetCharge(C.int(a.Handle), &charge))
 	if ret < 0 {
-		return 0, fmt.Errorf("failed to get atom charge: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get atom charge")
 	}
 	return int(charge), nil
 }
 
 // SetCharge sets the charge of an atom
 func (a *Atom) SetCharge(charge int) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetCharge(C.int(a.Handle), C.int(charge)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set atom charge: %s", getLastError())
+		return session.NativeError(a.Handle, "set atom charge")
 	}
 	return nil
 }
 
 // Isotope returns the isotope of an atom (0 if not set)
 func (a *Atom) Isotope() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	isotope := int(C.indigoIsotope(C.int(a.Handle)))
 	if isotope < 0 {
-		return 0, fmt.Errorf("failed to get isotope: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get isotope")
 	}
 	return isotope, nil
 }
 
 // SetIsotope sets the isotope of an atom
 func (a *Atom) SetIsotope(isotope int) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetIsotope(C.int(a.Handle), C.int(isotope)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set isotope: %s", getLastError())
+		return session.NativeError(a.Handle, "set isotope")
 	}
 	return nil
 }
 
 // Valence returns the valence of an atom
 func (a *Atom) Valence() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	valence := int(C.indigoValence(C.int(a.Handle)))
 	if valence < 0 {
-		return 0, fmt.Errorf("failed to get valence: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get valence")
 	}
 	return valence, nil
 }
 
 // ExplicitValence returns the explicit valence of an atom
 func (a *Atom) ExplicitValence() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	var valence C.int
 	ret := int(C.indigoGetExplicitValence(C.int(a.Handle), &valence))
 	if ret < 0 {
-		return 0, fmt.Errorf("failed to get explicit valence: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get explicit valence")
 	}
 	return int(valence), nil
 }
 
 // SetExplicitValence sets the explicit valence of an atom
 func (a *Atom) SetExplicitValence(valence int) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetExplicitValence(C.int(a.Handle), C.int(valence)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set explicit valence: %s", getLastError())
+		return session.NativeError(a.Handle, "set explicit valence")
 	}
 	return nil
 }
 
 // Radical returns the radical type of an atom
 func (a *Atom) Radical() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	var radical C.int
 	ret := int(C.indigoGetRadical(C.int(a.Handle), &radical))
 	if ret < 0 {
-		return 0, fmt.Errorf("failed to get radical: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "get radical")
 	}
 	return int(radical), nil
 }
 
 // SetRadical sets the radical type of an atom
 func (a *Atom) SetRadical(radical int) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetRadical(C.int(a.Handle), C.int(radical)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set radical: %s", getLastError())
+		return session.NativeError(a.Handle, "set radical")
 	}
 	return nil
 }
 
 // CountImplicitHydrogens returns the number of implicit hydrogens
 func (a *Atom) CountImplicitHydrogens() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	count := int(C.indigoCountImplicitHydrogens(C.int(a.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count implicit hydrogens: %s", getLastError())
+		return 0, session.NativeError(a.Handle, "count implicit hydrogens")
 	}
 	return count, nil
 }
 
 // SetImplicitHCount sets the implicit hydrogen count
 func (a *Atom) SetImplicitHCount(count int) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetImplicitHCount(C.int(a.Handle), C.int(count)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set implicit H count: %s", getLastError())
+		return session.NativeError(a.Handle, "set implicit H count")
 	}
 	return nil
 }
 
+// CountHydrogens returns the total number of hydrogens attached to an atom
+func (a *Atom) CountHydrogens() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	var count C.int
+	ret := int(C.indigoCountHydrogens(C.int(a.Handle), &count))
+	if ret < 0 {
+		return 0, session.NativeError(a.Handle, "count hydrogens")
+	}
+	if ret == 0 {
+		return 0, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "number of hydrogens is not definitely known")
+	}
+	return int(count), nil
+}
+
+// Hybridization returns the hybridization of an atom (HYBRIDIZATION_* constants)
+func (a *Atom) Hybridization() (int, error) {
+	if err := session.Enter(a.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	hybridization := int(C.indigoGetHybridization(C.int(a.Handle)))
+	if hybridization < 0 {
+		return 0, session.NativeError(a.Handle, "get hybridization")
+	}
+	return hybridization, nil
+}
+
 // IsPseudoatom checks if an atom is a pseudoatom
 func (a *Atom) IsPseudoatom() bool {
+	if session.Enter(a.session) != nil {
+		return false
+	}
+	defer session.Exit()
+
 	return int(C.indigoIsPseudoatom(C.int(a.Handle))) > 0
 }
 
 // IsRSite checks if an atom is an R-site
 func (a *Atom) IsRSite() bool {
+	if session.Enter(a.session) != nil {
+		return false
+	}
+	defer session.Exit()
+
 	return int(C.indigoIsRSite(C.int(a.Handle))) > 0
 }
 
-// BondOrder returns the order of a bond
-func BondOrder(bondHandle int) (int, error) {
-	order := int(C.indigoBondOrder(C.int(bondHandle)))
+// Neighbors returns the atoms adjacent to an atom and the bonds connecting them
+// Close every neighbor when done, e.g. with CloseNeighbors
+func (a *Atom) Neighbors() ([]Neighbor, error) {
+	if err := session.Enter(a.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateNeighbors(C.int(a.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(a.Handle, "iterate neighbors")
+	}
+	defer C.indigoFree(C.int(iterHandle))
+
+	var neighbors []Neighbor
+	for {
+		nei := int(C.indigoNext(C.int(iterHandle)))
+		if nei == 0 {
+			return neighbors, nil
+		}
+		if nei < 0 {
+			err := session.NativeError(a.Handle, "get next neighbor")
+			CloseNeighbors(neighbors)
+			return nil, err
+		}
+
+		bond := int(C.indigoBond(C.int(nei)))
+		if bond < 0 {
+			err := session.NativeError(a.Handle, "get neighbor bond")
+			C.indigoFree(C.int(nei))
+			CloseNeighbors(neighbors)
+			return nil, err
+		}
+
+		// The neighbor element itself can be used as an atom and outlives the iterator
+		neighbors = append(neighbors, Neighbor{
+			Atom: &Atom{Handle: nei, session: a.session},
+			Bond: &Bond{Handle: bond, session: a.session},
+		})
+	}
+}
+
+// CloseNeighbors closes every neighbor returned by Neighbors
+func CloseNeighbors(neighbors []Neighbor) {
+	for _, nei := range neighbors {
+		nei.Close()
+	}
+}
+
+// Close frees the bond handle; the bond itself stays in the molecule
+// Closing a bond yielded by an iterator does nothing, the iterator frees it
+func (b *Bond) Close() error {
+	if b.Handle < 0 || b.borrowed {
+		return nil
+	}
+
+	if b.session.Closed() {
+		b.Handle = -1
+		return nil
+	}
+	if err := session.Enter(b.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoFree(C.int(b.Handle)))
+	if ret < 0 {
+		return session.NativeError(b.Handle, "free bond")
+	}
+
+	b.Handle = -1
+	return nil
+}
+
+// Order returns the bond order (BOND_SINGLE, BOND_DOUBLE, BOND_TRIPLE, BOND_AROMATIC)
+// or 0 for an ambiguous query bond
+func (b *Bond) Order() (int, error) {
+	if err := session.Enter(b.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	order := int(C.indigoBondOrder(C.int(b.Handle)))
 	if order < 0 {
-		return 0, fmt.Errorf("failed to get bond order: %s", getLastError())
+		return 0, session.NativeError(b.Handle, "get bond order")
 	}
 	return order, nil
 }
 
-// BondIndex returns the index of a bond
-func BondIndex(bondHandle int) (int, error) {
-	index := int(C.indigoBondIndex(C.int(bondHandle)))
+// SetOrder sets the bond order
+func (b *Bond) SetOrder(order int) error {
+	if err := session.Enter(b.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoSetBondOrder(C.int(b.Handle), C.int(order)))
+	if ret < 0 {
+		return session.NativeError(b.Handle, "set bond order")
+	}
+	return nil
+}
+
+// IsAromatic checks if the bond is aromatic
+func (b *Bond) IsAromatic() (bool, error) {
+	if err := session.Enter(b.session); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	order, err := b.Order()
+	if err != nil {
+		return false, err
+	}
+	return order == BOND_AROMATIC, nil
+}
+
+// Stereo returns the bond stereo (STEREO_UP, STEREO_DOWN, STEREO_EITHER, STEREO_CIS, STEREO_TRANS)
+// or 0 if the bond is not a stereo bond
+func (b *Bond) Stereo() (int, error) {
+	if err := session.Enter(b.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	stereo := int(C.indigoBondStereo(C.int(b.Handle)))
+	if stereo < 0 {
+		return 0, session.NativeError(b.Handle, "get bond stereo")
+	}
+	return stereo, nil
+}
+
+// Topology returns TOPOLOGY_RING if the bond is in a ring, TOPOLOGY_CHAIN otherwise
+func (b *Bond) Topology() (int, error) {
+	if err := session.Enter(b.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	topology := int(C.indigoTopology(C.int(b.Handle)))
+	if topology < 0 {
+		return 0, session.NativeError(b.Handle, "get bond topology")
+	}
+	return topology, nil
+}
+
+// Index returns the index of a bond in its molecule
+func (b *Bond) Index() (int, error) {
+	if err := session.Enter(b.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	index := int(C.indigoBondIndex(C.int(b.Handle)))
 	if index < 0 {
-		return 0, fmt.Errorf("failed to get bond index: %s", getLastError())
+		return 0, session.NativeError(b.Handle, "get bond index")
 	}
 	return index, nil
 }
 
-// BondSource returns the source atom of a bond
-func BondSource(bondHandle int) (int, error) {
-	handle := int(C.indigoSource(C.int(bondHandle)))
+// Begin returns the source atom of the bond
+func (b *Bond) Begin() (*Atom, error) {
+	if err := session.Enter(b.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoSource(C.int(b.Handle)))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to get bond source: %s", getLastError())
+		return nil, session.NativeError(b.Handle, "get bond source")
 	}
-	return handle, nil
+	return &Atom{Handle: handle, session: b.session}, nil
 }
 
-// BondDestination returns the destination atom of a bond
-func BondDestination(bondHandle int) (int, error) {
-	handle := int(C.indigoDestination(C.int(bondHandle)))
+// End returns the destination atom of the bond
+func (b *Bond) End() (*Atom, error) {
+	if err := session.Enter(b.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoDestination(C.int(b.Handle)))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to get bond destination: %s", getLastError())
+		return nil, session.NativeError(b.Handle, "get bond destination")
 	}
-	return handle, nil
+	return &Atom{Handle: handle, session: b.session}, nil
 }
 
-// MapAtom maps an atom from a query molecule to the target molecule using an exact match mapping.
+// Remove removes the bond from its molecule
+func (b *Bond) Remove() error {
+	if err := session.Enter(b.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoRemove(C.int(b.Handle)))
+	if ret < 0 {
+		return session.NativeError(b.Handle, "remove bond")
+	}
+	return nil
+}
+
+// MapAtom maps an atom of this molecule to the target molecule using an exact match mapping.
 // mappingHandle is the handle returned by ExactMatch()
 // queryAtomHandle is the atom handle from the query molecule
 // Returns the corresponding atom handle in the target molecule, or 0 if no mapping exists
+func (m *Molecule) MapAtom(mappingHandle int, queryAtomHandle int) (int, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoMapAtom(C.int(mappingHandle), C.int(queryAtomHandle)))
+	if handle < 0 {
+		return 0, session.NativeError(mappingHandle, "map atom")
+	}
+	return handle, nil
+}
+
+// BondOrder returns the order of a bond
+//
+// Deprecated: use Bond.Order, which runs in the session of the bond.
+func BondOrder(bondHandle int) (int, error) {
+	return (&Bond{Handle: bondHandle}).Order()
+}
+
+// BondIndex returns the index of a bond
+//
+// Deprecated: use Bond.Index, which runs in the session of the bond.
+func BondIndex(bondHandle int) (int, error) {
+	return (&Bond{Handle: bondHandle}).Index()
+}
+
+// BondSource returns the source atom of a bond
+//
+// Deprecated: use Bond.Begin, which runs in the session of the bond.
+func BondSource(bondHandle int) (int, error) {
+	atom, err := (&Bond{Handle: bondHandle}).Begin()
+	if err != nil {
+		return 0, err
+	}
+	return atom.Handle, nil
+}
+
+// BondDestination returns the destination atom of a bond
+//
+// Deprecated: use Bond.End, which runs in the session of the bond.
+func BondDestination(bondHandle int) (int, error) {
+	atom, err := (&Bond{Handle: bondHandle}).End()
+	if err != nil {
+		return 0, err
+	}
+	return atom.Handle, nil
+}
+
+// MapAtom maps an atom from a query molecule to the target molecule using an exact match mapping.
+// Returns the corresponding atom handle in the target molecule, 0 if no mapping exists or -1 on error
+//
+// Deprecated: use Molecule.MapAtom, which runs in the session of the molecule and reports errors.
 func MapAtom(mappingHandle int, queryAtomHandle int) int {
-	return int(C.indigoMapAtom(C.int(mappingHandle), C.int(queryAtomHandle)))
+	handle, err := unbound().MapAtom(mappingHandle, queryAtomHandle)
+	if err != nil {
+		return -1
+	}
+	return handle
+}
+
+// unbound returns a molecule without session, for the deprecated functions taking raw handles
+// They run in the session active on the calling thread, as they did before molecules were bound
+func unbound() *Molecule {
+	return &Molecule{Handle: -1}
 }
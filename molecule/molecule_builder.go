@@ -28,8 +28,10 @@ package molecule
 */
 import "C"
 import (
-	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // Bond order constants
@@ -44,29 +46,35 @@ const (
 // symbol: element symbol (e.g., "C", "N", "O") or pseudoatom label
 // Returns the handle of the added atom
 func (m *Molecule) AddAtom(symbol string) (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	cSymbol := C.CString(symbol)
 	defer C.free(unsafe.Pointer(cSymbol))
 
 	handle := int(C.indigoAddAtom(C.int(m.Handle), cSymbol))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to add atom %s: %s", symbol, getLastError())
+		return 0, session.NativeError(m.Handle, "add atom %s", symbol)
 	}
 
 	return handle, nil
 }
 
 // ResetAtom resets an atom to a new element
-func ResetAtom(atomHandle int, symbol string) error {
+func (m *Molecule) ResetAtom(atomHandle int, symbol string) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	cSymbol := C.CString(symbol)
 	defer C.free(unsafe.Pointer(cSymbol))
 
 	ret := int(C.indigoResetAtom(C.int(atomHandle), cSymbol))
 	if ret < 0 {
-		return fmt.Errorf("failed to reset atom: %s", getLastError())
+		return session.NativeError(atomHandle, "reset atom")
 	}
 
 	return nil
@@ -76,29 +84,35 @@ func ResetAtom(atomHandle int, symbol string) error {
 // name: R-site name (e.g., "R", "R1", "R2", or list "R1 R3")
 // Returns the handle of the added R-site
 func (m *Molecule) AddRSite(name string) (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 
 	handle := int(C.indigoAddRSite(C.int(m.Handle), cName))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to add R-site %s: %s", name, getLastError())
+		return 0, session.NativeError(m.Handle, "add R-site %s", name)
 	}
 
 	return handle, nil
 }
 
 // SetRSite sets an atom as an R-site
-func SetRSite(atomHandle int, name string) error {
+func (m *Molecule) SetRSite(atomHandle int, name string) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 
 	ret := int(C.indigoSetRSite(C.int(atomHandle), cName))
 	if ret < 0 {
-		return fmt.Errorf("failed to set R-site: %s", getLastError())
+		return session.NativeError(atomHandle, "set R-site")
 	}
 
 	return nil
@@ -110,73 +124,104 @@ func SetRSite(atomHandle int, name string) error {
 // order: bond order (BOND_SINGLE, BOND_DOUBLE, BOND_TRIPLE, BOND_AROMATIC)
 // Returns the handle of the added bond
 func (m *Molecule) AddBond(source int, destination int, order int) (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoAddBond(C.int(source), C.int(destination), C.int(order)))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to add bond: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "add bond")
 	}
 
 	return handle, nil
 }
 
 // SetBondOrder sets the order of a bond
-func SetBondOrder(bondHandle int, order int) error {
+func (m *Molecule) SetBondOrder(bondHandle int, order int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetBondOrder(C.int(bondHandle), C.int(order)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set bond order: %s", getLastError())
+		return session.NativeError(bondHandle, "set bond order")
 	}
 
 	return nil
 }
 
 // SetCharge sets the charge of an atom
-func SetCharge(atomHandle int, charge int) error {
+func (m *Molecule) SetCharge(atomHandle int, charge int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetCharge(C.int(atomHandle), C.int(charge)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set charge: %s", getLastError())
+		return session.NativeError(atomHandle, "set charge")
 	}
 
 	return nil
 }
 
 // SetIsotope sets the isotope of an atom
-func SetIsotope(atomHandle int, isotope int) error {
+func (m *Molecule) SetIsotope(atomHandle int, isotope int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetIsotope(C.int(atomHandle), C.int(isotope)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set isotope: %s", getLastError())
+		return session.NativeError(atomHandle, "set isotope")
 	}
 
 	return nil
 }
 
 // SetRadical sets the radical of an atom
-func SetRadical(atomHandle int, radical int) error {
+func (m *Molecule) SetRadical(atomHandle int, radical int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetRadical(C.int(atomHandle), C.int(radical)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set radical: %s", getLastError())
+		return session.NativeError(atomHandle, "set radical")
 	}
 
 	return nil
 }
 
 // ResetRadical resets the radical of an atom
-func ResetRadical(atomHandle int) error {
+func (m *Molecule) ResetRadical(atomHandle int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoResetRadical(C.int(atomHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to reset radical: %s", getLastError())
+		return session.NativeError(atomHandle, "reset radical")
 	}
 
 	return nil
 }
 
 // SetImplicitHCount sets the implicit hydrogen count of an atom
-func SetImplicitHCount(atomHandle int, count int) error {
+func (m *Molecule) SetImplicitHCount(atomHandle int, count int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoSetImplicitHCount(C.int(atomHandle), C.int(count)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set implicit H count: %s", getLastError())
+		return session.NativeError(atomHandle, "set implicit H count")
 	}
 
 	return nil
@@ -184,39 +229,124 @@ func SetImplicitHCount(atomHandle int, count int) error {
 
 // Merge merges another molecule into this molecule
 func (m *Molecule) Merge(other *Molecule) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
+
 	if other.Closed {
-		return fmt.Errorf("other molecule is closed")
+		return indigoerr.Closed("other molecule")
+	}
+	if err := session.Same(m.session, other.session); err != nil {
+		return err
 	}
 
 	ret := int(C.indigoMerge(C.int(m.Handle), C.int(other.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to merge molecules: %s", getLastError())
+		return session.NativeError(m.Handle, "merge molecules")
 	}
 
 	return nil
 }
 
 // GetRadicalElectrons gets the number of radical electrons of an atom
-func GetRadicalElectrons(atomHandle int) (int, bool, error) {
+func (m *Molecule) GetRadicalElectrons(atomHandle int) (int, bool, error) {
+	if err := m.enter(); err != nil {
+		return 0, false, err
+	}
+	defer session.Exit()
+
 	var electrons C.int
 	ret := int(C.indigoGetRadicalElectrons(C.int(atomHandle), &electrons))
 	if ret < 0 {
-		return 0, false, fmt.Errorf("failed to get radical electrons: %s", getLastError())
+		return 0, false, session.NativeError(atomHandle, "get radical electrons")
 	}
 
 	return int(electrons), ret == 1, nil
 }
 
 // GetRadical gets the radical of an atom
-func GetRadical(atomHandle int) (int, bool, error) {
+func (m *Molecule) GetRadical(atomHandle int) (int, bool, error) {
+	if err := m.enter(); err != nil {
+		return 0, false, err
+	}
+	defer session.Exit()
+
 	var radical C.int
 	ret := int(C.indigoGetRadical(C.int(atomHandle), &radical))
 	if ret < 0 {
-		return 0, false, fmt.Errorf("failed to get radical: %s", getLastError())
+		return 0, false, session.NativeError(atomHandle, "get radical")
 	}
 
 	return int(radical), ret == 1, nil
 }
+
+// ResetAtom resets an atom to a new element
+//
+// Deprecated: use Molecule.ResetAtom, which runs in the session of the molecule.
+func ResetAtom(atomHandle int, symbol string) error {
+	return unbound().ResetAtom(atomHandle, symbol)
+}
+
+// SetRSite sets an atom as an R-site
+//
+// Deprecated: use Molecule.SetRSite, which runs in the session of the molecule.
+func SetRSite(atomHandle int, name string) error {
+	return unbound().SetRSite(atomHandle, name)
+}
+
+// SetBondOrder sets the order of a bond
+//
+// Deprecated: use Molecule.SetBondOrder, which runs in the session of the molecule.
+func SetBondOrder(bondHandle int, order int) error {
+	return unbound().SetBondOrder(bondHandle, order)
+}
+
+// SetCharge sets the charge of an atom
+//
+// Deprecated: use Molecule.SetCharge, which runs in the session of the molecule.
+func SetCharge(atomHandle int, charge int) error {
+	return unbound().SetCharge(atomHandle, charge)
+}
+
+// SetIsotope sets the isotope of an atom
+//
+// Deprecated: use Molecule.SetIsotope, which runs in the session of the molecule.
+func SetIsotope(atomHandle int, isotope int) error {
+	return unbound().SetIsotope(atomHandle, isotope)
+}
+
+// SetRadical sets the radical of an atom
+//
+// Deprecated: use Molecule.SetRadical, which runs in the session of the molecule.
+func SetRadical(atomHandle int, radical int) error {
+	return unbound().SetRadical(atomHandle, radical)
+}
+
+// ResetRadical resets the radical of an atom
+//
+// Deprecated: use Molecule.ResetRadical, which runs in the session of the molecule.
+func ResetRadical(atomHandle int) error {
+	return unbound().ResetRadical(atomHandle)
+}
+
+// SetImplicitHCount sets the implicit hydrogen count of an atom
+//
+// Deprecated: use Molecule.SetImplicitHCount, which runs in the session of the molecule.
+func SetImplicitHCount(atomHandle int, count int) error {
+	return unbound().SetImplicitHCount(atomHandle, count)
+}
+
+// GetRadicalElectrons gets the number of radical electrons of an atom
+//
+// Deprecated: use Molecule.GetRadicalElectrons, which runs in the session of the molecule.
+func GetRadicalElectrons(atomHandle int) (int, bool, error) {
+	return unbound().GetRadicalElectrons(atomHandle)
+}
+
+// GetRadical gets the radical of an atom
+//
+// Deprecated: use Molecule.GetRadical, which runs in the session of the molecule.
+func GetRadical(atomHandle int) (int, bool, error) {
+	return unbound().GetRadical(atomHandle)
+}
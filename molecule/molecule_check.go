@@ -0,0 +1,326 @@
+// Package molecule provides structure checking functionality using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_check.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"encoding/json"
+	"strings"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// CheckType names a structure check understood by indigoCheck
+type CheckType string
+
+// Structure check types
+const (
+	CHECK_LOAD              CheckType = "load"
+	CHECK_VALENCE           CheckType = "valence"
+	CHECK_RADICALS          CheckType = "radicals"
+	CHECK_PSEUDOATOMS       CheckType = "pseudoatoms"
+	CHECK_STEREO            CheckType = "stereo"
+	CHECK_QUERY             CheckType = "query"
+	CHECK_OVERLAPPING_ATOMS CheckType = "overlapping_atoms"
+	CHECK_OVERLAPPING_BONDS CheckType = "overlapping_bonds"
+	CHECK_RGROUPS           CheckType = "rgroups"
+	CHECK_CHIRALITY         CheckType = "chirality"
+	CHECK_3D_COORD          CheckType = "3d"
+	CHECK_CHARGE            CheckType = "charge"
+	CHECK_SGROUPS           CheckType = "sgroups"
+	CHECK_V3000             CheckType = "v3000"
+	CHECK_AMBIGUOUS_H       CheckType = "ambiguous_h"
+	CHECK_COORD             CheckType = "coord"
+)
+
+// CheckIssue is a single problem reported by a structure check
+type CheckIssue struct {
+	Check   CheckType
+	Message string
+	Atoms   []int // indices of offending atoms, if reported
+	Bonds   []int // indices of offending bonds, if reported
+	Index   int   // index of the checked sub-object, -1 for the object itself
+}
+
+// CheckReport is the decoded result of a structure check
+type CheckReport struct {
+	Issues []CheckIssue
+	Raw    string // JSON returned by Indigo
+}
+
+// OK returns true if no issues were reported
+func (r *CheckReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Messages returns the messages of all issues
+func (r *CheckReport) Messages() []string {
+	messages := make([]string, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		messages = append(messages, issue.Message)
+	}
+	return messages
+}
+
+// ByCheck returns the issues reported by the given check
+func (r *CheckReport) ByCheck(check CheckType) []CheckIssue {
+	var issues []CheckIssue
+	for _, issue := range r.Issues {
+		if issue.Check == check {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// CheckFlags joins check types into the flag string expected by Indigo
+// An empty list means all checks
+func CheckFlags(checks ...CheckType) string {
+	flags := make([]string, 0, len(checks))
+	for _, c := range checks {
+		flags = append(flags, string(c))
+	}
+	return strings.Join(flags, ";")
+}
+
+// Check runs the given structure checks, or all checks if none are given
+func (m *Molecule) Check(checks ...CheckType) (*CheckReport, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cFlags := C.CString(CheckFlags(checks...))
+	defer C.free(unsafe.Pointer(cFlags))
+
+	cStr := C.indigoCheckObj(C.int(m.Handle), cFlags)
+	if cStr == nil {
+		return nil, session.NativeError(m.Handle, "check molecule")
+	}
+
+	return ParseCheckReport(C.GoString(cStr))
+}
+
+// CheckData loads a structure from a string and runs the given checks on it
+// Loading problems are reported as CHECK_LOAD issues instead of errors
+// loadParams: loader options, may be empty
+func CheckData(data string, loadParams string, checks ...CheckType) (*CheckReport, error) {
+	// Not bound to an object, so the check runs in the default session
+	if err := session.EnterDefault(); err != nil {
+		return nil, err
+	}
+	defer session.ExitDefault()
+
+	cData := C.CString(data)
+	defer C.free(unsafe.Pointer(cData))
+
+	cFlags := C.CString(CheckFlags(checks...))
+	defer C.free(unsafe.Pointer(cFlags))
+
+	cParams := C.CString(loadParams)
+	defer C.free(unsafe.Pointer(cParams))
+
+	cStr := C.indigoCheck(cData, cFlags, cParams)
+	if cStr == nil {
+		return nil, session.NativeError(0, "check structure")
+	}
+
+	return ParseCheckReport(C.GoString(cStr))
+}
+
+// CheckStructure runs the given checks on a structure given as a string
+func CheckStructure(structure string, checks ...CheckType) (*CheckReport, error) {
+	// Not bound to an object, so the check runs in the default session
+	if err := session.EnterDefault(); err != nil {
+		return nil, err
+	}
+	defer session.ExitDefault()
+
+	cStructure := C.CString(structure)
+	defer C.free(unsafe.Pointer(cStructure))
+
+	cFlags := C.CString(CheckFlags(checks...))
+	defer C.free(unsafe.Pointer(cFlags))
+
+	cStr := C.indigoCheckStructure(cStructure, cFlags)
+	if cStr == nil {
+		return nil, session.NativeError(0, "check structure")
+	}
+
+	return ParseCheckReport(C.GoString(cStr))
+}
+
+// CheckBadValence returns a description of the first valence problem, or "" if there is none
+func (m *Molecule) CheckBadValence() (string, error) {
+	if err := m.enter(); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
+	cStr := C.indigoCheckBadValence(C.int(m.Handle))
+	if cStr == nil {
+		return "", session.NativeError(m.Handle, "check valence")
+	}
+
+	return C.GoString(cStr), nil
+}
+
+// CheckAmbiguousH returns a description of the first ambiguous hydrogen count, or "" if there is none
+func (m *Molecule) CheckAmbiguousH() (string, error) {
+	if err := m.enter(); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
+	cStr := C.indigoCheckAmbiguousH(C.int(m.Handle))
+	if cStr == nil {
+		return "", session.NativeError(m.Handle, "check ambiguous H")
+	}
+
+	return C.GoString(cStr), nil
+}
+
+// CheckChirality returns true if the chirality check reports a problem
+func (m *Molecule) CheckChirality() (bool, error) {
+	return m.checkFlag("chirality", func(h C.int) C.int { return C.indigoCheckChirality(h) })
+}
+
+// Check3DStereo returns true if the 3D stereo check reports a problem
+func (m *Molecule) Check3DStereo() (bool, error) {
+	return m.checkFlag("3D stereo", func(h C.int) C.int { return C.indigoCheck3DStereo(h) })
+}
+
+// CheckStereo returns true if the stereo check reports a problem
+func (m *Molecule) CheckStereo() (bool, error) {
+	return m.checkFlag("stereo", func(h C.int) C.int { return C.indigoCheckStereo(h) })
+}
+
+// CheckRGroups returns true if the molecule contains R-sites, R-groups or attachment points
+func (m *Molecule) CheckRGroups() (bool, error) {
+	return m.checkFlag("R-groups", func(h C.int) C.int { return C.indigoCheckRGroups(h) })
+}
+
+// CheckQuery returns true if the molecule is a query or has query features
+func (m *Molecule) CheckQuery() (bool, error) {
+	return m.checkFlag("query", func(h C.int) C.int { return C.indigoCheckQuery(h) })
+}
+
+// CheckValence returns false if the valence of the atom is wrong
+func (a *Atom) CheckValence() (bool, error) {
+	if err := session.Enter(a.session); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoCheckValence(C.int(a.Handle)))
+	if ret < 0 {
+		return false, session.NativeError(a.Handle, "check valence")
+	}
+	return ret > 0, nil
+}
+
+// checkFlag runs a native check returning 1 or 0
+func (m *Molecule) checkFlag(name string, check func(C.int) C.int) (bool, error) {
+	if err := m.enter(); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	ret := int(check(C.int(m.Handle)))
+	if ret < 0 {
+		return false, session.NativeError(m.Handle, "run %s check", name)
+	}
+
+	return ret > 0, nil
+}
+
+// checkResult is the JSON document written by indigoCheckObj and friends
+type checkResult struct {
+	Checks []checkMessage `json:"checks"`
+}
+
+// checkMessage is one entry of checkResult, sub-objects of a reaction report their own entries
+type checkMessage struct {
+	ID        string         `json:"id"`
+	Message   string         `json:"message"`
+	Index     *int           `json:"index"`
+	IDs       []int          `json:"ids"`
+	Subresult []checkMessage `json:"subresult"`
+}
+
+// ParseCheckReport decodes the JSON returned by the Indigo check functions
+func ParseCheckReport(data string) (*CheckReport, error) {
+	report := &CheckReport{Raw: data}
+	if strings.TrimSpace(data) == "" {
+		return report, nil
+	}
+
+	// Unknown fields are ignored so that newer Indigo versions can extend the report
+	var result checkResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, &indigoerr.IndigoError{Op: "decode check result", Message: err.Error(), Category: indigoerr.CATEGORY_PARSE, Err: err}
+	}
+
+	for _, msg := range result.Checks {
+		if err := appendCheckIssues(report, msg, -1); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// appendCheckIssues adds a check message and its subresult to the report
+// An entry with a subresult only wraps the issues of a reaction component and is not an issue itself
+func appendCheckIssues(report *CheckReport, msg checkMessage, index int) error {
+	if msg.ID == "" {
+		return indigoerr.New(indigoerr.CATEGORY_PARSE, "decode check result: entry without id: %q", msg.Message)
+	}
+	if msg.Index != nil {
+		index = *msg.Index
+	}
+
+	if len(msg.Subresult) > 0 {
+		for _, sub := range msg.Subresult {
+			if err := appendCheckIssues(report, sub, index); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	issue := CheckIssue{Check: CheckType(msg.ID), Message: msg.Message, Index: index}
+	// ids are bond indices for the bond checks and atom indices otherwise
+	if issue.Check == CHECK_OVERLAPPING_BONDS {
+		issue.Bonds = msg.IDs
+	} else {
+		issue.Atoms = msg.IDs
+	}
+	report.Issues = append(report.Issues, issue)
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package molecule provides molecular descriptors and drug-likeness rules
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_descriptors.go
+// @Software: GoLand
+package molecule
+
+import (
+	"fmt"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Descriptors holds the physicochemical descriptors of a molecule
+type Descriptors struct {
+	MolecularWeight   float64
+	LogP              float64
+	MolarRefractivity float64
+	TPSA              float64
+	HBondAcceptors    int
+	HBondDonors       int
+	RotatableBonds    int
+	HeavyAtoms        int
+	Hydrogens         int
+	Rings             int
+}
+
+// RuleResult is the outcome of a drug-likeness rule set
+type RuleResult struct {
+	Rule       string
+	Passed     bool
+	Violations []string // one message per violated criterion
+}
+
+// Descriptors computes all descriptors of the molecule in one pass
+func (m *Molecule) Descriptors() (*Descriptors, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	d := &Descriptors{}
+	var err error
+
+	if d.MolecularWeight, err = m.MolecularWeight(); err != nil {
+		return nil, err
+	}
+	if d.LogP, err = m.LogP(); err != nil {
+		return nil, err
+	}
+	if d.MolarRefractivity, err = m.MolarRefractivity(); err != nil {
+		return nil, err
+	}
+	if d.TPSA, err = m.TPSA(false); err != nil {
+		return nil, err
+	}
+	if d.HBondAcceptors, err = m.NumHydrogenBondAcceptors(); err != nil {
+		return nil, err
+	}
+	if d.HBondDonors, err = m.NumHydrogenBondDonors(); err != nil {
+		return nil, err
+	}
+	if d.RotatableBonds, err = m.NumRotatableBonds(); err != nil {
+		return nil, err
+	}
+	if d.HeavyAtoms, err = m.CountHeavyAtoms(); err != nil {
+		return nil, err
+	}
+	if d.Hydrogens, err = m.CountHydrogens(); err != nil {
+		return nil, err
+	}
+	if d.Rings, err = m.CountSSSR(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// TotalAtoms returns the number of atoms including hydrogens
+func (d *Descriptors) TotalAtoms() int {
+	return d.HeavyAtoms + d.Hydrogens
+}
+
+// Lipinski evaluates the rule of five: MW <= 500, logP <= 5, HBD <= 5, HBA <= 10
+// The rule passes with at most one violation
+func (d *Descriptors) Lipinski() RuleResult {
+	r := RuleResult{Rule: "Lipinski"}
+	r.atMost("molecular weight", d.MolecularWeight, 500)
+	r.atMost("logP", d.LogP, 5)
+	r.atMost("H-bond donors", float64(d.HBondDonors), 5)
+	r.atMost("H-bond acceptors", float64(d.HBondAcceptors), 10)
+	r.Passed = len(r.Violations) <= 1
+	return r
+}
+
+// Veber evaluates oral bioavailability: rotatable bonds <= 10, TPSA <= 140
+func (d *Descriptors) Veber() RuleResult {
+	r := RuleResult{Rule: "Veber"}
+	r.atMost("rotatable bonds", float64(d.RotatableBonds), 10)
+	r.atMost("TPSA", d.TPSA, 140)
+	r.Passed = len(r.Violations) == 0
+	return r
+}
+
+// Ghose evaluates the Ghose filter: MW 160-480, logP -0.4-5.6, MR 40-130, 20-70 atoms
+func (d *Descriptors) Ghose() RuleResult {
+	r := RuleResult{Rule: "Ghose"}
+	r.between("molecular weight", d.MolecularWeight, 160, 480)
+	r.between("logP", d.LogP, -0.4, 5.6)
+	r.between("molar refractivity", d.MolarRefractivity, 40, 130)
+	r.between("atom count", float64(d.TotalAtoms()), 20, 70)
+	r.Passed = len(r.Violations) == 0
+	return r
+}
+
+// LeadLike evaluates lead-likeness: MW 250-350, logP <= 3.5, rotatable bonds <= 7
+func (d *Descriptors) LeadLike() RuleResult {
+	r := RuleResult{Rule: "LeadLike"}
+	r.between("molecular weight", d.MolecularWeight, 250, 350)
+	r.atMost("logP", d.LogP, 3.5)
+	r.atMost("rotatable bonds", float64(d.RotatableBonds), 7)
+	r.Passed = len(r.Violations) == 0
+	return r
+}
+
+// atMost records a violation if value exceeds max
+func (r *RuleResult) atMost(name string, value float64, max float64) {
+	if value > max {
+		r.Violations = append(r.Violations, fmt.Sprintf("%s %g > %g", name, value, max))
+	}
+}
+
+// between records a violation if value is outside [min, max]
+func (r *RuleResult) between(name string, value float64, min float64, max float64) {
+	if value < min || value > max {
+		r.Violations = append(r.Violations, fmt.Sprintf("%s %g not in [%g, %g]", name, value, min, max))
+	}
+}
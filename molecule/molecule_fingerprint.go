@@ -0,0 +1,253 @@
+// Package molecule provides fingerprint functionality using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_fingerprint.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Fingerprint type constants
+const (
+	FINGERPRINT_SIM     = "sim"     // similarity fingerprint (default)
+	FINGERPRINT_SUB     = "sub"     // substructure screening fingerprint
+	FINGERPRINT_SUB_RES = "sub-res" // resonance substructure screening fingerprint
+	FINGERPRINT_SUB_TAU = "sub-tau" // tautomer substructure screening fingerprint
+	FINGERPRINT_FULL    = "full"    // all of the above
+)
+
+// Fingerprint holds the raw bits of an Indigo fingerprint.
+// It does not keep a native handle alive, so it can be stored and compared freely.
+type Fingerprint struct {
+	Type string
+	Data []byte
+}
+
+// NewFingerprint creates a fingerprint from raw bytes, e.g. previously saved with Bytes()
+func NewFingerprint(kind string, data []byte) *Fingerprint {
+	buf := make([]byte, len(data))
+	copy(buf, data)
+	return &Fingerprint{Type: kind, Data: buf}
+}
+
+// Fingerprint calculates a fingerprint of the molecule
+// kind: FINGERPRINT_SIM, FINGERPRINT_SUB, FINGERPRINT_SUB_RES, FINGERPRINT_SUB_TAU or FINGERPRINT_FULL
+func (m *Molecule) Fingerprint(kind string) (*Fingerprint, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	if kind == "" {
+		kind = FINGERPRINT_SIM
+	}
+
+	cKind := C.CString(kind)
+	defer C.free(unsafe.Pointer(cKind))
+
+	fpHandle := int(C.indigoFingerprint(C.int(m.Handle), cKind))
+	if fpHandle < 0 {
+		return nil, session.NativeError(m.Handle, "calculate %s fingerprint", kind)
+	}
+	defer C.indigoFree(C.int(fpHandle))
+
+	var size C.int
+	var dataPtr *C.char
+	ret := int(C.indigoToBuffer(C.int(fpHandle), &dataPtr, &size))
+	if ret < 0 || dataPtr == nil {
+		return nil, session.NativeError(m.Handle, "get fingerprint data")
+	}
+
+	return &Fingerprint{Type: kind, Data: C.GoBytes(unsafe.Pointer(dataPtr), size)}, nil
+}
+
+// Bytes returns a copy of the raw fingerprint bytes
+func (fp *Fingerprint) Bytes() []byte {
+	buf := make([]byte, len(fp.Data))
+	copy(buf, fp.Data)
+	return buf
+}
+
+// Size returns the fingerprint length in bits
+func (fp *Fingerprint) Size() int {
+	return len(fp.Data) * 8
+}
+
+// CountBits returns the number of bits set to one
+func (fp *Fingerprint) CountBits() (int, error) {
+	if err := fp.check(); err != nil {
+		return 0, err
+	}
+	return countOnes(fp.Data), nil
+}
+
+// OnBits returns the indices of the bits set to one in ascending order
+// Bit i is bit i%8 of byte i/8, counted from the least significant bit as Indigo does
+func (fp *Fingerprint) OnBits() ([]int, error) {
+	if err := fp.check(); err != nil {
+		return nil, err
+	}
+
+	onBits := make([]int, 0, countOnes(fp.Data))
+	for i, b := range fp.Data {
+		for ; b != 0; b &= b - 1 {
+			onBits = append(onBits, i*8+bits.TrailingZeros8(b))
+		}
+	}
+	return onBits, nil
+}
+
+// CommonBits returns the number of one bits shared with another fingerprint
+func (fp *Fingerprint) CommonBits(other *Fingerprint) (int, error) {
+	if err := fp.checkPair(other); err != nil {
+		return 0, err
+	}
+	return countCommon(fp.Data, other.Data), nil
+}
+
+// Tanimoto returns the Tanimoto similarity with another fingerprint
+func (fp *Fingerprint) Tanimoto(other *Fingerprint) (float64, error) {
+	return fp.Similarity(other, "tanimoto")
+}
+
+// Tversky returns the Tversky similarity with another fingerprint
+// alpha weights the bits unique to this fingerprint, beta the bits unique to other
+func (fp *Fingerprint) Tversky(other *Fingerprint, alpha float64, beta float64) (float64, error) {
+	return fp.Similarity(other, fmt.Sprintf("tversky %g %g", alpha, beta))
+}
+
+// Euclid returns the "euclid-sub" similarity with another fingerprint
+func (fp *Fingerprint) Euclid(other *Fingerprint) (float64, error) {
+	return fp.Similarity(other, "euclid-sub")
+}
+
+// Similarity returns the similarity with another fingerprint using the given metrics
+// metrics: "tanimoto" (default), "tversky", "tversky <alpha> <beta>" or "euclid-sub"
+// The bits are compared in Go with the formulas of indigoSimilarity, no native call is made
+func (fp *Fingerprint) Similarity(other *Fingerprint, metrics string) (float64, error) {
+	if err := fp.checkPair(other); err != nil {
+		return 0, err
+	}
+
+	ones1 := float64(countOnes(fp.Data))
+	ones2 := float64(countOnes(other.Data))
+	common := float64(countCommon(fp.Data, other.Data))
+
+	fields := strings.Fields(strings.ToLower(metrics))
+	if len(fields) == 0 {
+		fields = []string{"tanimoto"}
+	}
+
+	switch {
+	case fields[0] == "tanimoto" && len(fields) == 1:
+		if common == 0 {
+			return 0, nil
+		}
+		return common / (ones1 + ones2 - common), nil
+
+	case fields[0] == "euclid-sub" && len(fields) == 1:
+		if common == 0 {
+			return 0, nil
+		}
+		return common / ones1, nil
+
+	case fields[0] == "tversky" && (len(fields) == 1 || len(fields) == 3):
+		alpha, beta := 0.5, 0.5
+		if len(fields) == 3 {
+			var err1, err2 error
+			alpha, err1 = strconv.ParseFloat(fields[1], 64)
+			beta, err2 = strconv.ParseFloat(fields[2], 64)
+			if err1 != nil || err2 != nil {
+				return 0, indigoerr.New(indigoerr.CATEGORY_OPTION, "invalid tversky parameters in metrics %q", metrics)
+			}
+		}
+		if ones1+ones2 == 0 {
+			return 0, nil
+		}
+
+		denom := (ones1-common)*alpha + (ones2-common)*beta + common
+		if denom < 1e-6 {
+			return 0, indigoerr.New(indigoerr.CATEGORY_OPTION, "bad tversky denominator for metrics %q", metrics)
+		}
+		return common / denom, nil
+	}
+
+	return 0, indigoerr.New(indigoerr.CATEGORY_OPTION, "unknown similarity metrics %q", metrics)
+}
+
+// check validates a fingerprint before its bits are read
+func (fp *Fingerprint) check() error {
+	if fp == nil || len(fp.Data) == 0 {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "fingerprint is empty")
+	}
+	return nil
+}
+
+// checkPair validates two fingerprints before their bits are compared
+func (fp *Fingerprint) checkPair(other *Fingerprint) error {
+	if other == nil {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "other fingerprint is nil")
+	}
+	if err := fp.check(); err != nil {
+		return err
+	}
+	if err := other.check(); err != nil {
+		return err
+	}
+	if fp.Type != "" && other.Type != "" && fp.Type != other.Type {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "fingerprint types differ: %s and %s", fp.Type, other.Type)
+	}
+	if len(fp.Data) != len(other.Data) {
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "fingerprint sizes differ: %d and %d bytes", len(fp.Data), len(other.Data))
+	}
+	return nil
+}
+
+// countOnes returns the number of one bits in data
+func countOnes(data []byte) int {
+	count := 0
+	for _, b := range data {
+		count += bits.OnesCount8(b)
+	}
+	return count
+}
+
+// countCommon returns the number of one bits shared by two byte slices of the same length
+func countCommon(a []byte, b []byte) int {
+	count := 0
+	for i := range a {
+		count += bits.OnesCount8(a[i] & b[i])
+	}
+	return count
+}
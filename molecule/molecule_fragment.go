@@ -0,0 +1,387 @@
+// Package molecule provides fragment selection and salt stripping using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_fragment.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Criteria for choosing the largest fragment
+const (
+	FRAGMENT_HEAVY_ATOMS      = iota // most heavy atoms, ties broken by molecular weight
+	FRAGMENT_MOLECULAR_WEIGHT        // highest molecular weight, ties broken by heavy atoms
+)
+
+// DEFAULT_SALTS lists common counter-ions and solvents removed by DefaultSaltStripper
+var DEFAULT_SALTS = []string{
+	// Halides and hydrohalic acids
+	"[F-]", "[Cl-]", "[Br-]", "[I-]", "F", "Cl", "Br", "I",
+	// Metal and ammonium cations
+	"[Li+]", "[Na+]", "[K+]", "[Mg+2]", "[Ca+2]", "[Zn+2]", "[NH4+]", "N",
+	// Solvents
+	"O", "CO", "CC(C)=O", "ClCCl", "ClC(Cl)Cl",
+	// Inorganic acids and anions
+	"OS(=O)(=O)O", "OS([O-])(=O)=O", "[O-]S([O-])(=O)=O",
+	"OP(O)(O)=O", "OP([O-])(O)=O", "[O-]P([O-])([O-])=O",
+	"O[N+]([O-])=O", "[O-][N+]([O-])=O",
+	// Organic acids and their anions
+	"OC=O", "[O-]C=O", "CC(O)=O", "CC([O-])=O",
+	"OC(=O)C(F)(F)F", "[O-]C(=O)C(F)(F)F",
+	"OC(=O)C(O)=O", "OC(=O)/C=C/C(O)=O", "OC(=O)/C=C\\C(O)=O",
+	"OC(=O)CC(O)(CC(O)=O)C(O)=O", "OC(C(O)C(O)=O)C(O)=O",
+	"CS(O)(=O)=O", "CS([O-])(=O)=O",
+	"Cc1ccc(cc1)S(O)(=O)=O", "Cc1ccc(cc1)S([O-])(=O)=O",
+	"OS(=O)(=O)c1ccccc1", "[O-]S(=O)(=O)c1ccccc1",
+}
+
+// SaltStripper removes known salt and solvent components from molecules
+// Components are matched by canonical SMILES, so entries must match exactly, including charges
+type SaltStripper struct {
+	salts map[string]string // canonical SMILES -> entry as given
+}
+
+// StripResult is the outcome of stripping a molecule
+type StripResult struct {
+	Parent  *Molecule // the molecule without the removed components; owned by the caller
+	Removed []string  // dictionary entries of the removed components, in component order
+}
+
+// Composition is one molecule enumerated by RGroupComposition; both molecules are owned by the caller
+type Composition struct {
+	Molecule   *Molecule // the molecule with its R-sites replaced by R-group fragments
+	Fragmented *Molecule // the same molecule as returned by indigoGetFragmentedMolecule
+}
+
+// Close frees both molecules of the composition
+func (c *Composition) Close() {
+	if c.Molecule != nil {
+		c.Molecule.Close()
+	}
+	if c.Fragmented != nil {
+		c.Fragmented.Close()
+	}
+}
+
+// RGroupComposition enumerates the molecules built by attaching the R-group fragments of m to its R-sites
+// options is passed to indigoRGroupComposition and fragmentOptions to indigoGetFragmentedMolecule; both may be empty
+func (m *Molecule) RGroupComposition(options string, fragmentOptions string) ([]Composition, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+	cFragmentOptions := C.CString(fragmentOptions)
+	defer C.free(unsafe.Pointer(cFragmentOptions))
+
+	iterHandle := int(C.indigoRGroupComposition(C.int(m.Handle), cOptions))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "compose R-groups")
+	}
+
+	var compositions []Composition
+	err := iterateHandles(iterHandle, func(item int) error {
+		composed, err := cloneItem(item, "composed molecule", m.session)
+		if err != nil {
+			return err
+		}
+
+		fragmented, err := cloneResult(int(C.indigoGetFragmentedMolecule(C.int(item), cFragmentOptions)), "fragmented molecule", m.session)
+		if err != nil {
+			composed.Close()
+			return err
+		}
+
+		compositions = append(compositions, Composition{Molecule: composed, Fragmented: fragmented})
+		return nil
+	})
+	if err != nil {
+		for i := range compositions {
+			compositions[i].Close()
+		}
+		return nil, err
+	}
+
+	return compositions, nil
+}
+
+// Component returns a connected component as a new molecule
+func (m *Molecule) Component(index int) (*Molecule, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	component := int(C.indigoComponent(C.int(m.Handle), C.int(index)))
+	if component < 0 {
+		return nil, session.NativeError(m.Handle, "get component %d", index)
+	}
+	defer C.indigoFree(C.int(component))
+
+	handle := int(C.indigoClone(C.int(component)))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "clone component %d", index)
+	}
+
+	return newMolecule(handle, m.session), nil
+}
+
+// ComponentList returns every connected component as a new molecule
+func (m *Molecule) ComponentList() ([]*Molecule, error) {
+	count, err := m.CountComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]*Molecule, 0, count)
+	for i := 0; i < count; i++ {
+		component, err := m.Component(i)
+		if err != nil {
+			closeMolecules(components)
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	return components, nil
+}
+
+// LargestFragment returns a copy of the largest connected component
+// criteria is FRAGMENT_HEAVY_ATOMS or FRAGMENT_MOLECULAR_WEIGHT; remaining ties keep the first component
+func (m *Molecule) LargestFragment(criteria int) (*Molecule, error) {
+	if criteria != FRAGMENT_HEAVY_ATOMS && criteria != FRAGMENT_MOLECULAR_WEIGHT {
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "unknown fragment criteria %d", criteria)
+	}
+
+	components, err := m.ComponentList()
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "molecule has no components")
+	}
+
+	best := -1
+	var bestAtoms int
+	var bestWeight float64
+	for i, component := range components {
+		atoms, err := component.CountHeavyAtoms()
+		if err != nil {
+			closeMolecules(components)
+			return nil, err
+		}
+		weight, err := component.MolecularWeight()
+		if err != nil {
+			closeMolecules(components)
+			return nil, err
+		}
+
+		if best < 0 || largerFragment(criteria, atoms, weight, bestAtoms, bestWeight) {
+			best, bestAtoms, bestWeight = i, atoms, weight
+		}
+	}
+
+	for i, component := range components {
+		if i != best {
+			component.Close()
+		}
+	}
+	return components[best], nil
+}
+
+// NewSaltStripper creates a salt stripper from a list of SMILES
+// An entry with several components, such as "[Na+].[Cl-]", adds each of them
+func NewSaltStripper(smiles ...string) (*SaltStripper, error) {
+	s := &SaltStripper{salts: make(map[string]string)}
+	if err := s.Add(smiles...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DefaultSaltStripper creates a salt stripper with the DEFAULT_SALTS dictionary
+func DefaultSaltStripper() (*SaltStripper, error) {
+	return NewSaltStripper(DEFAULT_SALTS...)
+}
+
+// Add adds SMILES entries to the dictionary
+func (s *SaltStripper) Add(smiles ...string) error {
+	for _, entry := range smiles {
+		keys, err := saltKeys(entry)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, ok := s.salts[key]; !ok {
+				s.salts[key] = entry
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of distinct components in the dictionary
+func (s *SaltStripper) Len() int {
+	return len(s.salts)
+}
+
+// Strip returns a copy of the molecule without the components found in the dictionary
+// If every component is a known salt, nothing is removed so that a parent structure always remains
+func (s *SaltStripper) Strip(m *Molecule) (*StripResult, error) {
+	components, err := m.ComponentList()
+	if err != nil {
+		return nil, err
+	}
+	defer closeMolecules(components)
+
+	var removed []string
+	var removedComponents []int
+	for i, component := range components {
+		key, err := component.ToCanonicalSmiles()
+		if err != nil {
+			return nil, err
+		}
+		if entry, ok := s.salts[key]; ok {
+			removed = append(removed, entry)
+			removedComponents = append(removedComponents, i)
+		}
+	}
+
+	parent, err := m.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if len(removedComponents) == 0 || len(removedComponents) == len(components) {
+		return &StripResult{Parent: parent}, nil
+	}
+
+	atoms, err := componentAtoms(m, removedComponents)
+	if err != nil {
+		parent.Close()
+		return nil, err
+	}
+	if err := parent.RemoveAtoms(atoms); err != nil {
+		parent.Close()
+		return nil, err
+	}
+
+	return &StripResult{Parent: parent, Removed: removed}, nil
+}
+
+// largerFragment compares two fragments by the criteria, then by the other measure
+func largerFragment(criteria int, atoms int, weight float64, bestAtoms int, bestWeight float64) bool {
+	if criteria == FRAGMENT_MOLECULAR_WEIGHT {
+		if weight != bestWeight {
+			return weight > bestWeight
+		}
+		return atoms > bestAtoms
+	}
+
+	if atoms != bestAtoms {
+		return atoms > bestAtoms
+	}
+	return weight > bestWeight
+}
+
+// componentAtoms returns the atom indices of the given components
+func componentAtoms(m *Molecule, components []int) ([]int, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	var atoms []int
+	for _, index := range components {
+		component := int(C.indigoComponent(C.int(m.Handle), C.int(index)))
+		if component < 0 {
+			return nil, session.NativeError(0, "get component %d", index)
+		}
+
+		iterHandle := int(C.indigoIterateAtoms(C.int(component)))
+		if iterHandle < 0 {
+			C.indigoFree(C.int(component))
+			return nil, session.NativeError(0, "iterate component atoms")
+		}
+
+		err := iterateHandles(iterHandle, func(atom int) error {
+			atoms = append(atoms, int(C.indigoIndex(C.int(atom))))
+			return nil
+		})
+		C.indigoFree(C.int(component))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return atoms, nil
+}
+
+// saltKeys returns the canonical SMILES of every component of a dictionary entry
+func saltKeys(smiles string) ([]string, error) {
+	cSmiles := C.CString(smiles)
+	defer C.free(unsafe.Pointer(cSmiles))
+
+	// Dictionary entries are only used through their SMILES, so they are parsed in the default session
+	if err := session.EnterDefault(); err != nil {
+		return nil, err
+	}
+	defer session.ExitDefault()
+
+	handle := int(C.indigoLoadMoleculeFromString(cSmiles))
+	if handle < 0 {
+		return nil, session.NativeError(0, "load salt %s", smiles)
+	}
+	entry := newMolecule(handle, nil)
+	defer entry.Close()
+
+	components, err := entry.ComponentList()
+	if err != nil {
+		return nil, err
+	}
+	defer closeMolecules(components)
+
+	keys := make([]string, 0, len(components))
+	for _, component := range components {
+		key, err := component.ToCanonicalSmiles()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// closeMolecules closes every molecule of the slice
+func closeMolecules(mols []*Molecule) {
+	for _, mol := range mols {
+		mol.Close()
+	}
+}
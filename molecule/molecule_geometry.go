@@ -0,0 +1,280 @@
+// Package molecule provides atom coordinates and geometry utilities using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_geometry.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"math"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Point is a position in 2D or 3D space; Z is 0 for 2D coordinates
+type Point struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// XYZ returns the coordinates of an atom
+func (a *Atom) XYZ() (Point, error) {
+	if err := session.Enter(a.session); err != nil {
+		return Point{}, err
+	}
+	defer session.Exit()
+
+	xyz := C.indigoXYZ(C.int(a.Handle))
+	if xyz == nil {
+		return Point{}, session.NativeError(a.Handle, "get atom coordinates")
+	}
+
+	// indigoXYZ returns a pointer to a static 3-element array, copy it right away
+	coords := (*[3]C.float)(unsafe.Pointer(xyz))
+	return Point{X: float64(coords[0]), Y: float64(coords[1]), Z: float64(coords[2])}, nil
+}
+
+// SetXYZ sets the coordinates of an atom
+func (a *Atom) SetXYZ(x float64, y float64, z float64) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoSetXYZ(C.int(a.Handle), C.float(x), C.float(y), C.float(z)))
+	if ret < 0 {
+		return session.NativeError(a.Handle, "set atom coordinates")
+	}
+	return nil
+}
+
+// HasCoordinates checks if the molecule has atom coordinates
+func (m *Molecule) HasCoordinates() (bool, error) {
+	if err := m.enter(); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoHasCoord(C.int(m.Handle)))
+	if ret < 0 {
+		return false, session.NativeError(m.Handle, "check coordinates")
+	}
+
+	return ret > 0, nil
+}
+
+// Is3D checks if the molecule has non-zero Z coordinates
+func (m *Molecule) Is3D() (bool, error) {
+	if err := m.enter(); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoHasZCoord(C.int(m.Handle)))
+	if ret < 0 {
+		return false, session.NativeError(m.Handle, "check Z coordinates")
+	}
+
+	return ret > 0, nil
+}
+
+// ClearCoordinates resets the coordinates of all atoms
+func (m *Molecule) ClearCoordinates() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoClearXYZ(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "clear coordinates")
+	}
+
+	return nil
+}
+
+// Coordinates returns the coordinates of all atoms, indexed by atom index
+// Removing atoms can leave gaps in the indices; the entries of removed atoms are zero
+func (m *Molecule) Coordinates() ([]Point, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateAtoms(C.int(m.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate atoms")
+	}
+
+	var points []Point
+	err := iterateHandles(iterHandle, func(item int) error {
+		index := int(C.indigoIndex(C.int(item)))
+		if index < 0 {
+			return session.NativeError(m.Handle, "get atom index")
+		}
+
+		p, err := (&Atom{Handle: item, session: m.session}).XYZ()
+		if err != nil {
+			return err
+		}
+		for len(points) <= index {
+			points = append(points, Point{})
+		}
+		points[index] = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// AlignAtoms moves and rotates the molecule so that the given atoms come as close
+// as possible to the desired positions, and returns the root-mean-square deviation
+func (m *Molecule) AlignAtoms(atomIndices []int, desired []Point) (float64, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	if len(atomIndices) == 0 {
+		return 0, indigoerr.New(indigoerr.CATEGORY_INVALID, "no atoms to align")
+	}
+	if len(atomIndices) != len(desired) {
+		return 0, indigoerr.New(indigoerr.CATEGORY_INVALID, "got %d atom indices but %d positions", len(atomIndices), len(desired))
+	}
+
+	ids := make([]C.int, len(atomIndices))
+	xyz := make([]C.float, 3*len(desired))
+	for i, idx := range atomIndices {
+		ids[i] = C.int(idx)
+		xyz[3*i] = C.float(desired[i].X)
+		xyz[3*i+1] = C.float(desired[i].Y)
+		xyz[3*i+2] = C.float(desired[i].Z)
+	}
+
+	rmsd := float64(C.indigoAlignAtoms(C.int(m.Handle), C.int(len(ids)), &ids[0], &xyz[0]))
+	if rmsd < 0 {
+		return 0, session.NativeError(m.Handle, "align atoms")
+	}
+
+	return rmsd, nil
+}
+
+// Distance returns the distance between two points
+func Distance(a Point, b Point) float64 {
+	return norm(sub(a, b))
+}
+
+// Angle returns the angle a-b-c in degrees, with b as the vertex
+func Angle(a Point, b Point, c Point) float64 {
+	u := sub(a, b)
+	v := sub(c, b)
+
+	d := norm(u) * norm(v)
+	if d == 0 {
+		return 0
+	}
+
+	cos := math.Max(-1, math.Min(1, dot(u, v)/d))
+	return math.Acos(cos) * 180 / math.Pi
+}
+
+// Dihedral returns the torsion angle a-b-c-d in degrees, in the range (-180, 180]
+func Dihedral(a Point, b Point, c Point, d Point) float64 {
+	b1 := sub(b, a)
+	b2 := sub(c, b)
+	b3 := sub(d, c)
+
+	n1 := cross(b1, b2)
+	n2 := cross(b2, b3)
+
+	y := norm(b2) * dot(b1, n2)
+	x := dot(n1, n2)
+	return math.Atan2(y, x) * 180 / math.Pi
+}
+
+// Centroid returns the geometric center of the points
+func Centroid(points []Point) Point {
+	if len(points) == 0 {
+		return Point{}
+	}
+
+	var c Point
+	for _, p := range points {
+		c.X += p.X
+		c.Y += p.Y
+		c.Z += p.Z
+	}
+
+	return scale(c, 1/float64(len(points)))
+}
+
+// BoundingBox returns the minimum and maximum corners of the box enclosing the points
+func BoundingBox(points []Point) (Point, Point) {
+	if len(points) == 0 {
+		return Point{}, Point{}
+	}
+
+	min, max := points[0], points[0]
+	for _, p := range points[1:] {
+		min.X = math.Min(min.X, p.X)
+		min.Y = math.Min(min.Y, p.Y)
+		min.Z = math.Min(min.Z, p.Z)
+		max.X = math.Max(max.X, p.X)
+		max.Y = math.Max(max.Y, p.Y)
+		max.Z = math.Max(max.Z, p.Z)
+	}
+
+	return min, max
+}
+
+func sub(a Point, b Point) Point {
+	return Point{X: a.X - b.X, Y: a.Y - b.Y, Z: a.Z - b.Z}
+}
+
+func scale(a Point, k float64) Point {
+	return Point{X: a.X * k, Y: a.Y * k, Z: a.Z * k}
+}
+
+func dot(a Point, b Point) float64 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
+
+func cross(a Point, b Point) Point {
+	return Point{
+		X: a.Y*b.Z - a.Z*b.Y,
+		Y: a.Z*b.X - a.X*b.Z,
+		Z: a.X*b.Y - a.Y*b.X,
+	}
+}
+
+func norm(a Point) float64 {
+	return math.Sqrt(dot(a, a))
+}
@@ -0,0 +1,371 @@
+// Package molecule provides atom, bond and component iteration using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_iterator.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"runtime"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// handleIterator wraps a native Indigo iterator and owns the handle of its current element
+type handleIterator struct {
+	handle     int
+	current    int
+	generation uint64 // incremented for every element, as freed handles are reused by Indigo
+	closed     bool
+	err        error
+	session    *session.Session
+}
+
+// next frees the current element and advances to the next one
+func (it *handleIterator) next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if err := session.Enter(it.session); err != nil {
+		it.err = err
+		return false
+	}
+	defer session.Exit()
+
+	it.release()
+
+	item := int(C.indigoNext(C.int(it.handle)))
+	if item < 0 {
+		it.err = session.NativeError(it.handle, "get next element")
+		return false
+	}
+	if item == 0 {
+		return false
+	}
+
+	it.current = item
+	it.generation++
+	return true
+}
+
+// release frees the current element handle
+func (it *handleIterator) release() {
+	if it.current <= 0 {
+		return
+	}
+
+	// A closed session took the element with it
+	if session.Enter(it.session) == nil {
+		C.indigoFree(C.int(it.current))
+		session.Exit()
+	}
+	it.current = 0
+}
+
+// close frees the current element and the iterator itself
+func (it *handleIterator) close() error {
+	if it.closed || it.handle < 0 {
+		return nil
+	}
+
+	if it.session.Closed() {
+		it.current = 0
+		it.closed = true
+		it.handle = -1
+		return nil
+	}
+	if err := session.Enter(it.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	it.release()
+
+	ret := int(C.indigoFree(C.int(it.handle)))
+	if ret < 0 {
+		return session.NativeError(it.handle, "free iterator")
+	}
+
+	it.closed = true
+	it.handle = -1
+	return nil
+}
+
+// AtomIterator iterates over atoms of a molecule
+// The atom returned by Atom() is only valid until the next call to Next() or Close()
+//
+//	it, err := mol.Atoms()
+//	defer it.Close()
+//	for it.Next() {
+//		symbol, _ := it.Atom().Symbol()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type AtomIterator struct {
+	iter handleIterator
+}
+
+// BondIterator iterates over bonds of a molecule
+// The bond returned by Bond() is only valid until the next call to Next() or Close()
+type BondIterator struct {
+	iter handleIterator
+}
+
+// ComponentIterator iterates over connected components of a molecule
+// The molecule returned by Component() is only valid until the next call to Next() or Close()
+type ComponentIterator struct {
+	iter      handleIterator
+	component *Molecule
+}
+
+// Atoms returns an iterator over all atoms, including pseudoatoms and R-sites
+func (m *Molecule) Atoms() (*AtomIterator, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoIterateAtoms(C.int(m.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "create atoms iterator")
+	}
+
+	return newAtomIterator(handle, m.session), nil
+}
+
+// Pseudoatoms returns an iterator over pseudoatoms
+func (m *Molecule) Pseudoatoms() (*AtomIterator, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoIteratePseudoatoms(C.int(m.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "create pseudoatoms iterator")
+	}
+
+	return newAtomIterator(handle, m.session), nil
+}
+
+// RSites returns an iterator over R-sites
+func (m *Molecule) RSites() (*AtomIterator, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoIterateRSites(C.int(m.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "create R-sites iterator")
+	}
+
+	return newAtomIterator(handle, m.session), nil
+}
+
+// Bonds returns an iterator over all bonds
+func (m *Molecule) Bonds() (*BondIterator, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoIterateBonds(C.int(m.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "create bonds iterator")
+	}
+
+	iter := &BondIterator{iter: handleIterator{handle: handle, session: m.session}}
+	runtime.SetFinalizer(iter, (*BondIterator).Close)
+	return iter, nil
+}
+
+// Components returns an iterator over connected components
+func (m *Molecule) Components() (*ComponentIterator, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoIterateComponents(C.int(m.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "create components iterator")
+	}
+
+	iter := &ComponentIterator{iter: handleIterator{handle: handle, session: m.session}}
+	runtime.SetFinalizer(iter, (*ComponentIterator).Close)
+	return iter, nil
+}
+
+// Next advances to the next atom, freeing the previous one
+func (it *AtomIterator) Next() bool {
+	return it.iter.next()
+}
+
+// Atom returns the current atom; it is owned by the iterator, so closing it does nothing
+func (it *AtomIterator) Atom() *Atom {
+	return &Atom{Handle: it.iter.current, session: it.iter.session, borrowed: true}
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *AtomIterator) Err() error {
+	return it.iter.err
+}
+
+// Close frees the iterator and its current atom
+func (it *AtomIterator) Close() error {
+	return it.iter.close()
+}
+
+// ForEach calls fn for every remaining atom and closes the iterator
+// Iteration stops at the first error returned by fn
+func (it *AtomIterator) ForEach(fn func(*Atom) error) error {
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Atom()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// Next advances to the next bond, freeing the previous one
+func (it *BondIterator) Next() bool {
+	return it.iter.next()
+}
+
+// Bond returns the current bond; it is owned by the iterator, so closing it does nothing
+func (it *BondIterator) Bond() *Bond {
+	return &Bond{Handle: it.iter.current, session: it.iter.session, borrowed: true}
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *BondIterator) Err() error {
+	return it.iter.err
+}
+
+// Close frees the iterator and its current bond
+func (it *BondIterator) Close() error {
+	return it.iter.close()
+}
+
+// ForEach calls fn for every remaining bond and closes the iterator
+// Iteration stops at the first error returned by fn
+func (it *BondIterator) ForEach(fn func(*Bond) error) error {
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Bond()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// Next advances to the next component, freeing the previous one
+func (it *ComponentIterator) Next() bool {
+	it.closeComponent()
+
+	if !it.iter.next() {
+		return false
+	}
+	if err := session.Enter(it.iter.session); err != nil {
+		it.iter.err = err
+		return false
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoClone(C.int(it.iter.current)))
+	if handle < 0 {
+		it.iter.err = session.NativeError(0, "clone component")
+		return false
+	}
+
+	it.component = newMolecule(handle, it.iter.session)
+	return true
+}
+
+// Component returns the current component as a molecule
+// Use Clone() to keep it after the iteration moves on
+func (it *ComponentIterator) Component() *Molecule {
+	return it.component
+}
+
+// Index returns the index of the current component
+func (it *ComponentIterator) Index() (int, error) {
+	if err := session.Enter(it.iter.session); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	index := int(C.indigoIndex(C.int(it.iter.current)))
+	if index < 0 {
+		return 0, session.NativeError(0, "get component index")
+	}
+	return index, nil
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ComponentIterator) Err() error {
+	return it.iter.err
+}
+
+// Close frees the iterator and its current component
+func (it *ComponentIterator) Close() error {
+	it.closeComponent()
+	return it.iter.close()
+}
+
+// ForEach calls fn for every remaining component and closes the iterator
+// Iteration stops at the first error returned by fn
+func (it *ComponentIterator) ForEach(fn func(*Molecule) error) error {
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Component()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// closeComponent frees the molecule cloned for the current component
+func (it *ComponentIterator) closeComponent() {
+	if it.component != nil {
+		it.component.Close()
+		it.component = nil
+	}
+}
+
+// newAtomIterator is a helper function to create an AtomIterator from a handle
+// It sets up the finalizer to ensure proper cleanup
+func newAtomIterator(handle int, s *session.Session) *AtomIterator {
+	iter := &AtomIterator{iter: handleIterator{handle: handle, session: s}}
+	runtime.SetFinalizer(iter, (*AtomIterator).Close)
+	return iter
+}
@@ -28,8 +28,10 @@ package molecule
 */
 import "C"
 import (
-	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // SubstructureMatch represents a substructure match
@@ -39,21 +41,29 @@ type SubstructureMatch struct {
 
 // SubstructureMatcher performs substructure matching
 func (m *Molecule) SubstructureMatcher(query *Molecule) (*SubstructureMatch, error) {
-	if m.Closed {
-		return nil, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
+
 	if query.Closed {
-		return nil, fmt.Errorf("query molecule is closed")
+		return nil, indigoerr.Closed("query molecule")
+	}
+	if err := session.Same(m.session, query.session); err != nil {
+		return nil, err
 	}
 
 	handle := int(C.indigoSubstructureMatcher(C.int(m.Handle), C.CString("substructure")))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to create substructure matcher: %s", getLastError())
+		return nil, session.NativeError(m.Handle, "create substructure matcher")
 	}
 
 	matchHandle := int(C.indigoMatch(C.int(handle), C.int(query.Handle)))
 	if matchHandle < 0 {
-		return nil, fmt.Errorf("no match found")
+		return nil, session.NativeError(m.Handle, "match substructure")
+	}
+	if matchHandle == 0 {
+		return nil, indigoerr.NoMatch(m.Handle)
 	}
 
 	return &SubstructureMatch{handle: matchHandle}, nil
@@ -62,11 +72,16 @@ func (m *Molecule) SubstructureMatcher(query *Molecule) (*SubstructureMatch, err
 // CountSubstructureMatches counts the number of substructure matches
 // modeStr is the matching mode, "" or NORMAL, RES or RESONANCE, TAU..., defaults to ""
 func (m *Molecule) CountSubstructureMatches(queryMolecule *Molecule, modeStr *string) (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
+
 	if queryMolecule.Closed {
-		return 0, fmt.Errorf("queryMolecule molecule is closed")
+		return 0, indigoerr.Closed("queryMolecule molecule")
+	}
+	if err := session.Same(m.session, queryMolecule.session); err != nil {
+		return 0, err
 	}
 
 	// Prepare C string only if modeStr is provided and non-empty.
@@ -81,13 +96,13 @@ func (m *Molecule) CountSubstructureMatches(queryMolecule *Molecule, modeStr *st
 
 	matcherHandle := int(C.indigoSubstructureMatcher(C.int(m.Handle), cMode))
 	if matcherHandle < 0 {
-		return 0, fmt.Errorf("failed to create substructure matcher: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "create substructure matcher")
 	}
 	defer C.indigoFree(C.int(matcherHandle))
 
 	count := int(C.indigoCountMatches(C.int(matcherHandle), C.int(queryMolecule.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count matches: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "count matches")
 	}
 
 	return count, nil
@@ -96,11 +111,16 @@ func (m *Molecule) CountSubstructureMatches(queryMolecule *Molecule, modeStr *st
 // HasSubstructure checks if the molecule contains the given substructure
 // modeStr is the matching mode, NORMAL or RESONANCE or TAUTOMER
 func (m *Molecule) HasSubstructure(queryMolecule *Molecule, modeStr *string) (bool, error) {
-	if m.Closed {
-		return false, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return false, err
 	}
+	defer session.Exit()
+
 	if queryMolecule.Closed {
-		return false, fmt.Errorf("queryMolecule molecule is closed")
+		return false, indigoerr.Closed("queryMolecule molecule")
+	}
+	if err := session.Same(m.session, queryMolecule.session); err != nil {
+		return false, err
 	}
 
 	// Prepare C string only if modeStr is provided and non-empty.
@@ -114,7 +134,7 @@ func (m *Molecule) HasSubstructure(queryMolecule *Molecule, modeStr *string) (bo
 	}
 	matcherHandle := int(C.indigoSubstructureMatcher(C.int(m.Handle), cMode))
 	if matcherHandle < 0 {
-		return false, fmt.Errorf("failed to create substructure matcher: %s", getLastError())
+		return false, session.NativeError(m.Handle, "create substructure matcher")
 	}
 	defer C.indigoFree(C.int(matcherHandle))
 
@@ -133,11 +153,16 @@ func (m *Molecule) HasSubstructure(queryMolecule *Molecule, modeStr *string) (bo
 // "ALL": All of the above
 // By default (with null or empty flags string) all flags are on.
 func (m *Molecule) ExactMatch(other *Molecule, flags *string) (bool, int, error) {
-	if m.Closed {
-		return false, 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return false, 0, err
 	}
+	defer session.Exit()
+
 	if other.Closed {
-		return false, 0, fmt.Errorf("other molecule is closed")
+		return false, 0, indigoerr.Closed("other molecule")
+	}
+	if err := session.Same(m.session, other.session); err != nil {
+		return false, 0, err
 	}
 
 	var cFlags *C.char
@@ -151,7 +176,7 @@ func (m *Molecule) ExactMatch(other *Molecule, flags *string) (bool, int, error)
 	match := int(C.indigoExactMatch(C.int(m.Handle), C.int(other.Handle), cFlags))
 
 	if match < 0 {
-		return false, 0, fmt.Errorf("indigo error: %s", getLastError())
+		return false, 0, session.NativeError(m.Handle, "match exactly")
 	}
 	if match == 0 {
 		return false, 0, nil
@@ -162,11 +187,16 @@ func (m *Molecule) ExactMatch(other *Molecule, flags *string) (bool, int, error)
 
 // IterateSubstructureMatches iterates through all substructure matches
 func (m *Molecule) IterateSubstructureMatches(query *Molecule, modeStr *string) (int, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
+
 	if query.Closed {
-		return 0, fmt.Errorf("query molecule is closed")
+		return 0, indigoerr.Closed("query molecule")
+	}
+	if err := session.Same(m.session, query.session); err != nil {
+		return 0, err
 	}
 
 	// Prepare C string only if modeStr is provided and non-empty.
@@ -181,13 +211,13 @@ func (m *Molecule) IterateSubstructureMatches(query *Molecule, modeStr *string)
 
 	matcherHandle := int(C.indigoSubstructureMatcher(C.int(m.Handle), cMode))
 	if matcherHandle < 0 {
-		return 0, fmt.Errorf("failed to create substructure matcher: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "create substructure matcher")
 	}
 
 	iterHandle := int(C.indigoIterateMatches(C.int(matcherHandle), C.int(query.Handle)))
 	if iterHandle < 0 {
 		C.indigoFree(C.int(matcherHandle))
-		return 0, fmt.Errorf("failed to iterate matches: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "iterate matches")
 	}
 
 	return iterHandle, nil
@@ -195,13 +225,14 @@ func (m *Molecule) IterateSubstructureMatches(query *Molecule, modeStr *string)
 
 // Highlight highlights atoms and bonds from a match
 func (m *Molecule) Highlight(match *SubstructureMatch) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoHighlightedTarget(C.int(match.handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to highlight match: %s", getLastError())
+		return session.NativeError(m.Handle, "highlight match")
 	}
 
 	return nil
@@ -209,13 +240,14 @@ func (m *Molecule) Highlight(match *SubstructureMatch) error {
 
 // UnhighlightAll removes all highlights from the molecule
 func (m *Molecule) UnhighlightAll() error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoUnhighlight(C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to unhighlight: %s", getLastError())
+		return session.NativeError(m.Handle, "unhighlight")
 	}
 
 	return nil
@@ -223,9 +255,10 @@ func (m *Molecule) UnhighlightAll() error {
 
 // RemoveAtoms removes specified atoms from the molecule
 func (m *Molecule) RemoveAtoms(atomIndices []int) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	if len(atomIndices) == 0 {
 		return nil
@@ -239,7 +272,7 @@ func (m *Molecule) RemoveAtoms(atomIndices []int) error {
 
 	ret := int(C.indigoRemoveAtoms(C.int(m.Handle), C.int(len(atomIndices)), &cIndices[0]))
 	if ret < 0 {
-		return fmt.Errorf("failed to remove atoms: %s", getLastError())
+		return session.NativeError(m.Handle, "remove atoms")
 	}
 
 	return nil
@@ -247,9 +280,10 @@ func (m *Molecule) RemoveAtoms(atomIndices []int) error {
 
 // RemoveBonds removes specified bonds from the molecule
 func (m *Molecule) RemoveBonds(bondIndices []int) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	if len(bondIndices) == 0 {
 		return nil
@@ -263,7 +297,7 @@ func (m *Molecule) RemoveBonds(bondIndices []int) error {
 
 	ret := int(C.indigoRemoveBonds(C.int(m.Handle), C.int(len(bondIndices)), &cIndices[0]))
 	if ret < 0 {
-		return fmt.Errorf("failed to remove bonds: %s", getLastError())
+		return session.NativeError(m.Handle, "remove bonds")
 	}
 
 	return nil
@@ -271,12 +305,13 @@ func (m *Molecule) RemoveBonds(bondIndices []int) error {
 
 // GetSubmolecule returns a submolecule containing only specified atoms
 func (m *Molecule) GetSubmolecule(atomIndices []int) (*Molecule, error) {
-	if m.Closed {
-		return nil, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	if len(atomIndices) == 0 {
-		return nil, fmt.Errorf("no atoms specified")
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "no atoms specified")
 	}
 
 	// Convert to C array
@@ -287,8 +322,8 @@ func (m *Molecule) GetSubmolecule(atomIndices []int) (*Molecule, error) {
 
 	handle := int(C.indigoGetSubmolecule(C.int(m.Handle), C.int(len(atomIndices)), &cIndices[0]))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to get submolecule: %s", getLastError())
+		return nil, session.NativeError(m.Handle, "get submolecule")
 	}
 
-	return newMolecule(handle), nil
+	return newMolecule(handle, m.session), nil
 }
@@ -0,0 +1,392 @@
+// Package molecule provides substructure matchers and match mappings using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_matcher.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"runtime"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Matcher finds embeddings of query molecules into a target molecule
+type Matcher struct {
+	handle int
+	closed bool
+	target *Molecule
+}
+
+// Match is a single embedding of a query into the target
+// Atoms and Bonds are indexed by query atom and bond index: Atoms[i] and Bonds[i] hold the target
+// atom and bond indices matched by query atom and bond i, or -1 for query atoms and bonds without
+// a counterpart (e.g. R-sites) and for indices left free by removed query atoms and bonds
+type Match struct {
+	Atoms      []int
+	Bonds      []int
+	handle     int
+	generation uint64 // element generation of the owner when the match was produced
+	owner      *handleIterator
+}
+
+// MatchIterator iterates over the embeddings of a query
+//
+//	it, err := mol.Matches(query, "", 0)
+//	defer it.Close()
+//	for it.Next() {
+//		match := it.Match()
+//		fmt.Println(match.Atoms)
+//	}
+//	if err := it.Err(); err != nil { ... }
+type MatchIterator struct {
+	iter    handleIterator
+	query   *Molecule
+	matcher *Matcher // closed with the iterator if owned
+	owned   bool
+	limit   int
+	count   int
+	match   *Match
+}
+
+// NewMatcher creates a substructure matcher with the molecule as target
+// mode: "" for normal matching, "RES" for resonance, "TAU" for tautomer matching
+func (m *Molecule) NewMatcher(mode string) (*Matcher, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	var cMode *C.char
+	if mode != "" {
+		cMode = C.CString(mode)
+		defer C.free(unsafe.Pointer(cMode))
+	}
+
+	handle := int(C.indigoSubstructureMatcher(C.int(m.Handle), cMode))
+	if handle < 0 {
+		return nil, session.NativeError(m.Handle, "create substructure matcher")
+	}
+
+	mt := &Matcher{handle: handle, target: m}
+	runtime.SetFinalizer(mt, (*Matcher).Close)
+	return mt, nil
+}
+
+// Matches returns an iterator over at most limit embeddings of the query (0 for no limit)
+// The matcher is created for this call and freed when the iterator is closed
+func (m *Molecule) Matches(query *Molecule, mode string, limit int) (*MatchIterator, error) {
+	mt, err := m.NewMatcher(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := mt.Matches(query, limit)
+	if err != nil {
+		mt.Close()
+		return nil, err
+	}
+
+	it.matcher = mt
+	it.owned = true
+	return it, nil
+}
+
+// IgnoreAtom excludes the target atom at index from matching
+func (mt *Matcher) IgnoreAtom(index int) error {
+	return mt.withTargetAtom(index, func(atom int) int {
+		return int(C.indigoIgnoreAtom(C.int(mt.handle), C.int(atom)))
+	})
+}
+
+// UnignoreAtom allows the target atom at index to be matched again
+func (mt *Matcher) UnignoreAtom(index int) error {
+	return mt.withTargetAtom(index, func(atom int) int {
+		return int(C.indigoUnignoreAtom(C.int(mt.handle), C.int(atom)))
+	})
+}
+
+// UnignoreAllAtoms allows all target atoms to be matched again
+func (mt *Matcher) UnignoreAllAtoms() error {
+	if err := mt.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoUnignoreAllAtoms(C.int(mt.handle)))
+	if ret < 0 {
+		return session.NativeError(mt.handle, "unignore atoms")
+	}
+
+	return nil
+}
+
+// Count returns the number of embeddings of the query, stopping at limit (0 for no limit)
+func (mt *Matcher) Count(query *Molecule, limit int) (int, error) {
+	if query.Closed {
+		return 0, indigoerr.Closed("query molecule")
+	}
+	if err := mt.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	if err := session.Same(mt.target.session, query.session); err != nil {
+		return 0, err
+	}
+
+	var count int
+	if limit > 0 {
+		count = int(C.indigoCountMatchesWithLimit(C.int(mt.handle), C.int(query.Handle), C.int(limit)))
+	} else {
+		count = int(C.indigoCountMatches(C.int(mt.handle), C.int(query.Handle)))
+	}
+	if count < 0 {
+		return 0, session.NativeError(mt.handle, "count matches")
+	}
+
+	return count, nil
+}
+
+// Matches returns an iterator over at most limit embeddings of the query (0 for no limit)
+// The matcher must stay open while the iterator is used
+func (mt *Matcher) Matches(query *Molecule, limit int) (*MatchIterator, error) {
+	if query.Closed {
+		return nil, indigoerr.Closed("query molecule")
+	}
+	if err := mt.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	if err := session.Same(mt.target.session, query.session); err != nil {
+		return nil, err
+	}
+
+	handle := int(C.indigoIterateMatches(C.int(mt.handle), C.int(query.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(mt.handle, "iterate matches")
+	}
+
+	iter := handleIterator{handle: handle, session: mt.target.session}
+	it := &MatchIterator{iter: iter, query: query, matcher: mt, limit: limit}
+	runtime.SetFinalizer(it, (*MatchIterator).Close)
+	return it, nil
+}
+
+// Close frees the matcher
+func (mt *Matcher) Close() error {
+	if mt.closed || mt.handle < 0 {
+		return nil
+	}
+
+	if mt.target.session.Closed() {
+		mt.closed = true
+		mt.handle = -1
+		return nil
+	}
+	if err := session.Enter(mt.target.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoFree(C.int(mt.handle)))
+	if ret < 0 {
+		return session.NativeError(mt.handle, "free matcher")
+	}
+
+	mt.closed = true
+	mt.handle = -1
+	return nil
+}
+
+// enter pins the calling goroutine to the target's session; pair it with session.Exit
+func (mt *Matcher) enter() error {
+	if mt.closed {
+		return indigoerr.Closed("matcher")
+	}
+	return mt.target.enter()
+}
+
+// withTargetAtom calls fn with the handle of the target atom at index
+func (mt *Matcher) withTargetAtom(index int, fn func(atom int) int) error {
+	if err := mt.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	atom, err := mt.target.GetAtom(index)
+	if err != nil {
+		return err
+	}
+	defer atom.Close()
+
+	if fn(atom.Handle) < 0 {
+		return session.NativeError(mt.handle, "update ignored atom %d", index)
+	}
+
+	return nil
+}
+
+// Next advances to the next match, freeing the previous one
+func (it *MatchIterator) Next() bool {
+	it.match = nil
+	if it.limit > 0 && it.count >= it.limit {
+		it.iter.release()
+		return false
+	}
+
+	if it.query.Closed {
+		it.iter.err = indigoerr.Closed("query molecule")
+		it.iter.release()
+		return false
+	}
+
+	if !it.iter.next() {
+		return false
+	}
+	if err := session.Enter(it.iter.session); err != nil {
+		it.iter.err = err
+		return false
+	}
+	defer session.Exit()
+
+	atoms, err := mapItems(it.iter.current, C.indigoIterateAtoms(C.int(it.query.Handle)), func(match C.int, item C.int) C.int {
+		return C.indigoMapAtom(match, item)
+	})
+	if err != nil {
+		it.iter.err = err
+		return false
+	}
+
+	bonds, err := mapItems(it.iter.current, C.indigoIterateBonds(C.int(it.query.Handle)), func(match C.int, item C.int) C.int {
+		return C.indigoMapBond(match, item)
+	})
+	if err != nil {
+		it.iter.err = err
+		return false
+	}
+
+	it.count++
+	it.match = &Match{Atoms: atoms, Bonds: bonds, handle: it.iter.current, generation: it.iter.generation, owner: &it.iter}
+	return true
+}
+
+// Match returns the current match
+func (it *MatchIterator) Match() *Match {
+	return it.match
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *MatchIterator) Err() error {
+	return it.iter.err
+}
+
+// Close frees the iterator and, if it created it, the matcher
+func (it *MatchIterator) Close() error {
+	it.match = nil
+	err := it.iter.close()
+	if it.owned && it.matcher != nil {
+		if cerr := it.matcher.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// ForEach calls fn for every remaining match and closes the iterator
+// Iteration stops at the first error returned by fn
+func (it *MatchIterator) ForEach(fn func(*Match) error) error {
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Match()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// HighlightedTarget returns a copy of the target with the matched atoms and bonds highlighted
+// Only valid until the iterator that produced the match moves on
+func (mt *Match) HighlightedTarget() (*Molecule, error) {
+	if mt.owner == nil || mt.owner.generation != mt.generation || mt.owner.current <= 0 {
+		return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "match is no longer valid")
+	}
+	if err := session.Enter(mt.owner.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoHighlightedTarget(C.int(mt.handle)))
+	if handle < 0 {
+		return nil, session.NativeError(0, "get highlighted target")
+	}
+
+	return newMolecule(handle, mt.owner.session), nil
+}
+
+// mapItems maps every query atom or bond of an iterator to its target index, -1 if unmapped
+func mapItems(match int, iterHandle C.int, mapFn func(match C.int, item C.int) C.int) ([]int, error) {
+	if int(iterHandle) < 0 {
+		return nil, session.NativeError(0, "iterate query")
+	}
+
+	indices := []int{}
+	err := iterateHandles(int(iterHandle), func(item int) error {
+		queryIndex := int(C.indigoIndex(C.int(item)))
+		if queryIndex < 0 {
+			return session.NativeError(0, "get query index")
+		}
+		// Query items removed earlier leave gaps, which stay unmatched
+		for len(indices) <= queryIndex {
+			indices = append(indices, -1)
+		}
+
+		mapped := int(mapFn(C.int(match), C.int(item)))
+		if mapped < 0 {
+			return session.NativeError(0, "map query item")
+		}
+		if mapped == 0 {
+			return nil
+		}
+
+		index := int(C.indigoIndex(C.int(mapped)))
+		C.indigoFree(C.int(mapped))
+		if index < 0 {
+			return session.NativeError(0, "get mapped index")
+		}
+		indices[queryIndex] = index
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return indices, nil
+}
@@ -0,0 +1,129 @@
+// Package molecule provides pKa prediction using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_pka.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Default levels of the advanced pKa model
+const (
+	PKA_DEFAULT_LEVEL     = 5
+	PKA_DEFAULT_MIN_LEVEL = 0
+)
+
+// Indigo returns these values for atoms that are not acidic or basic sites
+const (
+	pkaNoAcid  = 100
+	pkaNoBasic = -100
+)
+
+// PkaSite is an ionizable atom with its predicted pKa
+type PkaSite struct {
+	AtomIndex int
+	Acidic    bool // true for an acidic site, false for a basic site
+	Pka       float64
+}
+
+// Pka returns the pKa estimate of the molecule
+func (m *Molecule) Pka() (float64, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	return nativeFloat(m.Handle, "get pKa", func() C.double {
+		return C.indigoPka(C.int(m.Handle))
+	})
+}
+
+// PkaValues returns the per-atom pKa values as reported by Indigo
+func (m *Molecule) PkaValues() (string, error) {
+	if err := m.enter(); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
+	cStr := C.indigoPkaValues(C.int(m.Handle))
+	if cStr == nil {
+		return "", session.NativeError(m.Handle, "get pKa values")
+	}
+
+	return C.GoString(cStr), nil
+}
+
+// PkaSites returns the acidic and basic atoms with their predicted pKa values
+// using the default levels of the pKa model
+func (m *Molecule) PkaSites() ([]PkaSite, error) {
+	return m.PkaSitesWithLevel(PKA_DEFAULT_LEVEL, PKA_DEFAULT_MIN_LEVEL)
+}
+
+// PkaSitesWithLevel returns the acidic and basic atoms using the given model levels
+// An atom may appear twice if it is both an acidic and a basic site
+func (m *Molecule) PkaSitesWithLevel(level int, minLevel int) ([]PkaSite, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateAtoms(C.int(m.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate atoms")
+	}
+
+	var sites []PkaSite
+	err := iterateHandles(iterHandle, func(atom int) error {
+		index := int(C.indigoIndex(C.int(atom)))
+		if index < 0 {
+			return session.NativeError(m.Handle, "get atom index")
+		}
+
+		acid := C.indigoGetAcidPkaValue(C.int(m.Handle), C.int(atom), C.int(level), C.int(minLevel))
+		if acid == nil {
+			return session.NativeError(m.Handle, "get acid pKa of atom %d", index)
+		}
+		if v := float64(*acid); v < pkaNoAcid {
+			sites = append(sites, PkaSite{AtomIndex: index, Acidic: true, Pka: v})
+		}
+
+		basic := C.indigoGetBasicPkaValue(C.int(m.Handle), C.int(atom), C.int(level), C.int(minLevel))
+		if basic == nil {
+			return session.NativeError(m.Handle, "get basic pKa of atom %d", index)
+		}
+		if v := float64(*basic); v > pkaNoBasic {
+			sites = append(sites, PkaSite{AtomIndex: index, Acidic: false, Pka: v})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
@@ -28,24 +28,28 @@ package molecule
 */
 import "C"
 import (
-	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/property"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // GrossFormula returns the gross formula of the molecule
 func (m *Molecule) GrossFormula() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoGrossFormula(C.int(m.Handle)))
 	if handle < 0 {
-		return "", fmt.Errorf("failed to get gross formula: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get gross formula")
 	}
 
 	cStr := C.indigoToString(C.int(handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert gross formula to string: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert gross formula to string")
 	}
 
 	return C.GoString(cStr), nil
@@ -53,18 +57,19 @@ func (m *Molecule) GrossFormula() (string, error) {
 
 // MolecularFormula returns the molecular formula of the molecule
 func (m *Molecule) MolecularFormula() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoMolecularFormula(C.int(m.Handle)))
 	if handle < 0 {
-		return "", fmt.Errorf("failed to get molecular formula: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get molecular formula")
 	}
 
 	cStr := C.indigoToString(C.int(handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert molecular formula to string: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert molecular formula to string")
 	}
 
 	return C.GoString(cStr), nil
@@ -72,13 +77,14 @@ func (m *Molecule) MolecularFormula() (string, error) {
 
 // MolecularWeight returns the molecular weight of the molecule
 func (m *Molecule) MolecularWeight() (float64, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	weight := float64(C.indigoMolecularWeight(C.int(m.Handle)))
 	if weight < 0 {
-		return 0, fmt.Errorf("failed to get molecular weight: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "get molecular weight")
 	}
 
 	return weight, nil
@@ -86,13 +92,14 @@ func (m *Molecule) MolecularWeight() (float64, error) {
 
 // MostAbundantMass returns the most abundant mass of the molecule
 func (m *Molecule) MostAbundantMass() (float64, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	mass := float64(C.indigoMostAbundantMass(C.int(m.Handle)))
 	if mass < 0 {
-		return 0, fmt.Errorf("failed to get most abundant mass: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "get most abundant mass")
 	}
 
 	return mass, nil
@@ -100,13 +107,14 @@ func (m *Molecule) MostAbundantMass() (float64, error) {
 
 // MonoisotopicMass returns the monoisotopic mass of the molecule
 func (m *Molecule) MonoisotopicMass() (float64, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	mass := float64(C.indigoMonoisotopicMass(C.int(m.Handle)))
 	if mass < 0 {
-		return 0, fmt.Errorf("failed to get monoisotopic mass: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "get monoisotopic mass")
 	}
 
 	return mass, nil
@@ -114,13 +122,14 @@ func (m *Molecule) MonoisotopicMass() (float64, error) {
 
 // MassComposition returns the mass composition of the molecule
 func (m *Molecule) MassComposition() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoMassComposition(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get mass composition: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get mass composition")
 	}
 
 	return C.GoString(cStr), nil
@@ -129,9 +138,10 @@ func (m *Molecule) MassComposition() (string, error) {
 // TPSA returns the topological polar surface area
 // includeSP: if true, includes sulfur and phosphorus
 func (m *Molecule) TPSA(includeSP bool) (float64, error) {
-	if m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	var sp C.int
 	if includeSP {
@@ -142,7 +152,7 @@ func (m *Molecule) TPSA(includeSP bool) (float64, error) {
 
 	tpsa := float64(C.indigoTPSA(C.int(m.Handle), sp))
 	if tpsa < 0 {
-		return 0, fmt.Errorf("failed to get TPSA: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "get TPSA")
 	}
 
 	return tpsa, nil
@@ -150,23 +160,104 @@ func (m *Molecule) TPSA(includeSP bool) (float64, error) {
 
 // NumRotatableBonds returns the number of rotatable bonds
 func (m *Molecule) NumRotatableBonds() (int, error) {
-	if m == nil || m.Closed {
-		return 0, fmt.Errorf("molecule is closed")
+	if m == nil {
+		return 0, indigoerr.Closed("molecule")
+	}
+	if err := m.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoNumRotatableBonds(C.int(m.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to get number of rotatable bonds: %s", getLastError())
+		return 0, session.NativeError(m.Handle, "get number of rotatable bonds")
+	}
+
+	return count, nil
+}
+
+// NumHydrogenBondAcceptors returns the number of hydrogen bond acceptors
+func (m *Molecule) NumHydrogenBondAcceptors() (int, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	count := int(C.indigoNumHydrogenBondAcceptors(C.int(m.Handle)))
+	if count < 0 {
+		return 0, session.NativeError(m.Handle, "get number of hydrogen bond acceptors")
 	}
 
 	return count, nil
 }
 
+// NumHydrogenBondDonors returns the number of hydrogen bond donors
+func (m *Molecule) NumHydrogenBondDonors() (int, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	count := int(C.indigoNumHydrogenBondDonors(C.int(m.Handle)))
+	if count < 0 {
+		return 0, session.NativeError(m.Handle, "get number of hydrogen bond donors")
+	}
+
+	return count, nil
+}
+
+// LogP returns the octanol-water partition coefficient (Crippen method)
+func (m *Molecule) LogP() (float64, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	return nativeFloat(m.Handle, "get logP", func() C.double {
+		return C.indigoLogP(C.int(m.Handle))
+	})
+}
+
+// MolarRefractivity returns the molar refractivity (Crippen method)
+func (m *Molecule) MolarRefractivity() (float64, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	mr := float64(C.indigoMolarRefractivity(C.int(m.Handle)))
+	if mr < 0 {
+		return 0, session.NativeError(m.Handle, "get molar refractivity")
+	}
+
+	return mr, nil
+}
+
+// CountHydrogens returns the total number of implicit and explicit hydrogens
+func (m *Molecule) CountHydrogens() (int, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	var count C.int
+	ret := int(C.indigoCountHydrogens(C.int(m.Handle), &count))
+	if ret < 0 {
+		return 0, session.NativeError(m.Handle, "count hydrogens")
+	}
+	if ret == 0 {
+		return 0, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "number of hydrogens is not definitely known")
+	}
+
+	return int(count), nil
+}
+
 // Name returns the name of the molecule
 func (m *Molecule) Name() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoName(C.int(m.Handle))
 	if cStr == nil {
@@ -178,16 +269,17 @@ func (m *Molecule) Name() (string, error) {
 
 // SetName sets the name of the molecule
 func (m *Molecule) SetName(name string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 
 	ret := int(C.indigoSetName(C.int(m.Handle), cName))
 	if ret < 0 {
-		return fmt.Errorf("failed to set name: %s", getLastError())
+		return session.NativeError(m.Handle, "set name")
 	}
 
 	return nil
@@ -195,16 +287,17 @@ func (m *Molecule) SetName(name string) error {
 
 // HasProperty checks if the molecule has a property
 func (m *Molecule) HasProperty(prop string) (bool, error) {
-	if m.Closed {
-		return false, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return false, err
 	}
+	defer session.Exit()
 
 	cProp := C.CString(prop)
 	defer C.free(unsafe.Pointer(cProp))
 
 	ret := int(C.indigoHasProperty(C.int(m.Handle), cProp))
 	if ret < 0 {
-		return false, fmt.Errorf("failed to check property: %s", getLastError())
+		return false, session.NativeError(m.Handle, "check property")
 	}
 
 	return ret > 0, nil
@@ -212,16 +305,17 @@ func (m *Molecule) HasProperty(prop string) (bool, error) {
 
 // GetProperty gets a property value from the molecule
 func (m *Molecule) GetProperty(prop string) (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cProp := C.CString(prop)
 	defer C.free(unsafe.Pointer(cProp))
 
 	cStr := C.indigoGetProperty(C.int(m.Handle), cProp)
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get property: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get property")
 	}
 
 	return C.GoString(cStr), nil
@@ -229,9 +323,10 @@ func (m *Molecule) GetProperty(prop string) (string, error) {
 
 // SetProperty sets a property value on the molecule
 func (m *Molecule) SetProperty(prop string, value string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cProp := C.CString(prop)
 	defer C.free(unsafe.Pointer(cProp))
@@ -241,7 +336,7 @@ func (m *Molecule) SetProperty(prop string, value string) error {
 
 	ret := int(C.indigoSetProperty(C.int(m.Handle), cProp, cValue))
 	if ret < 0 {
-		return fmt.Errorf("failed to set property: %s", getLastError())
+		return session.NativeError(m.Handle, "set property")
 	}
 
 	return nil
@@ -249,17 +344,81 @@ func (m *Molecule) SetProperty(prop string, value string) error {
 
 // RemoveProperty removes a property from the molecule
 func (m *Molecule) RemoveProperty(prop string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cProp := C.CString(prop)
 	defer C.free(unsafe.Pointer(cProp))
 
 	ret := int(C.indigoRemoveProperty(C.int(m.Handle), cProp))
 	if ret < 0 {
-		return fmt.Errorf("failed to remove property: %s", getLastError())
+		return session.NativeError(m.Handle, "remove property")
+	}
+
+	return nil
+}
+
+// Properties returns all properties of the molecule, such as SD file data fields
+func (m *Molecule) Properties() (map[string]string, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	return property.All(m.Handle)
+}
+
+// SetProperties sets every property of the map, keeping the other existing properties
+func (m *Molecule) SetProperties(props map[string]string) error {
+	for prop, value := range props {
+		if err := m.SetProperty(prop, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearProperties removes all properties of the molecule
+func (m *Molecule) ClearProperties() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoClearProperties(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "clear properties")
 	}
 
 	return nil
 }
+
+// PropertyFloat gets a property value parsed as a float
+func (m *Molecule) PropertyFloat(prop string) (float64, error) {
+	value, err := m.GetProperty(prop)
+	if err != nil {
+		return 0, err
+	}
+	return property.ParseFloat(prop, value)
+}
+
+// PropertyInt gets a property value parsed as an integer
+func (m *Molecule) PropertyInt(prop string) (int, error) {
+	value, err := m.GetProperty(prop)
+	if err != nil {
+		return 0, err
+	}
+	return property.ParseInt(prop, value)
+}
+
+// PropertyBool gets a property value parsed as a boolean
+// Accepts the strconv.ParseBool forms as well as yes/no, case-insensitive
+func (m *Molecule) PropertyBool(prop string) (bool, error) {
+	value, err := m.GetProperty(prop)
+	if err != nil {
+		return false, err
+	}
+	return property.ParseBool(prop, value)
+}
@@ -0,0 +1,348 @@
+// Package molecule provides query atom and bond constraints using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_query.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"strconv"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Query constraint types
+const (
+	CONSTRAINT_ATOMIC_NUMBER = "atomic-number"
+	CONSTRAINT_CHARGE        = "charge"
+	CONSTRAINT_ISOTOPE       = "isotope"
+	CONSTRAINT_RADICAL       = "radical"
+	CONSTRAINT_VALENCE       = "valence"
+	CONSTRAINT_CONNECTIVITY  = "connectivity"
+	CONSTRAINT_HYDROGENS     = "hydrogens"
+	CONSTRAINT_SUBSTITUENTS  = "substituents"
+	CONSTRAINT_RINGS         = "rings"
+	CONSTRAINT_RING_BONDS    = "ring-bonds"
+	CONSTRAINT_UNSATURATION  = "unsaturation"
+	CONSTRAINT_AROMATICITY   = "aromaticity"
+	CONSTRAINT_TOPOLOGY      = "topology"
+)
+
+// Values of the aromaticity and topology constraints
+const (
+	CONSTRAINT_AROMATIC  = "aromatic"
+	CONSTRAINT_ALIPHATIC = "aliphatic"
+	CONSTRAINT_RING      = "ring"
+	CONSTRAINT_CHAIN     = "chain"
+)
+
+// Constraint combination modes
+const (
+	constraintAnd = iota
+	constraintNot
+	constraintOr
+)
+
+// AddConstraint restricts a query atom with an additional constraint (logical AND)
+func (a *Atom) AddConstraint(constraintType string, value string) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return addConstraint(a.Handle, constraintType, value, constraintAnd)
+}
+
+// AddConstraintNot restricts a query atom with a negated constraint
+func (a *Atom) AddConstraintNot(constraintType string, value string) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return addConstraint(a.Handle, constraintType, value, constraintNot)
+}
+
+// AddConstraintOr extends a query atom with an alternative constraint (logical OR)
+func (a *Atom) AddConstraintOr(constraintType string, value string) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return addConstraint(a.Handle, constraintType, value, constraintOr)
+}
+
+// RemoveConstraints removes all constraints of the given type from a query atom
+func (a *Atom) RemoveConstraints(constraintType string) error {
+	if err := session.Enter(a.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return removeConstraints(a.Handle, constraintType)
+}
+
+// AddConstraint restricts a query bond with an additional constraint (logical AND)
+func (b *Bond) AddConstraint(constraintType string, value string) error {
+	if err := session.Enter(b.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return addConstraint(b.Handle, constraintType, value, constraintAnd)
+}
+
+// AddConstraintNot restricts a query bond with a negated constraint
+func (b *Bond) AddConstraintNot(constraintType string, value string) error {
+	if err := session.Enter(b.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return addConstraint(b.Handle, constraintType, value, constraintNot)
+}
+
+// RemoveConstraints removes all constraints of the given type from a query bond
+func (b *Bond) RemoveConstraints(constraintType string) error {
+	if err := session.Enter(b.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	return removeConstraints(b.Handle, constraintType)
+}
+
+// AtomQuery builds the constraints of a query atom; the first error stops the chain
+//
+//	q, err := query.QueryAtom(0)
+//	defer q.Close()
+//	q.AtomicNumberIn(6, 7).InRing(true).Aromatic(true)
+//	if err := q.Err(); err != nil { ... }
+type AtomQuery struct {
+	atom *Atom
+	err  error
+}
+
+// BondQuery builds the constraints of a query bond; the first error stops the chain
+type BondQuery struct {
+	bond *Bond
+	err  error
+}
+
+// QueryAtom returns a builder for the constraints of the atom at index
+// The molecule should be a query molecule, e.g. from CreateQueryMolecule
+func (m *Molecule) QueryAtom(index int) (*AtomQuery, error) {
+	atom, err := m.GetAtom(index)
+	if err != nil {
+		return nil, err
+	}
+	return &AtomQuery{atom: atom}, nil
+}
+
+// QueryBond returns a builder for the constraints of the bond at index
+func (m *Molecule) QueryBond(index int) (*BondQuery, error) {
+	bond, err := m.GetBond(index)
+	if err != nil {
+		return nil, err
+	}
+	return &BondQuery{bond: bond}, nil
+}
+
+// AtomicNumberIn replaces the element of the atom with a list of allowed atomic numbers
+func (q *AtomQuery) AtomicNumberIn(numbers ...int) *AtomQuery {
+	if len(numbers) == 0 {
+		return q
+	}
+
+	q.apply(func() error { return q.atom.RemoveConstraints(CONSTRAINT_ATOMIC_NUMBER) })
+	q.apply(func() error { return q.atom.AddConstraint(CONSTRAINT_ATOMIC_NUMBER, strconv.Itoa(numbers[0])) })
+	for _, n := range numbers[1:] {
+		value := strconv.Itoa(n)
+		q.apply(func() error { return q.atom.AddConstraintOr(CONSTRAINT_ATOMIC_NUMBER, value) })
+	}
+	return q
+}
+
+// NotAtomicNumber excludes the given atomic numbers
+func (q *AtomQuery) NotAtomicNumber(numbers ...int) *AtomQuery {
+	for _, n := range numbers {
+		value := strconv.Itoa(n)
+		q.apply(func() error { return q.atom.AddConstraintNot(CONSTRAINT_ATOMIC_NUMBER, value) })
+	}
+	return q
+}
+
+// Charge requires the given formal charge
+func (q *AtomQuery) Charge(charge int) *AtomQuery {
+	return q.Constraint(CONSTRAINT_CHARGE, strconv.Itoa(charge))
+}
+
+// InRing requires the atom to be in a ring (true) or in a chain (false)
+func (q *AtomQuery) InRing(inRing bool) *AtomQuery {
+	if inRing {
+		return q.ConstraintNot(CONSTRAINT_RINGS, "0")
+	}
+	return q.Constraint(CONSTRAINT_RINGS, "0")
+}
+
+// RingBonds requires the given number of ring bonds
+func (q *AtomQuery) RingBonds(count int) *AtomQuery {
+	return q.Constraint(CONSTRAINT_RING_BONDS, strconv.Itoa(count))
+}
+
+// Substituents requires the given number of non-hydrogen neighbors
+func (q *AtomQuery) Substituents(count int) *AtomQuery {
+	return q.Constraint(CONSTRAINT_SUBSTITUENTS, strconv.Itoa(count))
+}
+
+// Aromatic requires the atom to be aromatic (true) or aliphatic (false)
+func (q *AtomQuery) Aromatic(aromatic bool) *AtomQuery {
+	if aromatic {
+		return q.Constraint(CONSTRAINT_AROMATICITY, CONSTRAINT_AROMATIC)
+	}
+	return q.Constraint(CONSTRAINT_AROMATICITY, CONSTRAINT_ALIPHATIC)
+}
+
+// Constraint adds an arbitrary constraint (logical AND)
+func (q *AtomQuery) Constraint(constraintType string, value string) *AtomQuery {
+	q.apply(func() error { return q.atom.AddConstraint(constraintType, value) })
+	return q
+}
+
+// ConstraintNot adds an arbitrary negated constraint
+func (q *AtomQuery) ConstraintNot(constraintType string, value string) *AtomQuery {
+	q.apply(func() error { return q.atom.AddConstraintNot(constraintType, value) })
+	return q
+}
+
+// Remove removes all constraints of the given type
+func (q *AtomQuery) Remove(constraintType string) *AtomQuery {
+	q.apply(func() error { return q.atom.RemoveConstraints(constraintType) })
+	return q
+}
+
+// Err returns the first error met while building the query
+func (q *AtomQuery) Err() error {
+	return q.err
+}
+
+// Close frees the atom handle held by the builder
+func (q *AtomQuery) Close() error {
+	return q.atom.Close()
+}
+
+// apply runs fn unless an earlier step failed
+func (q *AtomQuery) apply(fn func() error) {
+	if q.err == nil {
+		q.err = fn()
+	}
+}
+
+// Topology requires the bond to be in a ring (TOPOLOGY_RING) or in a chain (TOPOLOGY_CHAIN)
+func (q *BondQuery) Topology(topology int) *BondQuery {
+	switch topology {
+	case TOPOLOGY_RING:
+		return q.Constraint(CONSTRAINT_TOPOLOGY, CONSTRAINT_RING)
+	case TOPOLOGY_CHAIN:
+		return q.Constraint(CONSTRAINT_TOPOLOGY, CONSTRAINT_CHAIN)
+	}
+
+	q.apply(func() error { return indigoerr.New(indigoerr.CATEGORY_INVALID, "unknown bond topology %d", topology) })
+	return q
+}
+
+// Constraint adds an arbitrary constraint (logical AND)
+func (q *BondQuery) Constraint(constraintType string, value string) *BondQuery {
+	q.apply(func() error { return q.bond.AddConstraint(constraintType, value) })
+	return q
+}
+
+// ConstraintNot adds an arbitrary negated constraint
+func (q *BondQuery) ConstraintNot(constraintType string, value string) *BondQuery {
+	q.apply(func() error { return q.bond.AddConstraintNot(constraintType, value) })
+	return q
+}
+
+// Remove removes all constraints of the given type
+func (q *BondQuery) Remove(constraintType string) *BondQuery {
+	q.apply(func() error { return q.bond.RemoveConstraints(constraintType) })
+	return q
+}
+
+// Err returns the first error met while building the query
+func (q *BondQuery) Err() error {
+	return q.err
+}
+
+// Close frees the bond handle held by the builder
+func (q *BondQuery) Close() error {
+	return q.bond.Close()
+}
+
+// apply runs fn unless an earlier step failed
+func (q *BondQuery) apply(fn func() error) {
+	if q.err == nil {
+		q.err = fn()
+	}
+}
+
+// addConstraint adds a constraint to a query atom or bond
+func addConstraint(handle int, constraintType string, value string, mode int) error {
+	cType := C.CString(constraintType)
+	defer C.free(unsafe.Pointer(cType))
+
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	var ret int
+	switch mode {
+	case constraintNot:
+		ret = int(C.indigoAddConstraintNot(C.int(handle), cType, cValue))
+	case constraintOr:
+		ret = int(C.indigoAddConstraintOr(C.int(handle), cType, cValue))
+	default:
+		ret = int(C.indigoAddConstraint(C.int(handle), cType, cValue))
+	}
+
+	if ret < 0 {
+		return session.NativeError(0, "add %s constraint", constraintType)
+	}
+	return nil
+}
+
+// removeConstraints removes all constraints of a type from a query atom or bond
+func removeConstraints(handle int, constraintType string) error {
+	cType := C.CString(constraintType)
+	defer C.free(unsafe.Pointer(cType))
+
+	ret := int(C.indigoRemoveConstraints(C.int(handle), cType))
+	if ret < 0 {
+		return session.NativeError(0, "remove %s constraints", constraintType)
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+// Package molecule provides R-group decomposition and scaffold detection using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_rgroup.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// RGroupDecomposer decomposes molecules into a scaffold and R-group substituents
+//
+//	dec, err := molecule.NewRGroupDecomposer(scaffold)
+//	defer dec.Close()
+//	results, err := dec.Decompose(mols)
+//	for _, res := range results {
+//		r1 := res.RGroups[1]
+//	}
+type RGroupDecomposer struct {
+	handle  int
+	closed  bool
+	session *session.Session
+}
+
+// RGroupDecomposition is the decomposition of a single molecule
+// Scaffold and Highlighted are owned by the caller; use Close to free them
+type RGroupDecomposition struct {
+	Index       int            // position of the molecule in the input slice
+	Scaffold    *Molecule      // matched scaffold with R-sites marking the substituents
+	RGroups     map[int]string // R-group number -> fragment SMILES
+	Highlighted *Molecule      // the input molecule with the scaffold highlighted
+	Err         error          // set if the molecule could not be decomposed
+}
+
+// NewRGroupDecomposer creates a decomposer for the given scaffold query
+func NewRGroupDecomposer(scaffold *Molecule) (*RGroupDecomposer, error) {
+	if scaffold == nil || scaffold.Closed {
+		return nil, indigoerr.Closed("scaffold molecule")
+	}
+	if err := scaffold.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	handle := int(C.indigoCreateDecomposer(C.int(scaffold.Handle)))
+	if handle < 0 {
+		return nil, session.NativeError(0, "create decomposer")
+	}
+
+	d := &RGroupDecomposer{handle: handle, session: scaffold.session}
+	runtime.SetFinalizer(d, (*RGroupDecomposer).Close)
+	return d, nil
+}
+
+// Decompose decomposes each molecule against the scaffold
+// Molecules not matching the scaffold get a non-nil Err instead of failing the whole call
+func (d *RGroupDecomposer) Decompose(mols []*Molecule) ([]RGroupDecomposition, error) {
+	if d.closed {
+		return nil, indigoerr.Closed("decomposer")
+	}
+	if err := session.Enter(d.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	results := make([]RGroupDecomposition, 0, len(mols))
+	for i, mol := range mols {
+		res := RGroupDecomposition{Index: i}
+		if mol == nil || mol.Closed {
+			res.Err = indigoerr.Closed("molecule")
+		} else if err := session.Same(d.session, mol.session); err != nil {
+			res.Err = err
+		} else {
+			res.Err = d.decomposeOne(mol, &res)
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// FullScaffold returns the scaffold extended with every R-site found so far
+func (d *RGroupDecomposer) FullScaffold() (*Molecule, error) {
+	if d.closed {
+		return nil, indigoerr.Closed("decomposer")
+	}
+	if err := session.Enter(d.session); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	return cloneResult(int(C.indigoDecomposedMoleculeScaffold(C.int(d.handle))), "full scaffold", d.session)
+}
+
+// Close frees the decomposer
+func (d *RGroupDecomposer) Close() error {
+	if d.closed || d.handle < 0 {
+		return nil
+	}
+
+	if d.session.Closed() {
+		d.closed = true
+		d.handle = -1
+		return nil
+	}
+	if err := session.Enter(d.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoFree(C.int(d.handle)))
+	if ret < 0 {
+		return session.NativeError(d.handle, "free decomposer")
+	}
+
+	d.closed = true
+	d.handle = -1
+	return nil
+}
+
+// decomposeOne decomposes a single molecule and adds its first match to the full scaffold
+func (d *RGroupDecomposer) decomposeOne(mol *Molecule, res *RGroupDecomposition) error {
+	item := int(C.indigoDecomposeMolecule(C.int(d.handle), C.int(mol.Handle)))
+	if item < 0 {
+		return session.NativeError(d.handle, "decompose molecule")
+	}
+	defer C.indigoFree(C.int(item))
+
+	iterHandle := int(C.indigoIterateDecompositions(C.int(item)))
+	if iterHandle < 0 {
+		return session.NativeError(d.handle, "iterate decompositions")
+	}
+	defer C.indigoFree(C.int(iterHandle))
+
+	match := int(C.indigoNext(C.int(iterHandle)))
+	if match < 0 {
+		return session.NativeError(d.handle, "get decomposition")
+	}
+	if match == 0 {
+		return indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "scaffold not found in molecule")
+	}
+	defer C.indigoFree(C.int(match))
+
+	if ret := int(C.indigoAddDecomposition(C.int(d.handle), C.int(match))); ret < 0 {
+		return session.NativeError(d.handle, "add decomposition")
+	}
+
+	return readDecomposition(match, res, d.session)
+}
+
+// DecomposeMolecules decomposes all molecules at once against a common scaffold
+// Returns the full scaffold with R-sites and one decomposition per molecule
+func DecomposeMolecules(scaffold *Molecule, mols []*Molecule) (*Molecule, []RGroupDecomposition, error) {
+	if scaffold == nil || scaffold.Closed {
+		return nil, nil, indigoerr.Closed("scaffold molecule")
+	}
+	s, err := commonSession(append([]*Molecule{scaffold}, mols...))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := session.Enter(s); err != nil {
+		return nil, nil, err
+	}
+	defer session.Exit()
+
+	arr, err := newMoleculeArray(mols)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer C.indigoFree(C.int(arr))
+
+	decomp := int(C.indigoDecomposeMolecules(C.int(scaffold.Handle), C.int(arr)))
+	if decomp < 0 {
+		return nil, nil, session.NativeError(0, "decompose molecules")
+	}
+	defer C.indigoFree(C.int(decomp))
+
+	full, err := cloneResult(int(C.indigoDecomposedMoleculeScaffold(C.int(decomp))), "full scaffold", s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iterHandle := int(C.indigoIterateDecomposedMolecules(C.int(decomp)))
+	if iterHandle < 0 {
+		full.Close()
+		return nil, nil, session.NativeError(0, "iterate decomposed molecules")
+	}
+
+	var results []RGroupDecomposition
+	err = iterateHandles(iterHandle, func(item int) error {
+		res := RGroupDecomposition{Index: len(results)}
+		res.Err = readDecomposition(item, &res, s)
+		results = append(results, res)
+		return nil
+	})
+	if err != nil {
+		full.Close()
+		return nil, nil, err
+	}
+
+	return full, results, nil
+}
+
+// Close frees the scaffold and highlighted molecules of the decomposition
+func (r *RGroupDecomposition) Close() {
+	if r.Scaffold != nil {
+		r.Scaffold.Close()
+	}
+	if r.Highlighted != nil {
+		r.Highlighted.Close()
+	}
+}
+
+// ExtractCommonScaffold finds the maximum common scaffold of the molecules
+// options: "exact", "approx" or "approx <iterations>"
+// The result can be passed to AllScaffolds to get every scaffold found
+func ExtractCommonScaffold(mols []*Molecule, options string) (*Molecule, error) {
+	s, err := commonSession(mols)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Enter(s); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	arr, err := newMoleculeArray(mols)
+	if err != nil {
+		return nil, err
+	}
+	defer C.indigoFree(C.int(arr))
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	handle := int(C.indigoExtractCommonScaffold(C.int(arr), cOptions))
+	if handle < 0 {
+		return nil, session.NativeError(0, "extract common scaffold")
+	}
+	if handle == 0 {
+		return nil, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "no common scaffold found")
+	}
+
+	return newMolecule(handle, s), nil
+}
+
+// AllScaffolds returns every scaffold found by ExtractCommonScaffold
+func AllScaffolds(extracted *Molecule) ([]*Molecule, error) {
+	if extracted.Closed {
+		return nil, indigoerr.Closed("scaffold")
+	}
+	if err := extracted.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	arr := int(C.indigoAllScaffolds(C.int(extracted.Handle)))
+	if arr < 0 {
+		return nil, session.NativeError(0, "get all scaffolds")
+	}
+	defer C.indigoFree(C.int(arr))
+
+	iterHandle := int(C.indigoIterateArray(C.int(arr)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(0, "iterate scaffolds")
+	}
+
+	var scaffolds []*Molecule
+	err := iterateHandles(iterHandle, func(item int) error {
+		// item belongs to the iterator, which frees it
+		scaffold, err := cloneItem(item, "scaffold", extracted.session)
+		if err != nil {
+			return err
+		}
+		scaffolds = append(scaffolds, scaffold)
+		return nil
+	})
+	if err != nil {
+		for _, s := range scaffolds {
+			s.Close()
+		}
+		return nil, err
+	}
+
+	return scaffolds, nil
+}
+
+// readDecomposition fills the scaffold, R-groups and highlighted molecule of a decomposition item
+func readDecomposition(item int, res *RGroupDecomposition, s *session.Session) error {
+	withRGroups := int(C.indigoDecomposedMoleculeWithRGroups(C.int(item)))
+	if withRGroups < 0 {
+		return session.NativeError(0, "get molecule with R-groups")
+	}
+	defer C.indigoFree(C.int(withRGroups))
+
+	rgroups, err := rgroupFragments(withRGroups)
+	if err != nil {
+		return err
+	}
+
+	scaffold, err := cloneResult(int(C.indigoDecomposedMoleculeScaffold(C.int(item))), "scaffold", s)
+	if err != nil {
+		return err
+	}
+
+	highlighted, err := cloneResult(int(C.indigoDecomposedMoleculeHighlighted(C.int(item))), "highlighted molecule", s)
+	if err != nil {
+		scaffold.Close()
+		return err
+	}
+
+	res.Scaffold = scaffold
+	res.Highlighted = highlighted
+	res.RGroups = rgroups
+	return nil
+}
+
+// rgroupFragments maps each R-group number to the SMILES of its fragments
+// Several fragments of one R-group are joined with '.'
+func rgroupFragments(mol int) (map[int]string, error) {
+	iterHandle := int(C.indigoIterateRGroups(C.int(mol)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(0, "iterate R-groups")
+	}
+
+	rgroups := make(map[int]string)
+	err := iterateHandles(iterHandle, func(rgroup int) error {
+		number := int(C.indigoIndex(C.int(rgroup)))
+		if number < 0 {
+			return session.NativeError(0, "get R-group number")
+		}
+
+		fragIter := int(C.indigoIterateRGroupFragments(C.int(rgroup)))
+		if fragIter < 0 {
+			return session.NativeError(0, "iterate R-group fragments")
+		}
+
+		var smiles []string
+		err := iterateHandles(fragIter, func(frag int) error {
+			cStr := C.indigoSmiles(C.int(frag))
+			if cStr == nil {
+				return session.NativeError(0, "get fragment SMILES")
+			}
+			smiles = append(smiles, C.GoString(cStr))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(smiles) > 0 {
+			rgroups[number] = strings.Join(smiles, ".")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rgroups, nil
+}
+
+// cloneResult clones a molecule returned by a decomposition call and frees the original
+func cloneResult(handle int, what string, s *session.Session) (*Molecule, error) {
+	if handle < 0 {
+		return nil, session.NativeError(0, "get %s", what)
+	}
+	defer C.indigoFree(C.int(handle))
+
+	return cloneItem(handle, what, s)
+}
+
+// cloneItem clones a molecule owned by someone else, e.g. an iterator element, without freeing it
+func cloneItem(handle int, what string, s *session.Session) (*Molecule, error) {
+	clone := int(C.indigoClone(C.int(handle)))
+	if clone < 0 {
+		return nil, session.NativeError(0, "clone %s", what)
+	}
+
+	return newMolecule(clone, s), nil
+}
+
+// newMoleculeArray creates an Indigo array holding copies of the molecules
+// The caller must free the returned handle
+func newMoleculeArray(mols []*Molecule) (int, error) {
+	arr := int(C.indigoCreateArray())
+	if arr < 0 {
+		return 0, session.NativeError(0, "create array")
+	}
+
+	for i, mol := range mols {
+		if mol == nil || mol.Closed {
+			C.indigoFree(C.int(arr))
+			return 0, indigoerr.New(indigoerr.CATEGORY_CLOSED, "molecule %d is closed", i)
+		}
+		if ret := int(C.indigoArrayAdd(C.int(arr), C.int(mol.Handle))); ret < 0 {
+			C.indigoFree(C.int(arr))
+			return 0, session.NativeError(0, "add molecule %d to array", i)
+		}
+	}
+
+	return arr, nil
+}
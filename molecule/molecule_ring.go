@@ -0,0 +1,333 @@
+// Package molecule provides ring perception functionality using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_ring.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"sort"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Ring is a ring of a molecule given by the indices of its atoms and bonds
+type Ring struct {
+	Atoms    []int
+	Bonds    []int
+	Aromatic bool // true if every ring bond is aromatic once the molecule is aromatized
+}
+
+// Subtree is an acyclic fragment of a molecule given by the indices of its atoms and bonds
+type Subtree struct {
+	Atoms []int
+	Bonds []int
+}
+
+// RingSystem is a group of rings fused together by shared bonds
+type RingSystem struct {
+	Atoms         []int // sorted atom indices of all rings in the system
+	Bonds         []int // sorted bond indices of all rings in the system
+	Rings         []Ring
+	AromaticRings int  // number of aromatic rings in the system
+	Aromatic      bool // true if every ring of the system is aromatic
+}
+
+// SSSR returns the smallest set of smallest rings
+// Aromaticity is checked on an aromatized copy, so Kekule input gives the same result and the molecule is left unchanged
+func (m *Molecule) SSSR() ([]Ring, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateSSSR(C.int(m.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate SSSR")
+	}
+
+	return m.collectRings(iterHandle)
+}
+
+// Rings returns all rings with a size between minSize and maxSize atoms
+func (m *Molecule) Rings(minSize int, maxSize int) ([]Ring, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateRings(C.int(m.Handle), C.int(minSize), C.int(maxSize)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate rings")
+	}
+
+	return m.collectRings(iterHandle)
+}
+
+// Subtrees returns all acyclic fragments with a size between minAtoms and maxAtoms atoms
+func (m *Molecule) Subtrees(minAtoms int, maxAtoms int) ([]Subtree, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateSubtrees(C.int(m.Handle), C.int(minAtoms), C.int(maxAtoms)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate subtrees")
+	}
+
+	var subtrees []Subtree
+	err := iterateHandles(iterHandle, func(item int) error {
+		atoms, bonds, err := submoleculeIndices(item)
+		if err != nil {
+			return err
+		}
+		subtrees = append(subtrees, Subtree{Atoms: atoms, Bonds: bonds})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subtrees, nil
+}
+
+// RingSystems groups the SSSR rings into fused ring systems
+// Rings sharing at least one bond belong to the same system; spiro rings form separate systems
+func (m *Molecule) RingSystems() ([]RingSystem, error) {
+	rings, err := m.SSSR()
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over rings, joining rings that share a bond
+	parent := make([]int, len(rings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	bondOwner := make(map[int]int)
+	for i, ring := range rings {
+		for _, bond := range ring.Bonds {
+			if j, ok := bondOwner[bond]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				bondOwner[bond] = i
+			}
+		}
+	}
+
+	var systems []RingSystem
+	systemIndex := make(map[int]int)
+	for i, ring := range rings {
+		root := find(i)
+		idx, ok := systemIndex[root]
+		if !ok {
+			idx = len(systems)
+			systemIndex[root] = idx
+			systems = append(systems, RingSystem{Aromatic: true})
+		}
+
+		system := &systems[idx]
+		system.Rings = append(system.Rings, ring)
+		system.Atoms = appendUnique(system.Atoms, ring.Atoms)
+		system.Bonds = appendUnique(system.Bonds, ring.Bonds)
+		if ring.Aromatic {
+			system.AromaticRings++
+		} else {
+			system.Aromatic = false
+		}
+	}
+
+	for i := range systems {
+		sort.Ints(systems[i].Atoms)
+		sort.Ints(systems[i].Bonds)
+	}
+
+	return systems, nil
+}
+
+// collectRings reads every ring of a ring iterator and checks its aromaticity
+func (m *Molecule) collectRings(iterHandle int) ([]Ring, error) {
+	var rings []Ring
+	err := iterateHandles(iterHandle, func(item int) error {
+		atoms, bonds, err := submoleculeIndices(item)
+		if err != nil {
+			return err
+		}
+
+		rings = append(rings, Ring{Atoms: atoms, Bonds: bonds})
+		return nil
+	})
+	if err != nil || len(rings) == 0 {
+		return rings, err
+	}
+
+	aromatic, err := m.aromaticBonds()
+	if err != nil {
+		return nil, err
+	}
+	for i := range rings {
+		rings[i].Aromatic = allBondsAromatic(rings[i].Bonds, aromatic)
+	}
+
+	return rings, nil
+}
+
+// aromaticBonds returns the indices of the bonds that are aromatic once the molecule is aromatized
+// A clone is aromatized; its bonds are matched to the bonds of m by iteration order,
+// since cloning renumbers the bonds when removed ones left gaps in the indices
+func (m *Molecule) aromaticBonds() (map[int]bool, error) {
+	clone := int(C.indigoClone(C.int(m.Handle)))
+	if clone < 0 {
+		return nil, session.NativeError(m.Handle, "clone molecule")
+	}
+	defer C.indigoFree(C.int(clone))
+
+	if int(C.indigoAromatize(C.int(clone))) < 0 {
+		return nil, session.NativeError(m.Handle, "aromatize molecule")
+	}
+
+	indices, _, err := bondOrders(m.Handle)
+	if err != nil {
+		return nil, err
+	}
+	_, orders, err := bondOrders(clone)
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) != len(orders) {
+		return nil, indigoerr.New(indigoerr.CATEGORY_INTERNAL, "aromatized copy has %d bonds instead of %d", len(orders), len(indices))
+	}
+
+	aromatic := make(map[int]bool)
+	for i, idx := range indices {
+		if orders[i] == BOND_AROMATIC {
+			aromatic[idx] = true
+		}
+	}
+	return aromatic, nil
+}
+
+// bondOrders returns the indices and orders of the bonds of a molecule in iteration order
+func bondOrders(handle int) ([]int, []int, error) {
+	iterHandle := int(C.indigoIterateBonds(C.int(handle)))
+	if iterHandle < 0 {
+		return nil, nil, session.NativeError(handle, "iterate bonds")
+	}
+
+	var indices, orders []int
+	err := iterateHandles(iterHandle, func(bond int) error {
+		idx := int(C.indigoIndex(C.int(bond)))
+		if idx < 0 {
+			return session.NativeError(handle, "get bond index")
+		}
+		order := int(C.indigoBondOrder(C.int(bond)))
+		if order < 0 {
+			return session.NativeError(handle, "get bond order")
+		}
+
+		indices = append(indices, idx)
+		orders = append(orders, order)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return indices, orders, nil
+}
+
+// allBondsAromatic checks if all bonds with the given indices are in the aromatic set
+func allBondsAromatic(bonds []int, aromatic map[int]bool) bool {
+	if len(bonds) == 0 {
+		return false
+	}
+
+	for _, idx := range bonds {
+		if !aromatic[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// submoleculeIndices returns the atom and bond indices of a submolecule in its parent molecule
+func submoleculeIndices(submol int) ([]int, []int, error) {
+	atomsIter := int(C.indigoIterateAtoms(C.int(submol)))
+	if atomsIter < 0 {
+		return nil, nil, session.NativeError(0, "iterate submolecule atoms")
+	}
+
+	var atoms []int
+	err := iterateHandles(atomsIter, func(atom int) error {
+		atoms = append(atoms, int(C.indigoIndex(C.int(atom))))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bondsIter := int(C.indigoIterateBonds(C.int(submol)))
+	if bondsIter < 0 {
+		return nil, nil, session.NativeError(0, "iterate submolecule bonds")
+	}
+
+	var bonds []int
+	err = iterateHandles(bondsIter, func(bond int) error {
+		bonds = append(bonds, int(C.indigoIndex(C.int(bond))))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return atoms, bonds, nil
+}
+
+// appendUnique appends the values not already present in dst
+func appendUnique(dst []int, values []int) []int {
+	for _, v := range values {
+		found := false
+		for _, d := range dst {
+			if d == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
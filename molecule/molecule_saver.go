@@ -28,19 +28,21 @@ package molecule
 */
 import "C"
 import (
-	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // ToSmiles converts the molecule to SMILES format
 func (m *Molecule) ToSmiles() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoSmiles(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to SMILES: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to SMILES")
 	}
 
 	return C.GoString(cStr), nil
@@ -48,13 +50,14 @@ func (m *Molecule) ToSmiles() (string, error) {
 
 // ToCanonicalSmiles converts the molecule to canonical SMILES format
 func (m *Molecule) ToCanonicalSmiles() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCanonicalSmiles(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to canonical SMILES: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to canonical SMILES")
 	}
 
 	return C.GoString(cStr), nil
@@ -62,13 +65,14 @@ func (m *Molecule) ToCanonicalSmiles() (string, error) {
 
 // ToSmarts converts the molecule to SMARTS format
 func (m *Molecule) ToSmarts() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoSmarts(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to SMARTS: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to SMARTS")
 	}
 
 	return C.GoString(cStr), nil
@@ -76,13 +80,14 @@ func (m *Molecule) ToSmarts() (string, error) {
 
 // ToCanonicalSmarts converts the molecule to canonical SMARTS format
 func (m *Molecule) ToCanonicalSmarts() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCanonicalSmarts(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to canonical SMARTS: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to canonical SMARTS")
 	}
 
 	return C.GoString(cStr), nil
@@ -90,13 +95,14 @@ func (m *Molecule) ToCanonicalSmarts() (string, error) {
 
 // ToMolfile returns the molecule as a MOL file string
 func (m *Molecule) ToMolfile() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoMolfile(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to MOL: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to MOL")
 	}
 
 	return C.GoString(cStr), nil
@@ -104,16 +110,17 @@ func (m *Molecule) ToMolfile() (string, error) {
 
 // SaveToFile saves the molecule to a file in MOL format
 func (m *Molecule) SaveToFile(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveMolfileToFile(C.int(m.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save to file %s: %s", filename, getLastError())
+		return session.NativeError(m.Handle, "save to file %s", filename)
 	}
 
 	return nil
@@ -122,13 +129,14 @@ func (m *Molecule) SaveToFile(filename string) error {
 // SaveMolfile saves the molecule to an output object
 // outputHandle is the Indigo handle of an output object
 func (m *Molecule) SaveMolfile(outputHandle int) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoSaveMolfile(C.int(m.Handle), C.int(outputHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to save to output: %s", getLastError())
+		return session.NativeError(m.Handle, "save to output")
 	}
 
 	return nil
@@ -136,27 +144,28 @@ func (m *Molecule) SaveMolfile(outputHandle int) error {
 
 // ToJSON converts the molecule to JSON format
 func (m *Molecule) ToJSON() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a string output buffer
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(m.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
 	// Save as JSON
 	ret := int(C.indigoSaveJson(C.int(m.Handle), C.int(bufferHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to save as JSON: %s", getLastError())
+		return "", session.NativeError(m.Handle, "save as JSON")
 	}
 
 	// Get the string
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get JSON string: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get JSON string")
 	}
 
 	return C.GoString(cStr), nil
@@ -164,16 +173,17 @@ func (m *Molecule) ToJSON() (string, error) {
 
 // SaveToJSONFile saves the molecule to a file in JSON format
 func (m *Molecule) SaveToJSONFile(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveJsonToFile(C.int(m.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save to JSON file %s: %s", filename, getLastError())
+		return session.NativeError(m.Handle, "save to JSON file %s", filename)
 	}
 
 	return nil
@@ -181,13 +191,14 @@ func (m *Molecule) SaveToJSONFile(filename string) error {
 
 // ToBase64String converts the molecule to base64 string
 func (m *Molecule) ToBase64String() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoToBase64String(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to base64: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to base64")
 	}
 
 	return C.GoString(cStr), nil
@@ -195,9 +206,10 @@ func (m *Molecule) ToBase64String() (string, error) {
 
 // ToCXSmiles converts the molecule to ChemAxon Extended SMILES format
 func (m *Molecule) ToCXSmiles() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Set the SMILES saving format to chemaxon
 	cOption := C.CString("smiles-saving-format")
@@ -209,7 +221,7 @@ func (m *Molecule) ToCXSmiles() (string, error) {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to set chemaxon format: %s", getLastError())
+		return "", session.NativeError(m.Handle, "set chemaxon format")
 	}
 
 	// Reset to default after getting the SMILES
@@ -217,7 +229,7 @@ func (m *Molecule) ToCXSmiles() (string, error) {
 
 	cStr := C.indigoSmiles(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to CXSmiles: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to CXSmiles")
 	}
 
 	return C.GoString(cStr), nil
@@ -226,15 +238,16 @@ func (m *Molecule) ToCXSmiles() (string, error) {
 // ToCanonicalCXSmiles converts the molecule to canonical ChemAxon Extended SMILES format
 // Note: According to Indigo API, canonical SMILES already includes ChemAxon extensions
 func (m *Molecule) ToCanonicalCXSmiles() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// For canonical CXSMILES, we use canonicalSmiles directly
 	// The canonical SMILES in Indigo automatically includes ChemAxon extensions
 	cStr := C.indigoCanonicalSmiles(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to canonical CXSmiles: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to canonical CXSmiles")
 	}
 
 	return C.GoString(cStr), nil
@@ -242,13 +255,14 @@ func (m *Molecule) ToCanonicalCXSmiles() (string, error) {
 
 // ToCML converts the molecule to CML (Chemical Markup Language) format
 func (m *Molecule) ToCML() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCml(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to CML: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to CML")
 	}
 
 	return C.GoString(cStr), nil
@@ -256,13 +270,14 @@ func (m *Molecule) ToCML() (string, error) {
 
 // ToCDXML converts the molecule to CDXML format
 func (m *Molecule) ToCDXML() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCdxml(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to CDXML: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to CDXML")
 	}
 
 	return C.GoString(cStr), nil
@@ -270,27 +285,28 @@ func (m *Molecule) ToCDXML() (string, error) {
 
 // ToCDXBase64 converts the molecule to base64-encoded CDX format
 func (m *Molecule) ToCDXBase64() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a buffer for CDX
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(m.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
 	// Save as CDX
 	ret := int(C.indigoSaveCdx(C.int(m.Handle), C.int(bufferHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to save as CDX: %s", getLastError())
+		return "", session.NativeError(m.Handle, "save as CDX")
 	}
 
 	// Convert to base64
 	cStr := C.indigoToBase64String(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert CDX to base64: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert CDX to base64")
 	}
 
 	return C.GoString(cStr), nil
@@ -298,9 +314,10 @@ func (m *Molecule) ToCDXBase64() (string, error) {
 
 // SaveToSDF saves the molecule components to an SDF file
 func (m *Molecule) SaveToSDF(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
@@ -308,7 +325,7 @@ func (m *Molecule) SaveToSDF(filename string) error {
 	// Create a file output
 	outputHandle := int(C.indigoWriteFile(cFilename))
 	if outputHandle < 0 {
-		return fmt.Errorf("failed to create output file: %s", getLastError())
+		return session.NativeError(m.Handle, "create output file")
 	}
 	defer C.indigoFree(C.int(outputHandle))
 
@@ -318,40 +335,40 @@ func (m *Molecule) SaveToSDF(filename string) error {
 
 	saverHandle := int(C.indigoCreateSaver(C.int(outputHandle), cFormat))
 	if saverHandle < 0 {
-		return fmt.Errorf("failed to create SDF saver: %s", getLastError())
+		return session.NativeError(m.Handle, "create SDF saver")
 	}
 	defer C.indigoFree(C.int(saverHandle))
 
 	// Iterate through components and save each
 	iterHandle := int(C.indigoIterateComponents(C.int(m.Handle)))
 	if iterHandle < 0 {
-		return fmt.Errorf("failed to iterate components: %s", getLastError())
+		return session.NativeError(m.Handle, "iterate components")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
 	for C.indigoHasNext(C.int(iterHandle)) != 0 {
 		compHandle := int(C.indigoNext(C.int(iterHandle)))
 		if compHandle < 0 {
-			return fmt.Errorf("failed to get next component: %s", getLastError())
+			return session.NativeError(m.Handle, "get next component")
 		}
 
 		cloneHandle := int(C.indigoClone(C.int(compHandle)))
 		if cloneHandle < 0 {
-			return fmt.Errorf("failed to clone component: %s", getLastError())
+			return session.NativeError(m.Handle, "clone component")
 		}
 
 		// Use indigoAppend instead of indigoSdfAppend (following Python API pattern)
 		ret := int(C.indigoAppend(C.int(saverHandle), C.int(cloneHandle)))
 		C.indigoFree(C.int(cloneHandle))
 		if ret < 0 {
-			return fmt.Errorf("failed to append to SDF: %s", getLastError())
+			return session.NativeError(m.Handle, "append to SDF")
 		}
 	}
 
 	// Close the saver
 	ret := int(C.indigoClose(C.int(saverHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to close SDF saver: %s", getLastError())
+		return session.NativeError(m.Handle, "close SDF saver")
 	}
 
 	return nil
@@ -359,14 +376,15 @@ func (m *Molecule) SaveToSDF(filename string) error {
 
 // ToSDF converts the molecule to SDF format string
 func (m *Molecule) ToSDF() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a buffer for SDF
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(m.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
@@ -376,46 +394,46 @@ func (m *Molecule) ToSDF() (string, error) {
 
 	saverHandle := int(C.indigoCreateSaver(C.int(bufferHandle), cFormat))
 	if saverHandle < 0 {
-		return "", fmt.Errorf("failed to create SDF saver: %s", getLastError())
+		return "", session.NativeError(m.Handle, "create SDF saver")
 	}
 	defer C.indigoFree(C.int(saverHandle))
 
 	// Iterate through components and save each
 	iterHandle := int(C.indigoIterateComponents(C.int(m.Handle)))
 	if iterHandle < 0 {
-		return "", fmt.Errorf("failed to iterate components: %s", getLastError())
+		return "", session.NativeError(m.Handle, "iterate components")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
 	for C.indigoHasNext(C.int(iterHandle)) != 0 {
 		compHandle := int(C.indigoNext(C.int(iterHandle)))
 		if compHandle < 0 {
-			return "", fmt.Errorf("failed to get next component: %s", getLastError())
+			return "", session.NativeError(m.Handle, "get next component")
 		}
 
 		cloneHandle := int(C.indigoClone(C.int(compHandle)))
 		if cloneHandle < 0 {
-			return "", fmt.Errorf("failed to clone component: %s", getLastError())
+			return "", session.NativeError(m.Handle, "clone component")
 		}
 
 		// Use indigoAppend instead of indigoSdfAppend (following Python API pattern)
 		ret := int(C.indigoAppend(C.int(saverHandle), C.int(cloneHandle)))
 		C.indigoFree(C.int(cloneHandle))
 		if ret < 0 {
-			return "", fmt.Errorf("failed to append to SDF: %s", getLastError())
+			return "", session.NativeError(m.Handle, "append to SDF")
 		}
 	}
 
 	// Close the saver
 	ret := int(C.indigoClose(C.int(saverHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to close SDF saver: %s", getLastError())
+		return "", session.NativeError(m.Handle, "close SDF saver")
 	}
 
 	// Get the string
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get SDF string: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get SDF string")
 	}
 
 	return C.GoString(cStr), nil
@@ -423,16 +441,17 @@ func (m *Molecule) ToSDF() (string, error) {
 
 // SaveToCMLFile saves the molecule to a file in CML format
 func (m *Molecule) SaveToCMLFile(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveCmlToFile(C.int(m.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save to CML file %s: %s", filename, getLastError())
+		return session.NativeError(m.Handle, "save to CML file %s", filename)
 	}
 
 	return nil
@@ -440,16 +459,17 @@ func (m *Molecule) SaveToCMLFile(filename string) error {
 
 // SaveToCDXMLFile saves the molecule to a file in CDXML format
 func (m *Molecule) SaveToCDXMLFile(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveCdxmlToFile(C.int(m.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save to CDXML file %s: %s", filename, getLastError())
+		return session.NativeError(m.Handle, "save to CDXML file %s", filename)
 	}
 
 	return nil
@@ -457,16 +477,17 @@ func (m *Molecule) SaveToCDXMLFile(filename string) error {
 
 // SaveToCDXFile saves the molecule to a file in CDX format
 func (m *Molecule) SaveToCDXFile(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveCdxToFile(C.int(m.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save to CDX file %s: %s", filename, getLastError())
+		return session.NativeError(m.Handle, "save to CDX file %s", filename)
 	}
 
 	return nil
@@ -475,9 +496,10 @@ func (m *Molecule) SaveToCDXFile(filename string) error {
 // ToDaylightSmiles converts the molecule to Daylight SMILES format
 // This explicitly uses the Daylight format (as opposed to ChemAxon)
 func (m *Molecule) ToDaylightSmiles() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Explicitly set Daylight format
 	cOption := C.CString("smiles-saving-format")
@@ -487,12 +509,12 @@ func (m *Molecule) ToDaylightSmiles() (string, error) {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to set daylight format: %s", getLastError())
+		return "", session.NativeError(m.Handle, "set daylight format")
 	}
 
 	cStr := C.indigoSmiles(C.int(m.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert to Daylight SMILES: %s", getLastError())
+		return "", session.NativeError(m.Handle, "convert to Daylight SMILES")
 	}
 
 	return C.GoString(cStr), nil
@@ -501,14 +523,15 @@ func (m *Molecule) ToDaylightSmiles() (string, error) {
 // ToRDF converts the molecule to RDF (Reaction Data Format) format string
 // Note: This is typically used for reactions, but can work with molecules
 func (m *Molecule) ToRDF() (string, error) {
-	if m.Closed {
-		return "", fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a buffer for RDF
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(m.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
@@ -518,26 +541,26 @@ func (m *Molecule) ToRDF() (string, error) {
 
 	saverHandle := int(C.indigoCreateSaver(C.int(bufferHandle), cFormat))
 	if saverHandle < 0 {
-		return "", fmt.Errorf("failed to create RDF saver: %s", getLastError())
+		return "", session.NativeError(m.Handle, "create RDF saver")
 	}
 	defer C.indigoFree(C.int(saverHandle))
 
 	// Append the molecule
 	ret := int(C.indigoAppend(C.int(saverHandle), C.int(m.Handle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to append to RDF: %s", getLastError())
+		return "", session.NativeError(m.Handle, "append to RDF")
 	}
 
 	// Close the saver
 	ret = int(C.indigoClose(C.int(saverHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to close RDF saver: %s", getLastError())
+		return "", session.NativeError(m.Handle, "close RDF saver")
 	}
 
 	// Get the string
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get RDF string: %s", getLastError())
+		return "", session.NativeError(m.Handle, "get RDF string")
 	}
 
 	return C.GoString(cStr), nil
@@ -545,9 +568,10 @@ func (m *Molecule) ToRDF() (string, error) {
 
 // SaveToRDFFile saves the molecule to a file in RDF format
 func (m *Molecule) SaveToRDFFile(filename string) error {
-	if m.Closed {
-		return fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
@@ -555,7 +579,7 @@ func (m *Molecule) SaveToRDFFile(filename string) error {
 	// Create a file output
 	outputHandle := int(C.indigoWriteFile(cFilename))
 	if outputHandle < 0 {
-		return fmt.Errorf("failed to create output file: %s", getLastError())
+		return session.NativeError(m.Handle, "create output file")
 	}
 	defer C.indigoFree(C.int(outputHandle))
 
@@ -565,20 +589,20 @@ func (m *Molecule) SaveToRDFFile(filename string) error {
 
 	saverHandle := int(C.indigoCreateSaver(C.int(outputHandle), cFormat))
 	if saverHandle < 0 {
-		return fmt.Errorf("failed to create RDF saver: %s", getLastError())
+		return session.NativeError(m.Handle, "create RDF saver")
 	}
 	defer C.indigoFree(C.int(saverHandle))
 
 	// Append the molecule
 	ret := int(C.indigoAppend(C.int(saverHandle), C.int(m.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to append to RDF: %s", getLastError())
+		return session.NativeError(m.Handle, "append to RDF")
 	}
 
 	// Close the saver
 	ret = int(C.indigoClose(C.int(saverHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to close RDF saver: %s", getLastError())
+		return session.NativeError(m.Handle, "close RDF saver")
 	}
 
 	return nil
@@ -586,27 +610,28 @@ func (m *Molecule) SaveToRDFFile(filename string) error {
 
 // ToBuffer converts the molecule to a binary buffer
 func (m *Molecule) ToBuffer() ([]byte, error) {
-	if m.Closed {
-		return nil, fmt.Errorf("molecule is closed")
+	if err := m.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	// Create a buffer
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return nil, fmt.Errorf("failed to create buffer: %s", getLastError())
+		return nil, session.NativeError(m.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
 	// Save molecule to buffer
 	ret := int(C.indigoSaveMolfile(C.int(m.Handle), C.int(bufferHandle)))
 	if ret < 0 {
-		return nil, fmt.Errorf("failed to save to buffer: %s", getLastError())
+		return nil, session.NativeError(m.Handle, "save to buffer")
 	}
 
 	// Get the buffer content
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return nil, fmt.Errorf("failed to get buffer content: %s", getLastError())
+		return nil, session.NativeError(m.Handle, "get buffer content")
 	}
 
 	return []byte(C.GoString(cStr)), nil
@@ -0,0 +1,416 @@
+// Package molecule provides a configurable standardization pipeline
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_standardizer.go
+// @Software: GoLand
+package molecule
+
+import (
+	"fmt"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+// StepKind identifies a standardization step
+type StepKind string
+
+// Standardization steps
+const (
+	STEP_NORMALIZE          StepKind = "normalize"          // indigoNormalize with the step options
+	STEP_STANDARDIZE        StepKind = "standardize"        // indigoStandardize
+	STEP_NEUTRALIZE         StepKind = "neutralize"         // neutralize charges by adding or removing hydrogens
+	STEP_FOLD_HYDROGENS     StepKind = "fold-hydrogens"     // turn explicit hydrogens into implicit ones
+	STEP_UNFOLD_HYDROGENS   StepKind = "unfold-hydrogens"   // turn implicit hydrogens into explicit atoms
+	STEP_AROMATIZE          StepKind = "aromatize"          // aromatize rings
+	STEP_DEAROMATIZE        StepKind = "dearomatize"        // convert aromatic rings to Kekule form
+	STEP_STRIP_SALTS        StepKind = "strip-salts"        // remove salt and solvent components
+	STEP_CANONICAL_TAUTOMER StepKind = "canonical-tautomer" // replace with the canonical tautomer
+	STEP_CLEAR_STEREO       StepKind = "clear-stereo"       // remove stereocenters and cis/trans bonds
+)
+
+// StandardizeStep is a single step of a Standardizer
+// Use the step constructors, such as NormalizeStep or StripSaltsStep, to create steps
+type StandardizeStep struct {
+	Kind    StepKind
+	Options string        // options of STEP_NORMALIZE and STEP_CANONICAL_TAUTOMER
+	Salts   *SaltStripper // dictionary of STEP_STRIP_SALTS
+}
+
+// StepResult describes the effect of one step
+type StepResult struct {
+	Step    StepKind
+	Before  string   // canonical SMILES before the step
+	After   string   // canonical SMILES after the step
+	Changed bool     // true if the canonical SMILES changed
+	Removed []string // salt entries removed by STEP_STRIP_SALTS
+}
+
+// StandardizeReport is the audit trail of a standardization run
+type StandardizeReport struct {
+	Input  string // canonical SMILES of the input
+	Output string // canonical SMILES of the result
+	Steps  []StepResult
+}
+
+// Standardizer applies an ordered list of steps to molecules
+//
+//	s, err := molecule.NewStandardizer(
+//		molecule.StripSaltsStep(nil),
+//		molecule.NeutralizeStep(),
+//		molecule.CanonicalTautomerStep(""),
+//	)
+//	parent, report, err := s.Run(mol)
+//	for _, step := range report.ChangedSteps() { ... }
+type Standardizer struct {
+	steps []StandardizeStep
+}
+
+// NormalizeStep runs indigoNormalize with the given options; "" uses the defaults
+func NormalizeStep(options string) StandardizeStep {
+	return StandardizeStep{Kind: STEP_NORMALIZE, Options: options}
+}
+
+// IndigoStandardizeStep runs indigoStandardize, configured by the session standardize-* options
+func IndigoStandardizeStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_STANDARDIZE}
+}
+
+// NeutralizeStep neutralizes charges by adding or removing hydrogens
+// Only N, P, O, S, Se and halogen atoms that reach their normal valence are neutralized, so metal ions and
+// hypervalent centres such as tetrafluoroborate keep their charge. Charge-separated groups such as nitro or
+// N-oxides are kept, and negative charges balancing a positive charge that is kept (e.g. quaternary ammonium) are kept
+func NeutralizeStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_NEUTRALIZE}
+}
+
+// FoldHydrogensStep turns explicit hydrogens into implicit ones
+func FoldHydrogensStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_FOLD_HYDROGENS}
+}
+
+// UnfoldHydrogensStep turns implicit hydrogens into explicit atoms
+func UnfoldHydrogensStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_UNFOLD_HYDROGENS}
+}
+
+// AromatizeStep aromatizes rings
+func AromatizeStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_AROMATIZE}
+}
+
+// DearomatizeStep converts aromatic rings to Kekule form
+func DearomatizeStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_DEAROMATIZE}
+}
+
+// StripSaltsStep removes the salt and solvent components found in the dictionary
+// A nil stripper uses DEFAULT_SALTS
+func StripSaltsStep(salts *SaltStripper) StandardizeStep {
+	return StandardizeStep{Kind: STEP_STRIP_SALTS, Salts: salts}
+}
+
+// CanonicalTautomerStep replaces the molecule with its canonical tautomer
+func CanonicalTautomerStep(options string) StandardizeStep {
+	return StandardizeStep{Kind: STEP_CANONICAL_TAUTOMER, Options: options}
+}
+
+// ClearStereoStep removes stereocenters and cis/trans bond configurations
+func ClearStereoStep() StandardizeStep {
+	return StandardizeStep{Kind: STEP_CLEAR_STEREO}
+}
+
+// NewStandardizer creates a standardizer running the steps in order
+func NewStandardizer(steps ...StandardizeStep) (*Standardizer, error) {
+	s := &Standardizer{steps: make([]StandardizeStep, len(steps))}
+	copy(s.steps, steps)
+
+	for i, step := range s.steps {
+		switch step.Kind {
+		case STEP_NORMALIZE, STEP_STANDARDIZE, STEP_NEUTRALIZE, STEP_FOLD_HYDROGENS,
+			STEP_UNFOLD_HYDROGENS, STEP_AROMATIZE, STEP_DEAROMATIZE,
+			STEP_CANONICAL_TAUTOMER, STEP_CLEAR_STEREO:
+		case STEP_STRIP_SALTS:
+			if step.Salts == nil {
+				salts, err := DefaultSaltStripper()
+				if err != nil {
+					return nil, err
+				}
+				s.steps[i].Salts = salts
+			}
+		default:
+			return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "unknown standardization step %q", step.Kind)
+		}
+	}
+
+	return s, nil
+}
+
+// Steps returns the steps of the standardizer
+func (s *Standardizer) Steps() []StandardizeStep {
+	steps := make([]StandardizeStep, len(s.steps))
+	copy(steps, s.steps)
+	return steps
+}
+
+// Run standardizes a copy of the molecule and reports the effect of every step
+// The input molecule is not modified; the result is owned by the caller
+func (s *Standardizer) Run(m *Molecule) (*Molecule, *StandardizeReport, error) {
+	current, err := m.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	smiles, err := current.ToCanonicalSmiles()
+	if err != nil {
+		current.Close()
+		return nil, nil, err
+	}
+	report := &StandardizeReport{Input: smiles, Output: smiles}
+
+	for _, step := range s.steps {
+		next, removed, err := step.apply(current)
+		if err != nil {
+			current.Close()
+			return nil, nil, fmt.Errorf("step %s: %w", step.Kind, err)
+		}
+		if next != current {
+			current.Close()
+			current = next
+		}
+
+		after, err := current.ToCanonicalSmiles()
+		if err != nil {
+			current.Close()
+			return nil, nil, fmt.Errorf("step %s: %w", step.Kind, err)
+		}
+
+		report.Steps = append(report.Steps, StepResult{
+			Step:    step.Kind,
+			Before:  report.Output,
+			After:   after,
+			Changed: after != report.Output,
+			Removed: removed,
+		})
+		report.Output = after
+	}
+
+	return current, report, nil
+}
+
+// Changed reports whether the result differs from the input
+func (r *StandardizeReport) Changed() bool {
+	return r.Input != r.Output
+}
+
+// ChangedSteps returns the steps that changed the canonical SMILES, in order
+func (r *StandardizeReport) ChangedSteps() []StepKind {
+	var steps []StepKind
+	for _, step := range r.Steps {
+		if step.Changed {
+			steps = append(steps, step.Step)
+		}
+	}
+	return steps
+}
+
+// apply runs the step, modifying m in place or returning a replacement molecule
+func (step StandardizeStep) apply(m *Molecule) (*Molecule, []string, error) {
+	switch step.Kind {
+	case STEP_NORMALIZE:
+		return m, nil, m.Normalize(step.Options)
+	case STEP_STANDARDIZE:
+		return m, nil, m.Standardize()
+	case STEP_NEUTRALIZE:
+		return m, nil, neutralizeCharges(m)
+	case STEP_FOLD_HYDROGENS:
+		return m, nil, m.FoldHydrogens()
+	case STEP_UNFOLD_HYDROGENS:
+		return m, nil, m.UnfoldHydrogens()
+	case STEP_AROMATIZE:
+		return m, nil, m.Aromatize()
+	case STEP_DEAROMATIZE:
+		return m, nil, m.Dearomatize()
+	case STEP_STRIP_SALTS:
+		result, err := step.Salts.Strip(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.Parent, result.Removed, nil
+	case STEP_CANONICAL_TAUTOMER:
+		tautomer, err := m.CanonicalTautomer(step.Options)
+		return tautomer, nil, err
+	case STEP_CLEAR_STEREO:
+		if err := m.ClearStereocenters(); err != nil {
+			return nil, nil, err
+		}
+		return m, nil, m.ClearCisTrans()
+	}
+
+	return nil, nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "unknown standardization step %q", step.Kind)
+}
+
+// neutralValence is the valence of the neutral atom for the elements neutralized by adding or removing
+// hydrogens; other elements, such as metals or boron, keep their charge
+var neutralValence = map[string]int{
+	"N": 3, "P": 3, "O": 2, "S": 2, "Se": 2,
+	"F": 1, "Cl": 1, "Br": 1, "I": 1,
+}
+
+// chargedAtom is a charged atom considered for neutralization
+type chargedAtom struct {
+	index     int
+	charge    int
+	hydrogens int   // implicit hydrogens
+	explicitH []int // indices of hydrogen atoms bonded to the atom
+	separated bool  // bonded to an oppositely charged atom
+}
+
+// neutralizeCharges removes charges by adjusting hydrogens
+// Only atoms of neutralValence that end up at their normal valence are neutralized,
+// so hypervalent centres such as [B-]F4 or [P-]F6 and metal ions keep their charge
+func neutralizeCharges(m *Molecule) error {
+	count, err := m.CountAtoms()
+	if err != nil {
+		return err
+	}
+
+	symbols := make([]string, count)
+	charges := make([]int, count)
+	for i := 0; i < count; i++ {
+		atom, err := m.GetAtom(i)
+		if err != nil {
+			return err
+		}
+		symbols[i], err = atom.Symbol()
+		if err == nil {
+			charges[i], err = atom.Charge()
+		}
+		atom.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Positive charges that stay, except charge-separated ones, keep their counter-ions
+	var positive, negative []chargedAtom
+	kept := 0
+	for i, charge := range charges {
+		if charge == 0 {
+			continue
+		}
+
+		ca, ok, err := neutralizable(m, i, symbols, charges)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if charge > 0 && !ca.separated {
+				kept += charge
+			}
+			continue
+		}
+
+		if charge > 0 {
+			positive = append(positive, ca)
+		} else {
+			negative = append(negative, ca)
+		}
+	}
+
+	var removed []int
+	for _, ca := range positive {
+		hydrogens := ca.hydrogens - ca.charge
+		if hydrogens < 0 {
+			// Implicit hydrogens do not suffice, explicit ones are removed
+			removed = append(removed, ca.explicitH[:-hydrogens]...)
+			hydrogens = 0
+		}
+		if err := setChargeAndHydrogens(m, ca.index, 0, hydrogens); err != nil {
+			return err
+		}
+	}
+
+	for _, ca := range negative {
+		if kept > 0 {
+			kept += ca.charge
+			continue
+		}
+		if err := setChargeAndHydrogens(m, ca.index, 0, ca.hydrogens-ca.charge); err != nil {
+			return err
+		}
+	}
+
+	// Removing atoms renumbers the others, so it is done last
+	return m.RemoveAtoms(removed)
+}
+
+// neutralizable reports whether the charge of an atom can be removed by adding or removing hydrogens
+// Charge-separated groups, such as nitro, are left as drawn
+func neutralizable(m *Molecule, index int, symbols []string, charges []int) (chargedAtom, bool, error) {
+	ca := chargedAtom{index: index, charge: charges[index]}
+
+	normal, ok := neutralValence[symbols[index]]
+	if !ok {
+		return ca, false, nil
+	}
+
+	atom, err := m.GetAtom(index)
+	if err != nil {
+		return ca, false, err
+	}
+	defer atom.Close()
+
+	neighbors, err := atom.Neighbors()
+	if err != nil {
+		return ca, false, err
+	}
+	defer CloseNeighbors(neighbors)
+	for _, nei := range neighbors {
+		neiIndex, err := nei.Atom.Index()
+		if err != nil {
+			return ca, false, err
+		}
+		if charges[neiIndex]*ca.charge < 0 {
+			ca.separated = true
+			return ca, false, nil
+		}
+		if symbols[neiIndex] == "H" {
+			ca.explicitH = append(ca.explicitH, neiIndex)
+		}
+	}
+
+	valence, err := atom.Valence()
+	if err != nil {
+		return ca, false, err
+	}
+	if valence-ca.charge != normal {
+		return ca, false, nil
+	}
+
+	ca.hydrogens, err = atom.CountImplicitHydrogens()
+	if err != nil {
+		return ca, false, err
+	}
+	if ca.charge > 0 && ca.hydrogens+len(ca.explicitH) < ca.charge {
+		return ca, false, nil
+	}
+
+	return ca, true, nil
+}
+
+// setChargeAndHydrogens sets the charge and implicit hydrogen count of an atom
+func setChargeAndHydrogens(m *Molecule, index int, charge int, hydrogens int) error {
+	atom, err := m.GetAtom(index)
+	if err != nil {
+		return err
+	}
+	defer atom.Close()
+
+	if err := atom.SetCharge(charge); err != nil {
+		return err
+	}
+	return atom.SetImplicitHCount(hydrogens)
+}
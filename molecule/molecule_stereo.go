@@ -0,0 +1,413 @@
+// Package molecule provides stereochemistry functionality using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_stereo.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Stereo type constants (INDIGO_* values from indigo.h)
+const (
+	STEREO_ABS    = 1
+	STEREO_OR     = 2
+	STEREO_AND    = 3
+	STEREO_EITHER = 4
+	STEREO_UP     = 5
+	STEREO_DOWN   = 6
+	STEREO_CIS    = 7
+	STEREO_TRANS  = 8
+)
+
+// cipLabels maps Indigo CIP descriptor codes to their labels
+var cipLabels = map[int]string{
+	2: "s",
+	3: "r",
+	4: "S",
+	5: "R",
+	6: "E",
+	7: "Z",
+}
+
+// Stereocenter describes a tetrahedral stereocenter of a molecule
+type Stereocenter struct {
+	AtomIndex int
+	Type      int    // STEREO_ABS, STEREO_OR, STEREO_AND or STEREO_EITHER
+	Group     int    // enhanced stereo group number for STEREO_OR and STEREO_AND
+	Pyramid   [4]int // atom indices defining the stereocenter pyramid, -1 for implicit H
+	CIP       string // "R", "S", "r", "s" or "" if undetermined
+}
+
+// StereoBond describes a bond carrying stereo information
+type StereoBond struct {
+	BondIndex int
+	Begin     int    // index of the source atom
+	End       int    // index of the destination atom
+	Stereo    int    // STEREO_UP, STEREO_DOWN, STEREO_EITHER, STEREO_CIS or STEREO_TRANS
+	CIP       string // "E", "Z" or "" if undetermined
+}
+
+// CountStereocenters returns the number of stereocenters
+func (m *Molecule) CountStereocenters() (int, error) {
+	if err := m.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
+	count := int(C.indigoCountStereocenters(C.int(m.Handle)))
+	if count < 0 {
+		return 0, session.NativeError(m.Handle, "count stereocenters")
+	}
+
+	return count, nil
+}
+
+// Stereocenters returns all stereocenters of the molecule
+func (m *Molecule) Stereocenters() ([]Stereocenter, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateStereocenters(C.int(m.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate stereocenters")
+	}
+
+	var centers []Stereocenter
+	err := iterateHandles(iterHandle, func(atom int) error {
+		center, err := readStereocenter(atom)
+		if err != nil {
+			return err
+		}
+		centers = append(centers, center)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return centers, nil
+}
+
+// StereoBonds returns all bonds of the molecule carrying stereo information
+// E/Z labels are calculated on a copy, the molecule itself is not modified
+func (m *Molecule) StereoBonds() ([]StereoBond, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	labels, err := bondCIPLabels(m.Handle)
+	if err != nil {
+		return nil, err
+	}
+
+	iterHandle := int(C.indigoIterateBonds(C.int(m.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate bonds")
+	}
+
+	var bonds []StereoBond
+	err = iterateHandles(iterHandle, func(bond int) error {
+		stereo := int(C.indigoBondStereo(C.int(bond)))
+		if stereo < 0 {
+			return session.NativeError(m.Handle, "get bond stereo")
+		}
+		if stereo == 0 {
+			return nil
+		}
+
+		var err error
+		sb := StereoBond{Stereo: stereo}
+		if sb.BondIndex = int(C.indigoIndex(C.int(bond))); sb.BondIndex < 0 {
+			return session.NativeError(m.Handle, "get bond index")
+		}
+		if sb.Begin, err = endpointIndex(int(C.indigoSource(C.int(bond)))); err != nil {
+			return err
+		}
+		if sb.End, err = endpointIndex(int(C.indigoDestination(C.int(bond)))); err != nil {
+			return err
+		}
+		sb.CIP = labels[atomPair(sb.Begin, sb.End)]
+
+		bonds = append(bonds, sb)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bonds, nil
+}
+
+// SetStereocenterGroup assigns an enhanced stereo type and group to a stereocenter
+// stereoType: STEREO_ABS, STEREO_OR, STEREO_AND or STEREO_EITHER
+// group: group number, ignored for STEREO_ABS and STEREO_EITHER
+func (m *Molecule) SetStereocenterGroup(atomIndex int, stereoType int, group int) error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	atom := int(C.indigoGetAtom(C.int(m.Handle), C.int(atomIndex)))
+	if atom < 0 {
+		return session.NativeError(m.Handle, "get atom at index %d", atomIndex)
+	}
+	defer C.indigoFree(C.int(atom))
+
+	ret := int(C.indigoChangeStereocenterType(C.int(atom), C.int(stereoType)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "change stereocenter type")
+	}
+
+	if stereoType == STEREO_OR || stereoType == STEREO_AND {
+		ret = int(C.indigoSetStereocenterGroup(C.int(atom), C.int(group)))
+		if ret < 0 {
+			return session.NativeError(m.Handle, "set stereocenter group")
+		}
+	}
+
+	return nil
+}
+
+// AddCIPDescriptors stores CIP descriptors in the molecule as data S-groups
+func (m *Molecule) AddCIPDescriptors() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoAddCIPStereoDescriptors(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "add CIP descriptors")
+	}
+
+	return nil
+}
+
+// InvertStereo inverts all stereocenters of the molecule
+func (m *Molecule) InvertStereo() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoInvertStereo(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "invert stereo")
+	}
+
+	return nil
+}
+
+// ResetStereo removes all stereo information from the molecule
+func (m *Molecule) ResetStereo() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoResetStereo(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "reset stereo")
+	}
+
+	return nil
+}
+
+// ClearStereocenters removes all tetrahedral stereocenters
+func (m *Molecule) ClearStereocenters() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoClearStereocenters(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "clear stereocenters")
+	}
+
+	return nil
+}
+
+// ClearCisTrans removes cis/trans information from all double bonds
+func (m *Molecule) ClearCisTrans() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoClearCisTrans(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "clear cis/trans")
+	}
+
+	return nil
+}
+
+// MarkStereobonds re-perceives wedge bonds from the current stereo configuration
+func (m *Molecule) MarkStereobonds() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoMarkStereobonds(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "mark stereobonds")
+	}
+
+	return nil
+}
+
+// MarkEitherCisTrans marks double bonds with undefined configuration as "either"
+func (m *Molecule) MarkEitherCisTrans() error {
+	if err := m.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoMarkEitherCisTrans(C.int(m.Handle)))
+	if ret < 0 {
+		return session.NativeError(m.Handle, "mark either cis/trans")
+	}
+
+	return nil
+}
+
+// readStereocenter reads the stereo properties of a stereocenter atom handle
+func readStereocenter(atom int) (Stereocenter, error) {
+	center := Stereocenter{}
+
+	if center.AtomIndex = int(C.indigoIndex(C.int(atom))); center.AtomIndex < 0 {
+		return center, session.NativeError(0, "get stereocenter index")
+	}
+	if center.Type = int(C.indigoStereocenterType(C.int(atom))); center.Type < 0 {
+		return center, session.NativeError(0, "get stereocenter type")
+	}
+	if center.Group = int(C.indigoStereocenterGroup(C.int(atom))); center.Group < 0 {
+		return center, session.NativeError(0, "get stereocenter group")
+	}
+
+	pyramid := C.indigoStereocenterPyramid(C.int(atom))
+	if pyramid == nil {
+		return center, session.NativeError(0, "get stereocenter pyramid")
+	}
+	for i, idx := range unsafe.Slice((*C.int)(unsafe.Pointer(pyramid)), 4) {
+		center.Pyramid[i] = int(idx)
+	}
+
+	cip := int(C.indigoStereocenterCIPDescriptor(C.int(atom)))
+	if cip < 0 {
+		return center, session.NativeError(0, "get CIP descriptor")
+	}
+	center.CIP = cipLabels[cip]
+
+	return center, nil
+}
+
+// bondCIPLabels calculates E/Z labels on a copy of the molecule
+// Returns a map from the sorted atom pair of each double bond to its label
+func bondCIPLabels(molHandle int) (map[[2]int]string, error) {
+	clone := int(C.indigoClone(C.int(molHandle)))
+	if clone < 0 {
+		return nil, session.NativeError(0, "clone molecule")
+	}
+	defer C.indigoFree(C.int(clone))
+
+	if int(C.indigoAddCIPStereoDescriptors(C.int(clone))) < 0 {
+		return nil, session.NativeError(0, "add CIP descriptors")
+	}
+
+	iterHandle := int(C.indigoIterateDataSGroups(C.int(clone)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(0, "iterate data S-groups")
+	}
+
+	labels := make(map[[2]int]string)
+	err := iterateHandles(iterHandle, func(sgroup int) error {
+		cData := C.indigoData(C.int(sgroup))
+		if cData == nil {
+			return nil
+		}
+		label := strings.Trim(C.GoString(cData), "() ")
+		if label != "E" && label != "Z" {
+			return nil
+		}
+
+		atomsIter := int(C.indigoIterateAtoms(C.int(sgroup)))
+		if atomsIter < 0 {
+			return session.NativeError(0, "iterate S-group atoms")
+		}
+
+		var atoms []int
+		err := iterateHandles(atomsIter, func(atom int) error {
+			atoms = append(atoms, int(C.indigoIndex(C.int(atom))))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(atoms) == 2 {
+			labels[atomPair(atoms[0], atoms[1])] = label
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// endpointIndex returns the index of a bond endpoint atom handle and frees it
+func endpointIndex(atom int) (int, error) {
+	if atom < 0 {
+		return 0, session.NativeError(0, "get bond atom")
+	}
+	defer C.indigoFree(C.int(atom))
+
+	index := int(C.indigoIndex(C.int(atom)))
+	if index < 0 {
+		return 0, session.NativeError(0, "get atom index")
+	}
+
+	return index, nil
+}
+
+// atomPair returns an order-independent key for a pair of atom indices
+func atomPair(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
@@ -0,0 +1,133 @@
+// Package molecule provides tautomer enumeration using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_tautomer.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// Tautomer enumeration methods
+const (
+	TAUTOMER_INCHI   = "INCHI"   // InChI-based enumeration
+	TAUTOMER_RSMARTS = "RSMARTS" // reaction SMARTS rules
+)
+
+// Tautomers enumerates the tautomers of the molecule
+// options: TAUTOMER_INCHI, TAUTOMER_RSMARTS or "" for the default method
+// Every returned molecule is independent and must be closed by the caller
+func (m *Molecule) Tautomers(options string) ([]*Molecule, error) {
+	if err := m.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cOptions := C.CString(options)
+	defer C.free(unsafe.Pointer(cOptions))
+
+	iterHandle := int(C.indigoIterateTautomers(C.int(m.Handle), cOptions))
+	if iterHandle < 0 {
+		return nil, session.NativeError(m.Handle, "iterate tautomers")
+	}
+
+	var tautomers []*Molecule
+	err := iterateHandles(iterHandle, func(item int) error {
+		handle := int(C.indigoClone(C.int(item)))
+		if handle < 0 {
+			return session.NativeError(m.Handle, "clone tautomer")
+		}
+		tautomers = append(tautomers, newMolecule(handle, m.session))
+		return nil
+	})
+	if err != nil {
+		for _, t := range tautomers {
+			t.Close()
+		}
+		return nil, err
+	}
+
+	return tautomers, nil
+}
+
+// CanonicalTautomer returns the tautomer with the smallest canonical SMILES
+// The molecule itself is a candidate, so the result is defined even if no tautomers are enumerated
+func (m *Molecule) CanonicalTautomer(options string) (*Molecule, error) {
+	tautomers, err := m.Tautomers(options)
+	if err != nil {
+		return nil, err
+	}
+
+	self, err := m.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	best := self
+	bestSmiles, err := self.ToCanonicalSmiles()
+	if err != nil {
+		self.Close()
+		for _, t := range tautomers {
+			t.Close()
+		}
+		return nil, err
+	}
+
+	// Every candidate must be compared, otherwise the choice would depend on which ones serialize
+	for i, t := range tautomers {
+		smiles, err := t.ToCanonicalSmiles()
+		if err != nil {
+			best.Close()
+			for _, rest := range tautomers[i:] {
+				rest.Close()
+			}
+			return nil, fmt.Errorf("failed to compare tautomer %d: %w", i, err)
+		}
+		if smiles < bestSmiles {
+			best.Close()
+			best, bestSmiles = t, smiles
+			continue
+		}
+		t.Close()
+	}
+
+	return best, nil
+}
+
+// TautomerKey returns the canonical SMILES of the canonical tautomer
+// Tautomeric duplicates share the same key
+func (m *Molecule) TautomerKey(options string) (string, error) {
+	canonical, err := m.CanonicalTautomer(options)
+	if err != nil {
+		return "", err
+	}
+	defer canonical.Close()
+
+	return canonical.ToCanonicalSmiles()
+}
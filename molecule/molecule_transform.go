@@ -0,0 +1,75 @@
+// Package molecule provides reaction-based molecule transformation using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_transform.go
+// @Software: GoLand
+package molecule
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// ApplyTransform modifies the molecule in place with a reaction SMARTS transformation,
+// e.g. "[N+:1](=[O:2])[O-:3]>>[N+0:1](=[O:2])=[O:3]"
+// Returns true if the molecule was changed by the transformation
+func (m *Molecule) ApplyTransform(rxnSmarts string) (bool, error) {
+	if err := m.enter(); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	cSmarts := C.CString(rxnSmarts)
+	defer C.free(unsafe.Pointer(cSmarts))
+
+	rxn := int(C.indigoLoadReactionSmartsFromString(cSmarts))
+	if rxn < 0 {
+		return false, session.NativeError(m.Handle, "load reaction SMARTS")
+	}
+	defer C.indigoFree(C.int(rxn))
+
+	// Indigo does not report whether the transformation matched, so compare the structures
+	before, err := m.ToCanonicalSmiles()
+	if err != nil {
+		return false, err
+	}
+
+	ret := int(C.indigoTransform(C.int(rxn), C.int(m.Handle)))
+	if ret < 0 {
+		return false, session.NativeError(m.Handle, "apply transform")
+	}
+	if ret > 0 {
+		// For a single molecule the result is the atom mapping of the transformation
+		C.indigoFree(C.int(ret))
+	}
+
+	after, err := m.ToCanonicalSmiles()
+	if err != nil {
+		return false, err
+	}
+
+	return before != after, nil
+}
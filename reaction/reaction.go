@@ -28,9 +28,11 @@ package reaction
 */
 import "C"
 import (
-	"fmt"
 	"runtime"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // Reaction center constants
@@ -45,19 +47,42 @@ const (
 
 // Reaction represents a chemical reaction in Indigo
 type Reaction struct {
-	Handle int
-	Closed bool
+	Handle  int
+	Closed  bool
+	session *session.Session // owning session, nil if unbound
+}
+
+// FromHandle wraps a native reaction handle owned by session s, see core.Indigo.Session
+// Every call on the reaction is pinned to s; a nil session leaves the reaction unbound
+func FromHandle(handle int, s *session.Session) *Reaction {
+	return newReaction(handle, s)
+}
+
+// Session returns the session the reaction belongs to, nil if unbound
+func (r *Reaction) Session() *session.Session {
+	return r.session
 }
 
 // Close frees the Indigo reaction object
+// If the session was already closed, the handle is gone with it and only the reaction is marked closed
 func (r *Reaction) Close() error {
 	if r.Closed || r.Handle < 0 {
 		return nil
 	}
 
+	if r.session.Closed() {
+		r.Closed = true
+		r.Handle = -1
+		return nil
+	}
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoFree(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to free reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "free reaction")
 	}
 
 	r.Closed = true
@@ -67,13 +92,14 @@ func (r *Reaction) Close() error {
 
 // CountReactants returns the number of reactants in the reaction
 func (r *Reaction) CountReactants() (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountReactants(C.int(r.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count reactants: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "count reactants")
 	}
 
 	return count, nil
@@ -81,13 +107,14 @@ func (r *Reaction) CountReactants() (int, error) {
 
 // CountProducts returns the number of products in the reaction
 func (r *Reaction) CountProducts() (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountProducts(C.int(r.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count products: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "count products")
 	}
 
 	return count, nil
@@ -95,13 +122,14 @@ func (r *Reaction) CountProducts() (int, error) {
 
 // CountCatalysts returns the number of catalysts in the reaction
 func (r *Reaction) CountCatalysts() (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountCatalysts(C.int(r.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count catalysts: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "count catalysts")
 	}
 
 	return count, nil
@@ -109,13 +137,14 @@ func (r *Reaction) CountCatalysts() (int, error) {
 
 // CountMolecules returns the total number of molecules (reactants + products + catalysts)
 func (r *Reaction) CountMolecules() (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	count := int(C.indigoCountMolecules(C.int(r.Handle)))
 	if count < 0 {
-		return 0, fmt.Errorf("failed to count molecules: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "count molecules")
 	}
 
 	return count, nil
@@ -124,13 +153,14 @@ func (r *Reaction) CountMolecules() (int, error) {
 // AddReactant adds a molecule as a reactant to the reaction
 // moleculeHandle is the Indigo handle of the molecule to add
 func (r *Reaction) AddReactant(moleculeHandle int) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoAddReactant(C.int(r.Handle), C.int(moleculeHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to add reactant: %s", getLastError())
+		return session.NativeError(r.Handle, "add reactant")
 	}
 
 	return nil
@@ -139,13 +169,14 @@ func (r *Reaction) AddReactant(moleculeHandle int) error {
 // AddProduct adds a molecule as a product to the reaction
 // moleculeHandle is the Indigo handle of the molecule to add
 func (r *Reaction) AddProduct(moleculeHandle int) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoAddProduct(C.int(r.Handle), C.int(moleculeHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to add product: %s", getLastError())
+		return session.NativeError(r.Handle, "add product")
 	}
 
 	return nil
@@ -154,13 +185,14 @@ func (r *Reaction) AddProduct(moleculeHandle int) error {
 // AddCatalyst adds a molecule as a catalyst to the reaction
 // moleculeHandle is the Indigo handle of the molecule to add
 func (r *Reaction) AddCatalyst(moleculeHandle int) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoAddCatalyst(C.int(r.Handle), C.int(moleculeHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to add catalyst: %s", getLastError())
+		return session.NativeError(r.Handle, "add catalyst")
 	}
 
 	return nil
@@ -169,13 +201,14 @@ func (r *Reaction) AddCatalyst(moleculeHandle int) error {
 // GetMolecule returns a molecule from the reaction by index
 // Index order: reactants, then products, then catalysts
 func (r *Reaction) GetMolecule(index int) (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoGetMolecule(C.int(r.Handle), C.int(index)))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to get molecule at index %d: %s", index, getLastError())
+		return 0, session.NativeError(r.Handle, "get molecule at index %d", index)
 	}
 
 	return handle, nil
@@ -183,52 +216,54 @@ func (r *Reaction) GetMolecule(index int) (int, error) {
 
 // Clone creates a deep copy of the reaction
 func (r *Reaction) Clone() (*Reaction, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	newHandle := int(C.indigoClone(C.int(r.Handle)))
 	if newHandle < 0 {
-		return nil, fmt.Errorf("failed to clone reaction: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "clone reaction")
 	}
 
-	return newReaction(newHandle), nil
+	return newReaction(newHandle, r.session), nil
 }
 
 // Optimize optimizes the query reaction for faster substructure search
 // options is a string with optimization options (empty string for defaults)
 func (r *Reaction) Optimize(options string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cOptions := C.CString(options)
 	defer C.free(unsafe.Pointer(cOptions))
 
 	ret := int(C.indigoOptimize(C.int(r.Handle), cOptions))
 	if ret < 0 {
-		return fmt.Errorf("failed to optimize reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "optimize reaction")
 	}
 
 	return nil
 }
 
-// getLastError retrieves the last error message from Indigo
-func getLastError() string {
-	errMsg := C.indigoGetLastError()
-	if errMsg == nil {
-		return "unknown error"
-	}
-	return C.GoString(errMsg)
-}
-
 // newReaction is a helper function to create a Reaction object from a handle
 // It sets up the finalizer to ensure proper cleanup
-func newReaction(handle int) *Reaction {
+func newReaction(handle int, s *session.Session) *Reaction {
 	r := &Reaction{
-		Handle: handle,
-		Closed: false,
+		Handle:  handle,
+		Closed:  false,
+		session: s,
 	}
 	runtime.SetFinalizer(r, (*Reaction).Close)
 	return r
 }
+
+// enter pins the calling goroutine to the reaction's session; pair it with session.Exit
+func (r *Reaction) enter() error {
+	if r.Closed {
+		return indigoerr.Closed("reaction")
+	}
+	return session.Enter(r.session)
+}
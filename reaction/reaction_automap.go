@@ -28,8 +28,9 @@ package reaction
 */
 import "C"
 import (
-	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // Automap modes
@@ -73,16 +74,17 @@ const (
 //   - "ignore_valence": do not consider atom valence while searching
 //   - "ignore_radicals": do not consider atom radicals while searching
 func (r *Reaction) Automap(mode string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cMode := C.CString(mode)
 	defer C.free(unsafe.Pointer(cMode))
 
 	ret := int(C.indigoAutomap(C.int(r.Handle), cMode))
 	if ret < 0 {
-		return fmt.Errorf("failed to automap reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "automap reaction")
 	}
 
 	return nil
@@ -91,13 +93,14 @@ func (r *Reaction) Automap(mode string) error {
 // GetAtomMappingNumber returns the atom-to-atom mapping number for a reaction atom
 // Returns 0 if no mapping number has been specified
 func (r *Reaction) GetAtomMappingNumber(atomHandle int) (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	number := int(C.indigoGetAtomMappingNumber(C.int(r.Handle), C.int(atomHandle)))
 	if number < 0 {
-		return 0, fmt.Errorf("failed to get atom mapping number: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "get atom mapping number")
 	}
 
 	return number, nil
@@ -105,13 +108,14 @@ func (r *Reaction) GetAtomMappingNumber(atomHandle int) (int, error) {
 
 // SetAtomMappingNumber sets the atom-to-atom mapping number for a reaction atom
 func (r *Reaction) SetAtomMappingNumber(atomHandle int, number int) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoSetAtomMappingNumber(C.int(r.Handle), C.int(atomHandle), C.int(number)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set atom mapping number: %s", getLastError())
+		return session.NativeError(r.Handle, "set atom mapping number")
 	}
 
 	return nil
@@ -120,14 +124,15 @@ func (r *Reaction) SetAtomMappingNumber(atomHandle int, number int) error {
 // GetReactingCenter returns the reacting center information for a bond
 // Returns the reacting center flags (combination of RC_* constants)
 func (r *Reaction) GetReactingCenter(bondHandle int) (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	var rc C.int
 	ret := int(C.indigoGetReactingCenter(C.int(r.Handle), C.int(bondHandle), &rc))
 	if ret < 0 {
-		return 0, fmt.Errorf("failed to get reacting center: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "get reacting center")
 	}
 
 	return int(rc), nil
@@ -136,13 +141,14 @@ func (r *Reaction) GetReactingCenter(bondHandle int) (int, error) {
 // SetReactingCenter sets the reacting center information for a bond
 // rc should be a combination of RC_* constants
 func (r *Reaction) SetReactingCenter(bondHandle int, rc int) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoSetReactingCenter(C.int(r.Handle), C.int(bondHandle), C.int(rc)))
 	if ret < 0 {
-		return fmt.Errorf("failed to set reacting center: %s", getLastError())
+		return session.NativeError(r.Handle, "set reacting center")
 	}
 
 	return nil
@@ -150,13 +156,14 @@ func (r *Reaction) SetReactingCenter(bondHandle int, rc int) error {
 
 // ClearAAM clears all atom-to-atom mapping information from the reaction
 func (r *Reaction) ClearAAM() error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoClearAAM(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to clear AAM: %s", getLastError())
+		return session.NativeError(r.Handle, "clear AAM")
 	}
 
 	return nil
@@ -164,13 +171,14 @@ func (r *Reaction) ClearAAM() error {
 
 // CorrectReactingCenters corrects reacting centers according to atom-to-atom mapping
 func (r *Reaction) CorrectReactingCenters() error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoCorrectReactingCenters(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to correct reacting centers: %s", getLastError())
+		return session.NativeError(r.Handle, "correct reacting centers")
 	}
 
 	return nil
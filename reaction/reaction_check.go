@@ -0,0 +1,143 @@
+// Package reaction provides reaction structure checking using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_check.go
+// @Software: GoLand
+package reaction
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+// Reaction component roles
+const (
+	ROLE_REACTANT = "reactant"
+	ROLE_PRODUCT  = "product"
+	ROLE_CATALYST = "catalyst"
+)
+
+// ComponentCheck is the check result of a single reaction component
+type ComponentCheck struct {
+	Role   string // ROLE_REACTANT, ROLE_PRODUCT or ROLE_CATALYST
+	Index  int    // position of the component within its role
+	Report *molecule.CheckReport
+}
+
+// CheckReport holds the check results of every component of a reaction
+type CheckReport struct {
+	Components []ComponentCheck
+}
+
+// OK returns true if no component has issues
+func (r *CheckReport) OK() bool {
+	for _, c := range r.Components {
+		if !c.Report.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Messages returns the messages of all issues prefixed with the component they belong to
+func (r *CheckReport) Messages() []string {
+	var messages []string
+	for _, c := range r.Components {
+		for _, msg := range c.Report.Messages() {
+			messages = append(messages, fmt.Sprintf("%s %d: %s", c.Role, c.Index, msg))
+		}
+	}
+	return messages
+}
+
+// Check runs the given structure checks on every reactant, product and catalyst
+// All checks are run if none are given
+func (r *Reaction) Check(checks ...molecule.CheckType) (*CheckReport, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	cFlags := C.CString(molecule.CheckFlags(checks...))
+	defer C.free(unsafe.Pointer(cFlags))
+
+	report := &CheckReport{}
+	roles := []struct {
+		role    string
+		iterate func(C.int) C.int
+	}{
+		{ROLE_REACTANT, func(h C.int) C.int { return C.indigoIterateReactants(h) }},
+		{ROLE_PRODUCT, func(h C.int) C.int { return C.indigoIterateProducts(h) }},
+		{ROLE_CATALYST, func(h C.int) C.int { return C.indigoIterateCatalysts(h) }},
+	}
+
+	for _, role := range roles {
+		iterHandle := int(role.iterate(C.int(r.Handle)))
+		if iterHandle < 0 {
+			return nil, session.NativeError(r.Handle, "iterate %ss", role.role)
+		}
+
+		err := checkComponents(iterHandle, role.role, cFlags, report)
+		C.indigoFree(C.int(iterHandle))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// checkComponents checks every molecule of a component iterator and appends the results
+func checkComponents(iterHandle int, role string, cFlags *C.char, report *CheckReport) error {
+	for index := 0; ; index++ {
+		molHandle := int(C.indigoNext(C.int(iterHandle)))
+		if molHandle == 0 {
+			return nil
+		}
+		if molHandle < 0 {
+			return session.NativeError(0, "get next %s", role)
+		}
+
+		cStr := C.indigoCheckObj(C.int(molHandle), cFlags)
+		var data string
+		if cStr != nil {
+			data = C.GoString(cStr)
+		}
+		C.indigoFree(C.int(molHandle))
+		if cStr == nil {
+			return session.NativeError(0, "check %s %d", role, index)
+		}
+
+		res, err := molecule.ParseCheckReport(data)
+		if err != nil {
+			return err
+		}
+
+		report.Components = append(report.Components, ComponentCheck{Role: role, Index: index, Report: res})
+	}
+}
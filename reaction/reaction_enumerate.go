@@ -0,0 +1,411 @@
+// Package reaction provides reaction product enumeration using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_enumerate.go
+// @Software: GoLand
+package reaction
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+// SOURCE_PROPERTY is set on every reactant of an enumerated product reaction
+// Its value is "<set>:<index>", the position of the reactant in the reactant sets
+const SOURCE_PROPERTY = "enumeration-source"
+
+// sourceNamePrefix tags the monomer copies so they can be traced through the enumeration
+const sourceNamePrefix = "__go_indigo_source_"
+
+// Enumerate runs a reaction template on every combination of reactants
+// template: a query reaction whose reactants are matched by the molecules of
+// the corresponding reactant set, e.g. loaded from reaction SMARTS
+// Every product reaction keeps the reactants it was built from; use Provenance
+// to find their positions in reactantSets. Products are ordered by the positions
+// of their reactants, the last set varying fastest; reactants that cannot be traced come first
+func Enumerate(template *Reaction, reactantSets [][]*molecule.Molecule) ([]*Reaction, error) {
+	if err := template.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	monomers, names, err := newMonomerTable(template.session, reactantSets)
+	if err != nil {
+		return nil, err
+	}
+	defer C.indigoFree(C.int(monomers))
+
+	output := int(C.indigoReactionProductEnumerate(C.int(template.Handle), C.int(monomers)))
+	if output < 0 {
+		return nil, session.NativeError(0, "enumerate products")
+	}
+	defer C.indigoFree(C.int(output))
+
+	iterHandle := int(C.indigoIterateArray(C.int(output)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(0, "iterate products")
+	}
+	defer C.indigoFree(C.int(iterHandle))
+
+	var products []*Reaction
+	var sources [][][2]int
+	closeAll := func() {
+		for _, p := range products {
+			p.Close()
+		}
+	}
+
+	matcher := &sourceMatcher{sets: reactantSets}
+	for {
+		item := int(C.indigoNext(C.int(iterHandle)))
+		if item == 0 {
+			break
+		}
+		if item < 0 {
+			closeAll()
+			return nil, session.NativeError(0, "get next product")
+		}
+
+		handle := int(C.indigoClone(C.int(item)))
+		C.indigoFree(C.int(item))
+		if handle < 0 {
+			closeAll()
+			return nil, session.NativeError(0, "clone product")
+		}
+
+		product := newReaction(handle, template.session)
+		products = append(products, product)
+
+		found, err := restoreSources(product, names, matcher)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		sources = append(sources, found)
+	}
+
+	order := make([]int, len(products))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return lessSources(sources[order[i]], sources[order[j]])
+	})
+	sorted := make([]*Reaction, len(products))
+	for i, index := range order {
+		sorted[i] = products[index]
+	}
+
+	return sorted, nil
+}
+
+// lessSources orders reactant positions lexicographically
+func lessSources(a, b [][2]int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i][0] < b[i][0] || (a[i][0] == b[i][0] && a[i][1] < b[i][1])
+		}
+	}
+	return len(a) < len(b)
+}
+
+// Provenance returns, for each reactant of an enumerated product, its [set, index]
+// position in the reactant sets passed to Enumerate, or [-1, -1] for a reactant that could not be traced
+func Provenance(product *Reaction) ([][2]int, error) {
+	if err := product.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	iterHandle := int(C.indigoIterateReactants(C.int(product.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(0, "iterate reactants")
+	}
+	defer C.indigoFree(C.int(iterHandle))
+
+	cProp := C.CString(SOURCE_PROPERTY)
+	defer C.free(unsafe.Pointer(cProp))
+
+	var sources [][2]int
+	for {
+		item := int(C.indigoNext(C.int(iterHandle)))
+		if item == 0 {
+			return sources, nil
+		}
+		if item < 0 {
+			return nil, session.NativeError(0, "get next reactant")
+		}
+
+		value, traced, err := sourceProperty(item, cProp)
+		C.indigoFree(C.int(item))
+		if err != nil {
+			return nil, err
+		}
+		if !traced {
+			sources = append(sources, [2]int{-1, -1})
+			continue
+		}
+
+		set, index, ok := parseSource(value)
+		if !ok {
+			return nil, indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid %s property %q", SOURCE_PROPERTY, value)
+		}
+		sources = append(sources, [2]int{set, index})
+	}
+}
+
+// sourceProperty reads the SOURCE_PROPERTY property of a reactant
+// traced is false if the reactant has none, i.e. it could not be traced to a monomer
+func sourceProperty(item int, cProp *C.char) (string, bool, error) {
+	has := int(C.indigoHasProperty(C.int(item), cProp))
+	if has < 0 {
+		return "", false, session.NativeError(item, "check %s property of reactant", SOURCE_PROPERTY)
+	}
+	if has == 0 {
+		return "", false, nil
+	}
+
+	cValue := C.indigoGetProperty(C.int(item), cProp)
+	if cValue == nil {
+		return "", false, session.NativeError(item, "get %s property of reactant", SOURCE_PROPERTY)
+	}
+	return C.GoString(cValue), true, nil
+}
+
+// newMonomerTable builds the array of arrays of reactants expected by Indigo
+// The copies are renamed with their position; the original names are returned by position
+func newMonomerTable(s *session.Session, reactantSets [][]*molecule.Molecule) (int, map[string]string, error) {
+	table := int(C.indigoCreateArray())
+	if table < 0 {
+		return 0, nil, session.NativeError(0, "create array")
+	}
+
+	names := make(map[string]string)
+	for set, mols := range reactantSets {
+		arr := int(C.indigoCreateArray())
+		if arr < 0 {
+			C.indigoFree(C.int(table))
+			return 0, nil, session.NativeError(0, "create array")
+		}
+
+		var err error
+		for index, mol := range mols {
+			if err = addMonomer(s, arr, set, index, mol, names); err != nil {
+				break
+			}
+		}
+		if err == nil && int(C.indigoArrayAdd(C.int(table), C.int(arr))) < 0 {
+			err = session.NativeError(0, "add reactant set %d", set)
+		}
+		C.indigoFree(C.int(arr))
+		if err != nil {
+			C.indigoFree(C.int(table))
+			return 0, nil, err
+		}
+	}
+
+	return table, names, nil
+}
+
+// addMonomer adds a renamed copy of reactant index of set to arr
+func addMonomer(s *session.Session, arr int, set int, index int, mol *molecule.Molecule, names map[string]string) error {
+	if mol == nil || mol.Closed {
+		return indigoerr.New(indigoerr.CATEGORY_CLOSED, "reactant %d of set %d is closed", index, set)
+	}
+	if err := session.Same(s, mol.Session()); err != nil {
+		return fmt.Errorf("reactant %d of set %d: %w", index, set, err)
+	}
+
+	if int(C.indigoArrayAdd(C.int(arr), C.int(mol.Handle))) < 0 {
+		return session.NativeError(0, "add reactant %d of set %d", index, set)
+	}
+
+	name, err := mol.Name()
+	if err != nil {
+		name = ""
+	}
+	tag := sourceNamePrefix + formatSource(set, index)
+	names[tag] = name
+
+	item := int(C.indigoAt(C.int(arr), C.int(index)))
+	if item < 0 {
+		return session.NativeError(0, "get reactant copy")
+	}
+
+	cTag := C.CString(tag)
+	ret := int(C.indigoSetName(C.int(item), cTag))
+	C.free(unsafe.Pointer(cTag))
+	C.indigoFree(C.int(item))
+	if ret < 0 {
+		return session.NativeError(0, "tag reactant")
+	}
+
+	return nil
+}
+
+// restoreSources replaces the tag names of the product reactants with the original
+// names and records their position in the SOURCE_PROPERTY property
+// The positions are returned in reactant order, [-1, -1] for reactants that cannot be traced
+func restoreSources(product *Reaction, names map[string]string, matcher *sourceMatcher) ([][2]int, error) {
+	iterHandle := int(C.indigoIterateReactants(C.int(product.Handle)))
+	if iterHandle < 0 {
+		return nil, session.NativeError(0, "iterate reactants")
+	}
+	defer C.indigoFree(C.int(iterHandle))
+
+	cProp := C.CString(SOURCE_PROPERTY)
+	defer C.free(unsafe.Pointer(cProp))
+
+	var sources [][2]int
+	for position := 0; ; position++ {
+		item := int(C.indigoNext(C.int(iterHandle)))
+		if item == 0 {
+			return sources, nil
+		}
+		if item < 0 {
+			return nil, session.NativeError(0, "get next reactant")
+		}
+
+		tag, err := restoreSource(item, cProp, names, matcher, position)
+		C.indigoFree(C.int(item))
+		if err != nil {
+			return nil, err
+		}
+		if set, index, ok := parseSource(strings.TrimPrefix(tag, sourceNamePrefix)); ok {
+			sources = append(sources, [2]int{set, index})
+		} else {
+			sources = append(sources, [2]int{-1, -1})
+		}
+	}
+}
+
+// restoreSource restores the name and sets the source property of a single reactant
+// at position in the product; a reactant that lost its tag is looked up in the set at
+// that position. The tag of the reactant is returned, "" if it is not one of the monomers
+func restoreSource(item int, cProp *C.char, names map[string]string, matcher *sourceMatcher, position int) (string, error) {
+	cName := C.indigoName(C.int(item))
+	if cName == nil {
+		return "", session.NativeError(0, "get reactant name")
+	}
+
+	tag := C.GoString(cName)
+	original, ok := names[tag]
+	if !ok {
+		index, err := matcher.find(item, position)
+		if err != nil {
+			return "", err
+		}
+		if index < 0 {
+			// Not one of our monomers, leave it untouched
+			return "", nil
+		}
+		tag = sourceNamePrefix + formatSource(position, index)
+		original = names[tag]
+	}
+
+	cOriginal := C.CString(original)
+	defer C.free(unsafe.Pointer(cOriginal))
+	if int(C.indigoSetName(C.int(item), cOriginal)) < 0 {
+		return "", session.NativeError(0, "restore reactant name")
+	}
+
+	cValue := C.CString(strings.TrimPrefix(tag, sourceNamePrefix))
+	defer C.free(unsafe.Pointer(cValue))
+	if int(C.indigoSetProperty(C.int(item), cProp, cValue)) < 0 {
+		return "", session.NativeError(0, "set %s property", SOURCE_PROPERTY)
+	}
+
+	return tag, nil
+}
+
+// sourceMatcher finds reactants that lost their tag by their canonical SMILES
+// The SMILES of a reactant set are computed on first use only
+type sourceMatcher struct {
+	sets   [][]*molecule.Molecule
+	smiles map[int][]string
+}
+
+// find returns the index of the first molecule of the set equal to the reactant item, -1 if none
+func (sm *sourceMatcher) find(item int, set int) (int, error) {
+	if set >= len(sm.sets) {
+		return -1, nil
+	}
+
+	if sm.smiles == nil {
+		sm.smiles = make(map[int][]string)
+	}
+	smiles, ok := sm.smiles[set]
+	if !ok {
+		for index, mol := range sm.sets[set] {
+			s, err := mol.ToCanonicalSmiles()
+			if err != nil {
+				return -1, fmt.Errorf("reactant %d of set %d: %w", index, set, err)
+			}
+			smiles = append(smiles, s)
+		}
+		sm.smiles[set] = smiles
+	}
+
+	cStr := C.indigoCanonicalSmiles(C.int(item))
+	if cStr == nil {
+		return -1, session.NativeError(0, "get reactant SMILES")
+	}
+	target := C.GoString(cStr)
+
+	for index, s := range smiles {
+		if s == target {
+			return index, nil
+		}
+	}
+	return -1, nil
+}
+
+// formatSource encodes the position of a reactant
+func formatSource(set int, index int) string {
+	return strconv.Itoa(set) + ":" + strconv.Itoa(index)
+}
+
+// parseSource decodes a position written by formatSource
+func parseSource(value string) (int, int, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	set, err1 := strconv.Atoi(parts[0])
+	index, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+
+	return set, index, true
+}
@@ -0,0 +1,66 @@
+// Package reaction provides reaction fingerprint functionality using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_fingerprint.go
+// @Software: GoLand
+package reaction
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+// Fingerprint calculates a fingerprint of the reaction
+// kind: molecule.FINGERPRINT_SIM, molecule.FINGERPRINT_SUB, molecule.FINGERPRINT_FULL, etc.
+func (r *Reaction) Fingerprint(kind string) (*molecule.Fingerprint, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	if kind == "" {
+		kind = molecule.FINGERPRINT_SIM
+	}
+
+	cKind := C.CString(kind)
+	defer C.free(unsafe.Pointer(cKind))
+
+	fpHandle := int(C.indigoFingerprint(C.int(r.Handle), cKind))
+	if fpHandle < 0 {
+		return nil, session.NativeError(r.Handle, "calculate %s fingerprint", kind)
+	}
+	defer C.indigoFree(C.int(fpHandle))
+
+	var size C.int
+	var dataPtr *C.char
+	ret := int(C.indigoToBuffer(C.int(fpHandle), &dataPtr, &size))
+	if ret < 0 || dataPtr == nil {
+		return nil, session.NativeError(r.Handle, "get fingerprint data")
+	}
+
+	return molecule.NewFingerprint(kind, C.GoBytes(unsafe.Pointer(dataPtr), size)), nil
+}
@@ -28,19 +28,21 @@ package reaction
 */
 import "C"
 import (
-	"fmt"
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // GetReactant returns a reactant molecule by index
 func (r *Reaction) GetReactant(index int) (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	// Get iterator
 	iterHandle := int(C.indigoIterateReactants(C.int(r.Handle)))
 	if iterHandle < 0 {
-		return 0, fmt.Errorf("failed to iterate reactants: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "iterate reactants")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
@@ -54,19 +56,20 @@ func (r *Reaction) GetReactant(index int) (int, error) {
 		currentIndex++
 	}
 
-	return 0, fmt.Errorf("reactant index %d out of range", index)
+	return 0, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "reactant index %d out of range", index)
 }
 
 // GetProduct returns a product molecule by index
 func (r *Reaction) GetProduct(index int) (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	// Get iterator
 	iterHandle := int(C.indigoIterateProducts(C.int(r.Handle)))
 	if iterHandle < 0 {
-		return 0, fmt.Errorf("failed to iterate products: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "iterate products")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
@@ -80,19 +83,20 @@ func (r *Reaction) GetProduct(index int) (int, error) {
 		currentIndex++
 	}
 
-	return 0, fmt.Errorf("product index %d out of range", index)
+	return 0, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "product index %d out of range", index)
 }
 
 // GetCatalyst returns a catalyst molecule by index
 func (r *Reaction) GetCatalyst(index int) (int, error) {
-	if r.Closed {
-		return 0, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	// Get iterator
 	iterHandle := int(C.indigoIterateCatalysts(C.int(r.Handle)))
 	if iterHandle < 0 {
-		return 0, fmt.Errorf("failed to iterate catalysts: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "iterate catalysts")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
@@ -106,18 +110,19 @@ func (r *Reaction) GetCatalyst(index int) (int, error) {
 		currentIndex++
 	}
 
-	return 0, fmt.Errorf("catalyst index %d out of range", index)
+	return 0, indigoerr.New(indigoerr.CATEGORY_NOT_FOUND, "catalyst index %d out of range", index)
 }
 
 // Layout performs 2D layout of the reaction
 func (r *Reaction) Layout() error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoLayout(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to layout reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "layout reaction")
 	}
 
 	return nil
@@ -125,13 +130,14 @@ func (r *Reaction) Layout() error {
 
 // Clean2D performs 2D cleaning of the reaction
 func (r *Reaction) Clean2D() error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoClean2d(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to clean2d reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "clean2d reaction")
 	}
 
 	return nil
@@ -139,13 +145,14 @@ func (r *Reaction) Clean2D() error {
 
 // Aromatize performs aromatization of all molecules in the reaction
 func (r *Reaction) Aromatize() error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoAromatize(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to aromatize reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "aromatize reaction")
 	}
 
 	return nil
@@ -153,13 +160,14 @@ func (r *Reaction) Aromatize() error {
 
 // Dearomatize removes aromaticity from all molecules in the reaction
 func (r *Reaction) Dearomatize() error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoDearomatize(C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to dearomatize reaction: %s", getLastError())
+		return session.NativeError(r.Handle, "dearomatize reaction")
 	}
 
 	return nil
@@ -167,6 +175,11 @@ func (r *Reaction) Dearomatize() error {
 
 // GetReactantMolecule returns a reactant molecule handle as a Molecule object by index
 func (r *Reaction) GetReactantMolecule(index int) (int, error) {
+	if err := r.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	handle, err := r.GetReactant(index)
 	if err != nil {
 		return 0, err
@@ -175,7 +188,7 @@ func (r *Reaction) GetReactantMolecule(index int) (int, error) {
 	// Clone the molecule to avoid issues with handle ownership
 	clonedHandle := int(C.indigoClone(C.int(handle)))
 	if clonedHandle < 0 {
-		return 0, fmt.Errorf("failed to clone reactant: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "clone reactant")
 	}
 
 	return clonedHandle, nil
@@ -183,6 +196,11 @@ func (r *Reaction) GetReactantMolecule(index int) (int, error) {
 
 // GetProductMolecule returns a product molecule as a Molecule object by index
 func (r *Reaction) GetProductMolecule(index int) (int, error) {
+	if err := r.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	handle, err := r.GetProduct(index)
 	if err != nil {
 		return 0, err
@@ -191,7 +209,7 @@ func (r *Reaction) GetProductMolecule(index int) (int, error) {
 	// Clone the molecule to avoid issues with handle ownership
 	clonedHandle := int(C.indigoClone(C.int(handle)))
 	if clonedHandle < 0 {
-		return 0, fmt.Errorf("failed to clone product: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "clone product")
 	}
 
 	return clonedHandle, nil
@@ -199,6 +217,11 @@ func (r *Reaction) GetProductMolecule(index int) (int, error) {
 
 // GetCatalystMolecule returns a catalyst molecule as a Molecule object by index
 func (r *Reaction) GetCatalystMolecule(index int) (int, error) {
+	if err := r.enter(); err != nil {
+		return 0, err
+	}
+	defer session.Exit()
+
 	handle, err := r.GetCatalyst(index)
 	if err != nil {
 		return 0, err
@@ -207,7 +230,7 @@ func (r *Reaction) GetCatalystMolecule(index int) (int, error) {
 	// Clone the molecule to avoid issues with handle ownership
 	clonedHandle := int(C.indigoClone(C.int(handle)))
 	if clonedHandle < 0 {
-		return 0, fmt.Errorf("failed to clone catalyst: %s", getLastError())
+		return 0, session.NativeError(r.Handle, "clone catalyst")
 	}
 
 	return clonedHandle, nil
@@ -215,9 +238,10 @@ func (r *Reaction) GetCatalystMolecule(index int) (int, error) {
 
 // GetAllReactants returns all reactant molecules as Molecule objects
 func (r *Reaction) GetAllReactants() ([]int, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	count, err := r.CountReactants()
 	if err != nil {
@@ -228,7 +252,7 @@ func (r *Reaction) GetAllReactants() ([]int, error) {
 
 	iterHandle := int(C.indigoIterateReactants(C.int(r.Handle)))
 	if iterHandle < 0 {
-		return nil, fmt.Errorf("failed to iterate reactants: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "iterate reactants")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
@@ -252,9 +276,10 @@ func (r *Reaction) GetAllReactants() ([]int, error) {
 
 // GetAllProducts returns all product molecules as Molecule objects
 func (r *Reaction) GetAllProducts() ([]int, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	count, err := r.CountProducts()
 	if err != nil {
@@ -265,7 +290,7 @@ func (r *Reaction) GetAllProducts() ([]int, error) {
 
 	iterHandle := int(C.indigoIterateProducts(C.int(r.Handle)))
 	if iterHandle < 0 {
-		return nil, fmt.Errorf("failed to iterate products: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "iterate products")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
@@ -289,9 +314,10 @@ func (r *Reaction) GetAllProducts() ([]int, error) {
 
 // GetAllCatalysts returns all catalyst molecules as Molecule objects
 func (r *Reaction) GetAllCatalysts() ([]int, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	count, err := r.CountCatalysts()
 	if err != nil {
@@ -302,7 +328,7 @@ func (r *Reaction) GetAllCatalysts() ([]int, error) {
 
 	iterHandle := int(C.indigoIterateCatalysts(C.int(r.Handle)))
 	if iterHandle < 0 {
-		return nil, fmt.Errorf("failed to iterate catalysts: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "iterate catalysts")
 	}
 	defer C.indigoFree(C.int(iterHandle))
 
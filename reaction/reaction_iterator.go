@@ -28,30 +28,35 @@ package reaction
 */
 import "C"
 import (
-	"fmt"
 	"runtime"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // ReactionIterator represents an iterator for molecules in a reaction
 type ReactionIterator struct {
-	handle int
-	closed bool
+	handle  int
+	closed  bool
+	session *session.Session
 }
 
 // IterateReactants returns an iterator for all reactants in the reaction
 func (r *Reaction) IterateReactants() (*ReactionIterator, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoIterateReactants(C.int(r.Handle)))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to create reactants iterator: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "create reactants iterator")
 	}
 
 	iter := &ReactionIterator{
-		handle: handle,
-		closed: false,
+		handle:  handle,
+		closed:  false,
+		session: r.session,
 	}
 
 	runtime.SetFinalizer(iter, (*ReactionIterator).Close)
@@ -60,18 +65,20 @@ func (r *Reaction) IterateReactants() (*ReactionIterator, error) {
 
 // IterateProducts returns an iterator for all products in the reaction
 func (r *Reaction) IterateProducts() (*ReactionIterator, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoIterateProducts(C.int(r.Handle)))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to create products iterator: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "create products iterator")
 	}
 
 	iter := &ReactionIterator{
-		handle: handle,
-		closed: false,
+		handle:  handle,
+		closed:  false,
+		session: r.session,
 	}
 
 	runtime.SetFinalizer(iter, (*ReactionIterator).Close)
@@ -80,18 +87,20 @@ func (r *Reaction) IterateProducts() (*ReactionIterator, error) {
 
 // IterateCatalysts returns an iterator for all catalysts in the reaction
 func (r *Reaction) IterateCatalysts() (*ReactionIterator, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoIterateCatalysts(C.int(r.Handle)))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to create catalysts iterator: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "create catalysts iterator")
 	}
 
 	iter := &ReactionIterator{
-		handle: handle,
-		closed: false,
+		handle:  handle,
+		closed:  false,
+		session: r.session,
 	}
 
 	runtime.SetFinalizer(iter, (*ReactionIterator).Close)
@@ -100,18 +109,20 @@ func (r *Reaction) IterateCatalysts() (*ReactionIterator, error) {
 
 // IterateMolecules returns an iterator for all molecules (reactants, products, and catalysts) in the reaction
 func (r *Reaction) IterateMolecules() (*ReactionIterator, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoIterateMolecules(C.int(r.Handle)))
 	if handle < 0 {
-		return nil, fmt.Errorf("failed to create molecules iterator: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "create molecules iterator")
 	}
 
 	iter := &ReactionIterator{
-		handle: handle,
-		closed: false,
+		handle:  handle,
+		closed:  false,
+		session: r.session,
 	}
 
 	runtime.SetFinalizer(iter, (*ReactionIterator).Close)
@@ -123,6 +134,10 @@ func (iter *ReactionIterator) HasNext() bool {
 	if iter.closed {
 		return false
 	}
+	if err := session.Enter(iter.session); err != nil {
+		return false
+	}
+	defer session.Exit()
 
 	ret := int(C.indigoHasNext(C.int(iter.handle)))
 	return ret > 0
@@ -131,12 +146,16 @@ func (iter *ReactionIterator) HasNext() bool {
 // Next advances the iterator and returns the handle to the current item
 func (iter *ReactionIterator) Next() (int, error) {
 	if iter.closed {
-		return 0, fmt.Errorf("iterator is closed")
+		return 0, indigoerr.Closed("iterator")
+	}
+	if err := session.Enter(iter.session); err != nil {
+		return 0, err
 	}
+	defer session.Exit()
 
 	handle := int(C.indigoNext(C.int(iter.handle)))
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to get next item: %s", getLastError())
+		return 0, session.NativeError(iter.handle, "get next item")
 	}
 
 	return handle, nil
@@ -148,9 +167,19 @@ func (iter *ReactionIterator) Close() error {
 		return nil
 	}
 
+	if iter.session.Closed() {
+		iter.closed = true
+		iter.handle = -1
+		return nil
+	}
+	if err := session.Enter(iter.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoFree(C.int(iter.handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to free iterator: %s", getLastError())
+		return session.NativeError(iter.handle, "free iterator")
 	}
 
 	iter.closed = true
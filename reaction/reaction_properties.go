@@ -0,0 +1,173 @@
+// Package reaction provides reaction property access using Indigo library via CGO
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_properties.go
+// @Software: GoLand
+package reaction
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../3rd
+
+// Windows platforms
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/../3rd/windows-x86_64 -lindigo
+#cgo windows,386 LDFLAGS: -L${SRCDIR}/../3rd/windows-i386 -lindigo
+
+// Linux platforms
+#cgo linux,amd64 LDFLAGS: -L${SRCDIR}/../3rd/linux-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-x86_64
+#cgo linux,arm64 LDFLAGS: -L${SRCDIR}/../3rd/linux-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/linux-aarch64
+
+// macOS platforms
+#cgo darwin,amd64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-x86_64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-x86_64
+#cgo darwin,arm64 LDFLAGS: -L${SRCDIR}/../3rd/darwin-aarch64 -lindigo -Wl,-rpath,${SRCDIR}/../3rd/darwin-aarch64
+
+#include <stdlib.h>
+#include "indigo.h"
+*/
+import "C"
+import (
+	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/property"
+	"github.com/cx-luo/go-indigo/internal/session"
+)
+
+// HasProperty checks if the reaction has a property
+func (r *Reaction) HasProperty(prop string) (bool, error) {
+	if err := r.enter(); err != nil {
+		return false, err
+	}
+	defer session.Exit()
+
+	cProp := C.CString(prop)
+	defer C.free(unsafe.Pointer(cProp))
+
+	ret := int(C.indigoHasProperty(C.int(r.Handle), cProp))
+	if ret < 0 {
+		return false, session.NativeError(r.Handle, "check property")
+	}
+
+	return ret > 0, nil
+}
+
+// GetProperty gets a property value from the reaction
+func (r *Reaction) GetProperty(prop string) (string, error) {
+	if err := r.enter(); err != nil {
+		return "", err
+	}
+	defer session.Exit()
+
+	cProp := C.CString(prop)
+	defer C.free(unsafe.Pointer(cProp))
+
+	cStr := C.indigoGetProperty(C.int(r.Handle), cProp)
+	if cStr == nil {
+		return "", session.NativeError(r.Handle, "get property")
+	}
+
+	return C.GoString(cStr), nil
+}
+
+// SetProperty sets a property value on the reaction
+func (r *Reaction) SetProperty(prop string, value string) error {
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	cProp := C.CString(prop)
+	defer C.free(unsafe.Pointer(cProp))
+
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	ret := int(C.indigoSetProperty(C.int(r.Handle), cProp, cValue))
+	if ret < 0 {
+		return session.NativeError(r.Handle, "set property")
+	}
+
+	return nil
+}
+
+// RemoveProperty removes a property from the reaction
+func (r *Reaction) RemoveProperty(prop string) error {
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	cProp := C.CString(prop)
+	defer C.free(unsafe.Pointer(cProp))
+
+	ret := int(C.indigoRemoveProperty(C.int(r.Handle), cProp))
+	if ret < 0 {
+		return session.NativeError(r.Handle, "remove property")
+	}
+
+	return nil
+}
+
+// Properties returns all properties of the reaction, such as RD file data fields
+func (r *Reaction) Properties() (map[string]string, error) {
+	if err := r.enter(); err != nil {
+		return nil, err
+	}
+	defer session.Exit()
+
+	return property.All(r.Handle)
+}
+
+// SetProperties sets every property of the map, keeping the other existing properties
+func (r *Reaction) SetProperties(props map[string]string) error {
+	for prop, value := range props {
+		if err := r.SetProperty(prop, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearProperties removes all properties of the reaction
+func (r *Reaction) ClearProperties() error {
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer session.Exit()
+
+	ret := int(C.indigoClearProperties(C.int(r.Handle)))
+	if ret < 0 {
+		return session.NativeError(r.Handle, "clear properties")
+	}
+
+	return nil
+}
+
+// PropertyFloat gets a property value parsed as a float
+func (r *Reaction) PropertyFloat(prop string) (float64, error) {
+	value, err := r.GetProperty(prop)
+	if err != nil {
+		return 0, err
+	}
+	return property.ParseFloat(prop, value)
+}
+
+// PropertyInt gets a property value parsed as an integer
+func (r *Reaction) PropertyInt(prop string) (int, error) {
+	value, err := r.GetProperty(prop)
+	if err != nil {
+		return 0, err
+	}
+	return property.ParseInt(prop, value)
+}
+
+// PropertyBool gets a property value parsed as a boolean
+// Accepts the strconv.ParseBool forms as well as yes/no, case-insensitive
+func (r *Reaction) PropertyBool(prop string) (bool, error) {
+	value, err := r.GetProperty(prop)
+	if err != nil {
+		return false, err
+	}
+	return property.ParseBool(prop, value)
+}
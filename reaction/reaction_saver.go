@@ -28,19 +28,21 @@ package reaction
 */
 import "C"
 import (
-	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 // ToRxnfile returns the reaction as an RXN file string
 func (r *Reaction) ToRxnfile() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoRxnfile(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to RXN: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to RXN")
 	}
 
 	return C.GoString(cStr), nil
@@ -48,16 +50,17 @@ func (r *Reaction) ToRxnfile() (string, error) {
 
 // SaveToFile saves the reaction to a file in RXN format
 func (r *Reaction) SaveToFile(filename string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveRxnfileToFile(C.int(r.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save reaction to file %s: %s", filename, getLastError())
+		return session.NativeError(r.Handle, "save reaction to file %s", filename)
 	}
 
 	return nil
@@ -66,13 +69,14 @@ func (r *Reaction) SaveToFile(filename string) error {
 // SaveRxnfile saves the reaction to an output object
 // outputHandle is the Indigo handle of an output object
 func (r *Reaction) SaveRxnfile(outputHandle int) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	ret := int(C.indigoSaveRxnfile(C.int(r.Handle), C.int(outputHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to save reaction to output: %s", getLastError())
+		return session.NativeError(r.Handle, "save reaction to output")
 	}
 
 	return nil
@@ -80,13 +84,14 @@ func (r *Reaction) SaveRxnfile(outputHandle int) error {
 
 // ToCanonicalSmiles converts the reaction to canonical SMILES format
 func (r *Reaction) ToCanonicalSmiles() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCanonicalSmiles(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to canonical SMILES: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to canonical SMILES")
 	}
 
 	return C.GoString(cStr), nil
@@ -97,7 +102,7 @@ func (r *Reaction) ToCanonicalSmiles() (string, error) {
 func CreateStringOutput() (int, error) {
 	handle := int(C.indigoWriteBuffer())
 	if handle < 0 {
-		return 0, fmt.Errorf("failed to create string output: %s", getLastError())
+		return 0, session.NativeError(0, "create string output")
 	}
 	return handle, nil
 }
@@ -106,7 +111,7 @@ func CreateStringOutput() (int, error) {
 func GetStringOutput(outputHandle int) (string, error) {
 	cStr := C.indigoToString(C.int(outputHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get string from output: %s", getLastError())
+		return "", session.NativeError(0, "get string from output")
 	}
 
 	return C.GoString(cStr), nil
@@ -116,20 +121,21 @@ func GetStringOutput(outputHandle int) (string, error) {
 func FreeOutput(outputHandle int) error {
 	ret := int(C.indigoFree(C.int(outputHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to free output: %s", getLastError())
+		return session.NativeError(0, "free output")
 	}
 	return nil
 }
 
 // ToSmarts converts the reaction to SMARTS format
 func (r *Reaction) ToSmarts() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoSmarts(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to SMARTS: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to SMARTS")
 	}
 
 	return C.GoString(cStr), nil
@@ -137,13 +143,14 @@ func (r *Reaction) ToSmarts() (string, error) {
 
 // ToCanonicalSmarts converts the reaction to canonical SMARTS format
 func (r *Reaction) ToCanonicalSmarts() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCanonicalSmarts(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to canonical SMARTS: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to canonical SMARTS")
 	}
 
 	return C.GoString(cStr), nil
@@ -151,9 +158,10 @@ func (r *Reaction) ToCanonicalSmarts() (string, error) {
 
 // ToCXSmiles converts the reaction to ChemAxon Extended SMILES format
 func (r *Reaction) ToCXSmiles() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Set the SMILES saving format to chemaxon
 	cOption := C.CString("smiles-saving-format")
@@ -165,7 +173,7 @@ func (r *Reaction) ToCXSmiles() (string, error) {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to set chemaxon format: %s", getLastError())
+		return "", session.NativeError(r.Handle, "set chemaxon format")
 	}
 
 	// Reset to default after getting the SMILES
@@ -173,7 +181,7 @@ func (r *Reaction) ToCXSmiles() (string, error) {
 
 	cStr := C.indigoSmiles(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to CXSmiles: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to CXSmiles")
 	}
 
 	return C.GoString(cStr), nil
@@ -182,9 +190,10 @@ func (r *Reaction) ToCXSmiles() (string, error) {
 // ToDaylightSmiles converts the reaction to Daylight SMILES format
 // This explicitly uses the Daylight format (as opposed to ChemAxon)
 func (r *Reaction) ToDaylightSmiles() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Explicitly set Daylight format
 	cOption := C.CString("smiles-saving-format")
@@ -194,12 +203,12 @@ func (r *Reaction) ToDaylightSmiles() (string, error) {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to set daylight format: %s", getLastError())
+		return "", session.NativeError(r.Handle, "set daylight format")
 	}
 
 	cStr := C.indigoSmiles(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to Daylight SMILES: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to Daylight SMILES")
 	}
 
 	return C.GoString(cStr), nil
@@ -207,13 +216,14 @@ func (r *Reaction) ToDaylightSmiles() (string, error) {
 
 // ToCML converts the reaction to CML (Chemical Markup Language) format
 func (r *Reaction) ToCML() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCml(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to CML: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to CML")
 	}
 
 	return C.GoString(cStr), nil
@@ -221,16 +231,17 @@ func (r *Reaction) ToCML() (string, error) {
 
 // SaveToCMLFile saves the reaction to a file in CML format
 func (r *Reaction) SaveToCMLFile(filename string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveCmlToFile(C.int(r.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save reaction to CML file %s: %s", filename, getLastError())
+		return session.NativeError(r.Handle, "save reaction to CML file %s", filename)
 	}
 
 	return nil
@@ -238,13 +249,14 @@ func (r *Reaction) SaveToCMLFile(filename string) error {
 
 // ToCDXML converts the reaction to CDXML format
 func (r *Reaction) ToCDXML() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	cStr := C.indigoCdxml(C.int(r.Handle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction to CDXML: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction to CDXML")
 	}
 
 	return C.GoString(cStr), nil
@@ -252,16 +264,17 @@ func (r *Reaction) ToCDXML() (string, error) {
 
 // SaveToCDXMLFile saves the reaction to a file in CDXML format
 func (r *Reaction) SaveToCDXMLFile(filename string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveCdxmlToFile(C.int(r.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save reaction to CDXML file %s: %s", filename, getLastError())
+		return session.NativeError(r.Handle, "save reaction to CDXML file %s", filename)
 	}
 
 	return nil
@@ -269,16 +282,17 @@ func (r *Reaction) SaveToCDXMLFile(filename string) error {
 
 // SaveToCDXFile saves the reaction to a file in CDX format
 func (r *Reaction) SaveToCDXFile(filename string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveCdxToFile(C.int(r.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save reaction to CDX file %s: %s", filename, getLastError())
+		return session.NativeError(r.Handle, "save reaction to CDX file %s", filename)
 	}
 
 	return nil
@@ -286,27 +300,28 @@ func (r *Reaction) SaveToCDXFile(filename string) error {
 
 // ToCDXBase64 converts the reaction to base64-encoded CDX format
 func (r *Reaction) ToCDXBase64() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a buffer for CDX
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(r.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
 	// Save as CDX
 	ret := int(C.indigoSaveCdx(C.int(r.Handle), C.int(bufferHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to save reaction as CDX: %s", getLastError())
+		return "", session.NativeError(r.Handle, "save reaction as CDX")
 	}
 
 	// Convert to base64
 	cStr := C.indigoToBase64String(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to convert reaction CDX to base64: %s", getLastError())
+		return "", session.NativeError(r.Handle, "convert reaction CDX to base64")
 	}
 
 	return C.GoString(cStr), nil
@@ -314,27 +329,28 @@ func (r *Reaction) ToCDXBase64() (string, error) {
 
 // ToJSON converts the reaction to JSON format
 func (r *Reaction) ToJSON() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a string output buffer
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(r.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
 	// Save as JSON
 	ret := int(C.indigoSaveJson(C.int(r.Handle), C.int(bufferHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to save reaction as JSON: %s", getLastError())
+		return "", session.NativeError(r.Handle, "save reaction as JSON")
 	}
 
 	// Get the string
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get reaction JSON string: %s", getLastError())
+		return "", session.NativeError(r.Handle, "get reaction JSON string")
 	}
 
 	return C.GoString(cStr), nil
@@ -342,16 +358,17 @@ func (r *Reaction) ToJSON() (string, error) {
 
 // SaveToJSONFile saves the reaction to a file in JSON format
 func (r *Reaction) SaveToJSONFile(filename string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
 
 	ret := int(C.indigoSaveJsonToFile(C.int(r.Handle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to save reaction to JSON file %s: %s", filename, getLastError())
+		return session.NativeError(r.Handle, "save reaction to JSON file %s", filename)
 	}
 
 	return nil
@@ -359,14 +376,15 @@ func (r *Reaction) SaveToJSONFile(filename string) error {
 
 // ToRDF converts the reaction to RDF (Reaction Data Format) format string
 func (r *Reaction) ToRDF() (string, error) {
-	if r.Closed {
-		return "", fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return "", err
 	}
+	defer session.Exit()
 
 	// Create a buffer for RDF
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return "", fmt.Errorf("failed to create buffer: %s", getLastError())
+		return "", session.NativeError(r.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
@@ -376,26 +394,26 @@ func (r *Reaction) ToRDF() (string, error) {
 
 	saverHandle := int(C.indigoCreateSaver(C.int(bufferHandle), cFormat))
 	if saverHandle < 0 {
-		return "", fmt.Errorf("failed to create RDF saver: %s", getLastError())
+		return "", session.NativeError(r.Handle, "create RDF saver")
 	}
 	defer C.indigoFree(C.int(saverHandle))
 
 	// Append the reaction
 	ret := int(C.indigoAppend(C.int(saverHandle), C.int(r.Handle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to append reaction to RDF: %s", getLastError())
+		return "", session.NativeError(r.Handle, "append reaction to RDF")
 	}
 
 	// Close the saver
 	ret = int(C.indigoClose(C.int(saverHandle)))
 	if ret < 0 {
-		return "", fmt.Errorf("failed to close RDF saver: %s", getLastError())
+		return "", session.NativeError(r.Handle, "close RDF saver")
 	}
 
 	// Get the string
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return "", fmt.Errorf("failed to get RDF string: %s", getLastError())
+		return "", session.NativeError(r.Handle, "get RDF string")
 	}
 
 	return C.GoString(cStr), nil
@@ -403,9 +421,10 @@ func (r *Reaction) ToRDF() (string, error) {
 
 // SaveToRDFFile saves the reaction to a file in RDF format
 func (r *Reaction) SaveToRDFFile(filename string) error {
-	if r.Closed {
-		return fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return err
 	}
+	defer session.Exit()
 
 	cFilename := C.CString(filename)
 	defer C.free(unsafe.Pointer(cFilename))
@@ -413,7 +432,7 @@ func (r *Reaction) SaveToRDFFile(filename string) error {
 	// Create a file output
 	outputHandle := int(C.indigoWriteFile(cFilename))
 	if outputHandle < 0 {
-		return fmt.Errorf("failed to create output file: %s", getLastError())
+		return session.NativeError(r.Handle, "create output file")
 	}
 	defer C.indigoFree(C.int(outputHandle))
 
@@ -423,20 +442,20 @@ func (r *Reaction) SaveToRDFFile(filename string) error {
 
 	saverHandle := int(C.indigoCreateSaver(C.int(outputHandle), cFormat))
 	if saverHandle < 0 {
-		return fmt.Errorf("failed to create RDF saver: %s", getLastError())
+		return session.NativeError(r.Handle, "create RDF saver")
 	}
 	defer C.indigoFree(C.int(saverHandle))
 
 	// Append the reaction
 	ret := int(C.indigoAppend(C.int(saverHandle), C.int(r.Handle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to append reaction to RDF: %s", getLastError())
+		return session.NativeError(r.Handle, "append reaction to RDF")
 	}
 
 	// Close the saver
 	ret = int(C.indigoClose(C.int(saverHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to close RDF saver: %s", getLastError())
+		return session.NativeError(r.Handle, "close RDF saver")
 	}
 
 	return nil
@@ -444,27 +463,28 @@ func (r *Reaction) SaveToRDFFile(filename string) error {
 
 // ToBuffer converts the reaction to a binary buffer
 func (r *Reaction) ToBuffer() ([]byte, error) {
-	if r.Closed {
-		return nil, fmt.Errorf("reaction is closed")
+	if err := r.enter(); err != nil {
+		return nil, err
 	}
+	defer session.Exit()
 
 	// Create a buffer
 	bufferHandle := int(C.indigoWriteBuffer())
 	if bufferHandle < 0 {
-		return nil, fmt.Errorf("failed to create buffer: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "create buffer")
 	}
 	defer C.indigoFree(C.int(bufferHandle))
 
 	// Save reaction to buffer
 	ret := int(C.indigoSaveRxnfile(C.int(r.Handle), C.int(bufferHandle)))
 	if ret < 0 {
-		return nil, fmt.Errorf("failed to save reaction to buffer: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "save reaction to buffer")
 	}
 
 	// Get the buffer content
 	cStr := C.indigoToString(C.int(bufferHandle))
 	if cStr == nil {
-		return nil, fmt.Errorf("failed to get buffer content: %s", getLastError())
+		return nil, session.NativeError(r.Handle, "get buffer content")
 	}
 
 	return []byte(C.GoString(cStr)), nil
@@ -31,12 +31,23 @@ import "C"
 import (
 	"fmt"
 	"unsafe"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/internal/session"
 )
 
 type Renderer struct {
 	Sid                 uint64
 	Options             *RenderOptions
 	RendererInitialized bool
+	session             *session.Session // owning session, nil if unbound
+}
+
+// NewRenderer creates a renderer bound to session s, see core.Indigo.Session
+// The renderer of the session must have been initialized by the caller, as core.Indigo.InitRenderer does
+// Every call on the renderer is pinned to s; a nil session leaves the renderer unbound
+func NewRenderer(s *session.Session, options *RenderOptions) *Renderer {
+	return &Renderer{Sid: s.ID(), Options: options, RendererInitialized: true, session: s}
 }
 
 // DisposeRenderer disposes the Indigo renderer
@@ -46,9 +57,19 @@ func (r *Renderer) DisposeRenderer() error {
 		return nil // Not initialized
 	}
 
+	if r.session.Closed() {
+		// Disposed together with the session
+		r.RendererInitialized = false
+		return nil
+	}
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoRendererDispose(C.ulonglong(r.Sid)))
 	if ret < 0 {
-		return fmt.Errorf("failed to dispose renderer: %s", getLastError())
+		return session.NativeError(0, "dispose renderer")
 	}
 
 	r.RendererInitialized = false
@@ -57,10 +78,15 @@ func (r *Renderer) DisposeRenderer() error {
 
 // ResetRenderer resets all rendering settings to defaults
 func (r *Renderer) ResetRenderer() error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	ret := int(C.indigoRenderReset())
 	r.RendererInitialized = false
 	if ret < 0 {
-		return fmt.Errorf("failed to reset renderer: %s", getLastError())
+		return session.NativeError(0, "reset renderer")
 	}
 	return nil
 }
@@ -69,8 +95,13 @@ func (r *Renderer) ResetRenderer() error {
 // objectHandle: the Indigo handle of the object to render
 // filename: the output file path (e.g., "molecule.png", "reaction.svg")
 func (r *Renderer) RenderToFile(objectHandle int, filename string) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	if objectHandle < 0 {
-		return fmt.Errorf("invalid object handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid object handle")
 	}
 
 	cFilename := C.CString(filename)
@@ -78,7 +109,7 @@ func (r *Renderer) RenderToFile(objectHandle int, filename string) error {
 
 	ret := int(C.indigoRenderToFile(C.int(objectHandle), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to render to file %s: %s", filename, getLastError())
+		return session.NativeError(0, "render to file %s", filename)
 	}
 
 	return nil
@@ -88,16 +119,21 @@ func (r *Renderer) RenderToFile(objectHandle int, filename string) error {
 // objectHandle: the Indigo handle of the object to render
 // outputHandle: the Indigo handle of the output buffer (from indigoWriteBuffer or indigoWriteFile)
 func (r *Renderer) Render(objectHandle int, outputHandle int) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	if objectHandle < 0 {
-		return fmt.Errorf("invalid object handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid object handle")
 	}
 	if outputHandle < 0 {
-		return fmt.Errorf("invalid output handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid output handle")
 	}
 
 	ret := int(C.indigoRender(C.int(objectHandle), C.int(outputHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to render: %s", getLastError())
+		return session.NativeError(0, "render")
 	}
 
 	return nil
@@ -109,11 +145,16 @@ func (r *Renderer) Render(objectHandle int, outputHandle int) error {
 // nColumns: number of columns in the grid
 // filename: the output file path
 func (r *Renderer) RenderGridToFile(arrayHandle int, refAtoms []int, nColumns int, filename string) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	if arrayHandle < 0 {
-		return fmt.Errorf("invalid array handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid array handle")
 	}
 	if nColumns <= 0 {
-		return fmt.Errorf("invalid number of columns: %d", nColumns)
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid number of columns: %d", nColumns)
 	}
 
 	cFilename := C.CString(filename)
@@ -131,7 +172,7 @@ func (r *Renderer) RenderGridToFile(arrayHandle int, refAtoms []int, nColumns in
 
 	ret := int(C.indigoRenderGridToFile(C.int(arrayHandle), refAtomsPtr, C.int(nColumns), cFilename))
 	if ret < 0 {
-		return fmt.Errorf("failed to render grid to file %s: %s", filename, getLastError())
+		return session.NativeError(0, "render grid to file %s", filename)
 	}
 
 	return nil
@@ -143,14 +184,19 @@ func (r *Renderer) RenderGridToFile(arrayHandle int, refAtoms []int, nColumns in
 // nColumns: number of columns in the grid
 // outputHandle: the Indigo handle of the output buffer
 func (r *Renderer) RenderGrid(arrayHandle int, refAtoms []int, nColumns int, outputHandle int) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	if arrayHandle < 0 {
-		return fmt.Errorf("invalid array handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid array handle")
 	}
 	if nColumns <= 0 {
-		return fmt.Errorf("invalid number of columns: %d", nColumns)
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid number of columns: %d", nColumns)
 	}
 	if outputHandle < 0 {
-		return fmt.Errorf("invalid output handle")
+		return indigoerr.New(indigoerr.CATEGORY_INVALID, "invalid output handle")
 	}
 
 	var refAtomsPtr *C.int
@@ -165,7 +211,7 @@ func (r *Renderer) RenderGrid(arrayHandle int, refAtoms []int, nColumns int, out
 
 	ret := int(C.indigoRenderGrid(C.int(arrayHandle), refAtomsPtr, C.int(nColumns), C.int(outputHandle)))
 	if ret < 0 {
-		return fmt.Errorf("failed to render grid: %s", getLastError())
+		return session.NativeError(0, "render grid")
 	}
 
 	return nil
@@ -185,6 +231,11 @@ func (r *Renderer) RenderGrid(arrayHandle int, refAtoms []int, nColumns int, out
 //   - "render-stereo-style": "none", "old", "ext", "bondmark"
 //   - "render-label-mode": "hetero", "terminal-hetero", "all", "none"
 func (r *Renderer) SetRenderOption(option string, value string) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	cOption := C.CString(option)
 	defer C.free(unsafe.Pointer(cOption))
 
@@ -193,7 +244,7 @@ func (r *Renderer) SetRenderOption(option string, value string) error {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return fmt.Errorf("failed to set render option %s: %s", option, getLastError())
+		return session.NativeError(0, "set render option %s", option)
 	}
 
 	return nil
@@ -201,6 +252,11 @@ func (r *Renderer) SetRenderOption(option string, value string) error {
 
 // SetRenderOptionInt sets a rendering option with an integer value
 func (r *Renderer) SetRenderOptionInt(option string, value int) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	cOption := C.CString(option)
 	defer C.free(unsafe.Pointer(cOption))
 
@@ -209,7 +265,7 @@ func (r *Renderer) SetRenderOptionInt(option string, value int) error {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return fmt.Errorf("failed to set render option %s: %s", option, getLastError())
+		return session.NativeError(0, "set render option %s", option)
 	}
 
 	return nil
@@ -217,6 +273,11 @@ func (r *Renderer) SetRenderOptionInt(option string, value int) error {
 
 // SetRenderOptionFloat sets a rendering option with a float value
 func (r *Renderer) SetRenderOptionFloat(option string, value float64) error {
+	if err := session.Enter(r.session); err != nil {
+		return err
+	}
+	defer session.Exit()
+
 	cOption := C.CString(option)
 	defer C.free(unsafe.Pointer(cOption))
 
@@ -225,7 +286,7 @@ func (r *Renderer) SetRenderOptionFloat(option string, value float64) error {
 
 	ret := int(C.indigoSetOption(cOption, cValue))
 	if ret < 0 {
-		return fmt.Errorf("failed to set render option %s: %s", option, getLastError())
+		return session.NativeError(0, "set render option %s", option)
 	}
 
 	return nil
@@ -322,12 +383,3 @@ func (r *Renderer) Apply() error {
 
 	return nil
 }
-
-// getLastError retrieves the last error message from Indigo
-func getLastError() string {
-	errMsg := C.indigoGetLastError()
-	if errMsg == nil {
-		return "unknown error"
-	}
-	return C.GoString(errMsg)
-}
@@ -0,0 +1,264 @@
+// Package bingo_test provides tests for the Bingo NoSQL database
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : bingo_test.go
+// @Software: GoLand
+package bingo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/bingo"
+	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+var indigoInit *core.Indigo
+
+func init() {
+	handle, err := core.IndigoInit()
+	if err != nil {
+		panic(err)
+	}
+	indigoInit = handle
+}
+
+// createTestDatabase creates a molecule database filled with a few compounds
+func createTestDatabase(t *testing.T) *bingo.Database {
+	db, err := bingo.CreateDatabaseFile(indigoInit.Session(), t.TempDir(), bingo.DB_MOLECULE, "")
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+
+	for i, smiles := range []string{"c1ccccc1", "c1ccccc1O", "CCO", "CC(=O)O"} {
+		mol, err := indigoInit.LoadMoleculeFromString(smiles)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", smiles, err)
+		}
+		if _, err := db.InsertWithID(mol, i+1); err != nil {
+			t.Fatalf("failed to insert %s: %v", smiles, err)
+		}
+		mol.Close()
+	}
+
+	return db
+}
+
+func TestBingoInsertAndGet(t *testing.T) {
+	db := createTestDatabase(t)
+	defer db.Close()
+
+	mol, err := db.GetMolecule(3)
+	if err != nil {
+		t.Fatalf("failed to get record: %v", err)
+	}
+	defer mol.Close()
+
+	smiles, err := mol.ToCanonicalSmiles()
+	if err != nil {
+		t.Fatalf("failed to get SMILES: %v", err)
+	}
+	if smiles != "CCO" {
+		t.Errorf("expected CCO, got %s", smiles)
+	}
+
+	if err := db.Delete(3); err != nil {
+		t.Fatalf("failed to delete record: %v", err)
+	}
+	if _, err := db.GetMolecule(3); err == nil {
+		t.Error("expected error getting deleted record")
+	}
+}
+
+func TestBingoSearchSub(t *testing.T) {
+	db := createTestDatabase(t)
+	defer db.Close()
+
+	query, err := indigoInit.LoadQueryMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	res, err := db.SearchSub(query, "")
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	defer res.Close()
+
+	ids := map[int]bool{}
+	for res.Next() {
+		id, err := res.ID()
+		if err != nil {
+			t.Fatalf("failed to get id: %v", err)
+		}
+		ids[id] = true
+
+		mol, err := res.Molecule()
+		if err != nil {
+			t.Fatalf("failed to get molecule: %v", err)
+		}
+		mol.Close()
+	}
+	if err := res.Err(); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	if len(ids) != 2 || !ids[1] || !ids[2] {
+		t.Errorf("expected records 1 and 2, got %v", ids)
+	}
+}
+
+func TestBingoSearchSim(t *testing.T) {
+	db := createTestDatabase(t)
+	defer db.Close()
+
+	query, err := indigoInit.LoadMoleculeFromString("c1ccccc1O")
+	if err != nil {
+		t.Fatalf("failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	res, err := db.SearchSimTopN(query, 1, 0.1, "tanimoto")
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	defer res.Close()
+
+	if !res.Next() {
+		t.Fatalf("expected a result: %v", res.Err())
+	}
+
+	id, _ := res.ID()
+	if id != 2 {
+		t.Errorf("expected record 2 as best hit, got %d", id)
+	}
+
+	sim, err := res.Similarity()
+	if err != nil {
+		t.Fatalf("failed to get similarity: %v", err)
+	}
+	if sim < 0.99 {
+		t.Errorf("expected similarity 1.0 for identical molecule, got %f", sim)
+	}
+}
+
+func TestBingoSearchExactAndFormula(t *testing.T) {
+	db := createTestDatabase(t)
+	defer db.Close()
+
+	query, err := indigoInit.LoadMoleculeFromString("OCC")
+	if err != nil {
+		t.Fatalf("failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	res, err := db.SearchExact(query, "")
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	defer res.Close()
+
+	if !res.Next() {
+		t.Fatalf("expected an exact hit: %v", res.Err())
+	}
+	if id, _ := res.ID(); id != 3 {
+		t.Errorf("expected record 3, got %d", id)
+	}
+
+	formula, err := db.SearchMolFormula("C6 H6", "")
+	if err != nil {
+		t.Fatalf("failed to search formula: %v", err)
+	}
+	defer formula.Close()
+
+	count := 0
+	for formula.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("expected 1 formula hit, got %d", count)
+	}
+}
+
+func TestBingoReload(t *testing.T) {
+	dir := t.TempDir()
+	db, err := bingo.CreateDatabaseFile(indigoInit.Session(), dir, bingo.DB_MOLECULE, "")
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+
+	mol, err := indigoInit.LoadMoleculeFromString("CCN")
+	if err != nil {
+		t.Fatalf("failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	id, err := db.Insert(mol)
+	if err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close database: %v", err)
+	}
+
+	reloaded, err := bingo.LoadDatabaseFile(indigoInit.Session(), dir, "")
+	if err != nil {
+		t.Fatalf("failed to load database: %v", err)
+	}
+	defer reloaded.Close()
+
+	got, err := reloaded.GetMolecule(id)
+	if err != nil {
+		t.Fatalf("failed to get record after reload: %v", err)
+	}
+	got.Close()
+}
+
+func TestBingoSession(t *testing.T) {
+	db := createTestDatabase(t)
+	defer db.Close()
+
+	res, err := db.SearchExact(mustLoad(t, indigoInit, "CCO"), "")
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	defer res.Close()
+
+	if !res.Next() {
+		t.Fatalf("expected a result, got %v", res.Err())
+	}
+	mol, err := res.Molecule()
+	if err != nil {
+		t.Fatalf("failed to get result molecule: %v", err)
+	}
+	defer mol.Close()
+	if mol.Session() != indigoInit.Session() {
+		t.Error("expected the result to belong to the database session")
+	}
+
+	other, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer other.Close()
+
+	if _, err := db.Insert(mustLoad(t, other, "CCC")); !errors.Is(err, indigoerr.ErrWrongSession) {
+		t.Errorf("expected ErrWrongSession, got %v", err)
+	}
+}
+
+// mustLoad loads a molecule that is closed when the test ends
+func mustLoad(t *testing.T, in *core.Indigo, smiles string) *molecule.Molecule {
+	mol, err := in.LoadMoleculeFromString(smiles)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", smiles, err)
+	}
+	t.Cleanup(func() { mol.Close() })
+	return mol
+}
@@ -0,0 +1,342 @@
+// Package core_test provides tests for parallel batch processing
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_batch_test.go
+// @Software: GoLand
+package core_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+func countAtoms(in *core.Indigo, index int, input string) (interface{}, error) {
+	mol, err := in.LoadMoleculeFromString(input)
+	if err != nil {
+		return nil, err
+	}
+	defer mol.Close()
+
+	return mol.CountAtoms()
+}
+
+func TestBatchOrdered(t *testing.T) {
+	pool, err := core.NewSessionPool(3, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	inputs := []string{"C", "CC", "not a smiles", "CCC", "CCCC", "CCCCC", "c1ccccc1"}
+	it := core.Batch(context.Background(), pool, core.SliceSource(inputs), countAtoms, &core.BatchOptions{Ordered: true, Window: 2})
+	results, err := it.Collect()
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	if len(results) != len(inputs) {
+		t.Fatalf("Expected %d results, got %d", len(inputs), len(results))
+	}
+
+	expected := []int{1, 2, -1, 3, 4, 5, 6}
+	for i, res := range results {
+		if res.Index != i || res.Input != inputs[i] {
+			t.Errorf("Expected result %d for %s, got %d for %s", i, inputs[i], res.Index, res.Input)
+		}
+		if expected[i] < 0 {
+			if res.Err == nil {
+				t.Errorf("Expected error for input %d", i)
+			}
+			continue
+		}
+		if res.Err != nil || res.Value.(int) != expected[i] {
+			t.Errorf("Expected %d atoms for input %d, got %v (%v)", expected[i], i, res.Value, res.Err)
+		}
+	}
+
+	if stats := pool.Stats(); stats.InUse != 0 || stats.Gets != uint64(len(inputs)) {
+		t.Errorf("Expected all sessions back in the pool, got %+v", stats)
+	}
+}
+
+func TestBatchUnorderedLines(t *testing.T) {
+	pool, err := core.NewSessionPool(2, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	src := core.LineSource(strings.NewReader("CCO\n\nCC(=O)O>>CC(=O)OC\nc1ccccc1>>c1ccccc1Cl\n"))
+	it := core.Batch(context.Background(), pool, src, func(in *core.Indigo, index int, input string) (interface{}, error) {
+		rxn, err := in.LoadReactionFromString(input)
+		if err != nil {
+			return nil, err
+		}
+		defer rxn.Close()
+
+		return rxn.CountReactants()
+	}, nil)
+	defer it.Close()
+
+	seen := make(map[int]bool)
+	failed := 0
+	for it.Next() {
+		res := it.Result()
+		seen[res.Index] = true
+		if res.Err != nil {
+			failed++
+			continue
+		}
+		if res.Value.(int) != 1 {
+			t.Errorf("Expected 1 reactant for %s, got %v", res.Input, res.Value)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	// The empty line is skipped; the plain molecule is not a reaction
+	if len(seen) != 3 || failed != 1 {
+		t.Errorf("Expected 3 results with 1 failure, got %d with %d failures", len(seen), failed)
+	}
+}
+
+func TestBatchCancel(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	inputs := make([]string, 100)
+	for i := range inputs {
+		inputs[i] = "CCO"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := core.Batch(ctx, pool, core.SliceSource(inputs), countAtoms, &core.BatchOptions{Ordered: true})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+		if count == 3 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", it.Err())
+	}
+	if count >= len(inputs) {
+		t.Errorf("Expected the batch to stop early, got %d results", count)
+	}
+}
+
+const batchSDF = `ethanol
+  test
+
+  3  2  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    1.2990    0.7500    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    2.5981    0.0000    0.0000 O   0  0  0  0  0  0  0  0  0  0  0  0
+  1  2  1  0  0  0  0
+  2  3  1  0  0  0  0
+M  END
+> <ID>
+MOL-1
+
+$$$$
+broken
+  test
+
+  2  1  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 Xx  0  0  0  0  0  0  0  0  0  0  0  0
+M  END
+> <ID>
+MOL-2
+
+$$$$
+`
+
+func TestBatchRecords(t *testing.T) {
+	pool, err := core.NewSessionPool(2, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	rd, err := in.IterateSDF(strings.NewReader(batchSDF))
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer rd.Close()
+
+	it := core.Batch(context.Background(), pool, core.RecordSource(rd), countAtoms, &core.BatchOptions{Ordered: true})
+	results, err := it.Collect()
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	for i, res := range results {
+		if res.Record == nil || res.Record.Properties["ID"] != []string{"MOL-1", "MOL-2"}[i] {
+			t.Errorf("Expected record with its data fields for input %d, got %+v", i, res.Record)
+			continue
+		}
+		if res.Record.Molecule != nil {
+			t.Errorf("Expected record %d not to be parsed by the reader", i)
+		}
+	}
+	if results[0].Err != nil || results[0].Value.(int) != 3 {
+		t.Errorf("Expected 3 atoms for the first record, got %v (%v)", results[0].Value, results[0].Err)
+	}
+	if results[1].Err == nil {
+		t.Error("Expected error for the broken record")
+	}
+}
+
+// countingSource wraps a record source, hiding its concrete type
+type countingSource struct {
+	core.RecordBatchSource
+	count int
+}
+
+func (s *countingSource) Next() (string, bool) {
+	input, ok := s.RecordBatchSource.Next()
+	if ok {
+		s.count++
+	}
+	return input, ok
+}
+
+func TestBatchWrappedRecordSource(t *testing.T) {
+	pool, err := core.NewSessionPool(2, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	rd, err := in.IterateSDF(strings.NewReader(batchSDF))
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer rd.Close()
+
+	src, ok := core.RecordSource(rd).(core.RecordBatchSource)
+	if !ok {
+		t.Fatal("Expected RecordSource to implement RecordBatchSource")
+	}
+	wrapped := &countingSource{RecordBatchSource: src}
+
+	it := core.Batch(context.Background(), pool, wrapped, countAtoms, &core.BatchOptions{Ordered: true})
+	results, err := it.Collect()
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if wrapped.count != 2 || len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for i, res := range results {
+		if res.Record == nil || res.Record.Properties["ID"] != []string{"MOL-1", "MOL-2"}[i] {
+			t.Errorf("Expected the wrapped source to keep record %d, got %+v", i, res.Record)
+		}
+	}
+}
+
+func TestBatchNilRecordSource(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	it := core.Batch(context.Background(), pool, core.RecordSource(nil), countAtoms, nil)
+	results, err := it.Collect()
+	if !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestBatchCDXRecordSource(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	rd, err := in.IterateCDX(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer rd.Close()
+
+	it := core.Batch(context.Background(), pool, core.RecordSource(rd), countAtoms, nil)
+	results, err := it.Collect()
+	if !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestBatchNilContext(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	it := core.Batch(nil, pool, core.SliceSource([]string{"CCO"}), countAtoms, nil)
+	results, err := it.Collect()
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Value != 3 {
+		t.Errorf("Expected one result with 3 atoms, got %+v", results)
+	}
+}
+
+func TestBatchNilPool(t *testing.T) {
+	it := core.Batch(context.Background(), nil, core.SliceSource([]string{"C"}), countAtoms, nil)
+	results, err := it.Collect()
+	if !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
@@ -0,0 +1,151 @@
+// Package core_test provides tests for the native error handler
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_errors_test.go
+// @Software: GoLand
+package core_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+func TestErrorHandler(t *testing.T) {
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	var messages []string
+	in.SetErrorHandler(func(msg string) {
+		messages = append(messages, msg)
+	})
+
+	_, err = in.LoadMoleculeFromString("C1CC(")
+	var ierr *indigoerr.IndigoError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("Expected IndigoError, got %v", err)
+	}
+	if len(messages) == 0 || messages[len(messages)-1] != ierr.Message {
+		t.Errorf("Expected handler to receive %q, got %v", ierr.Message, messages)
+	}
+
+	in.SetErrorHandler(nil)
+	count := len(messages)
+	if _, err := in.LoadMoleculeFromString("C1CC("); err == nil {
+		t.Fatal("Expected error for invalid SMILES")
+	}
+	if len(messages) != count {
+		t.Errorf("Expected no messages after removing the handler, got %v", messages[count:])
+	}
+}
+
+func TestErrorHandlerPerSession(t *testing.T) {
+	first, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer first.Close()
+
+	second, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer second.Close()
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	handler := func(name string) func(string) {
+		return func(string) {
+			mu.Lock()
+			counts[name]++
+			mu.Unlock()
+		}
+	}
+	first.SetErrorHandler(handler("first"))
+	second.SetErrorHandler(handler("second"))
+
+	var wg sync.WaitGroup
+	for _, in := range []*core.Indigo{first, second, second} {
+		wg.Add(1)
+		go func(in *core.Indigo) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				mol, err := in.LoadMoleculeFromString("CCO")
+				if err != nil {
+					t.Errorf("Failed to load molecule: %v", err)
+					return
+				}
+				mol.Close()
+			}
+		}(in)
+	}
+	wg.Wait()
+
+	if _, err := first.LoadMoleculeFromString("not a smiles"); err == nil {
+		t.Fatal("Expected error for invalid SMILES")
+	}
+	if _, err := second.LoadReactionFromString("CCO"); err == nil {
+		t.Fatal("Expected error for a molecule loaded as reaction")
+	}
+
+	if counts["first"] != 1 || counts["second"] != 1 {
+		t.Errorf("Expected one error per session, got %v", counts)
+	}
+}
+
+func TestErrorMessagePerCall(t *testing.T) {
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	if _, err := in.LoadMoleculeFromString("C1CC("); err == nil {
+		t.Fatal("Expected error for invalid SMILES")
+	}
+
+	// A later failure reports its own message, not the one left behind by the previous call
+	mol, err := in.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	_, err = mol.GetAtom(100)
+	if err == nil {
+		t.Fatal("Expected error for atom index out of range")
+	}
+	if strings.Contains(err.Error(), "SMILES") {
+		t.Errorf("Expected message of the failing call, got %v", err)
+	}
+}
+
+func TestLogErrorHandler(t *testing.T) {
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	var buf bytes.Buffer
+	in.SetErrorHandler(core.LogErrorHandler(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	if _, err := in.LoadMoleculeFromString("C1CC("); err == nil {
+		t.Fatal("Expected error for invalid SMILES")
+	}
+	if !strings.Contains(buf.String(), "indigo error") {
+		t.Errorf("Expected the error to be logged, got %q", buf.String())
+	}
+}
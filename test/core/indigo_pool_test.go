@@ -0,0 +1,220 @@
+// Package core_test provides tests for the session pool
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : indigo_pool_test.go
+// @Software: GoLand
+package core_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cx-luo/go-indigo/core"
+)
+
+func TestSessionPoolGetPut(t *testing.T) {
+	pool, err := core.NewSessionPool(2, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	a, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	b, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if a == b {
+		t.Fatal("Expected two distinct sessions")
+	}
+
+	stats := pool.Stats()
+	if stats.Size != 2 || stats.InUse != 2 || stats.Idle != 0 {
+		t.Errorf("Unexpected stats with all sessions borrowed: %+v", stats)
+	}
+
+	// The pool is empty, so Get waits until the context expires
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	pool.Put(a)
+	pool.Put(b)
+
+	stats = pool.Stats()
+	if stats.InUse != 0 || stats.Idle != 2 || stats.Gets != 2 || stats.Waits != 1 {
+		t.Errorf("Unexpected stats after Put: %+v", stats)
+	}
+}
+
+func TestSessionPoolPresets(t *testing.T) {
+	pool, err := core.NewSessionPool(1, &core.PoolOptions{
+		Presets: []core.SessionOption{{Name: "ignore-stereochemistry-errors", Values: []interface{}{true}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	in, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if value, err := in.GetOption("ignore-stereochemistry-errors"); err != nil || value != "true" {
+		t.Errorf("Expected preset option to be true, got %q (%v)", value, err)
+	}
+
+	if err := in.SetOption("ignore-stereochemistry-errors", false, nil, nil); err != nil {
+		t.Fatalf("SetOption failed: %v", err)
+	}
+	pool.Put(in)
+
+	in, err = pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer pool.Put(in)
+
+	if value, err := in.GetOption("ignore-stereochemistry-errors"); err != nil || value != "true" {
+		t.Errorf("Expected preset option to be reapplied, got %q (%v)", value, err)
+	}
+}
+
+func TestSessionPoolResetsErrorHandler(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	in, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	calls := 0
+	in.SetErrorHandler(func(msg string) {
+		calls++
+	})
+	pool.Put(in)
+
+	in, err = pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer pool.Put(in)
+
+	if _, err := in.LoadMoleculeFromString("not a molecule"); err == nil {
+		t.Fatal("Expected error for an invalid molecule")
+	}
+	if calls != 0 {
+		t.Errorf("Expected the handler of the previous borrower to be removed, got %d calls", calls)
+	}
+}
+
+func TestSessionPoolMaxUses(t *testing.T) {
+	pool, err := core.NewSessionPool(1, &core.PoolOptions{MaxUses: 2})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	for i := 0; i < 2; i++ {
+		in, err := pool.Get(context.Background())
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+
+		mol, err := in.LoadMoleculeFromString("CCO")
+		if err != nil {
+			t.Fatalf("Failed to load molecule: %v", err)
+		}
+		pool.Put(in)
+
+		// Objects of a recycled session are no longer usable
+		_, err = mol.CountAtoms()
+		if i == 1 && err == nil {
+			t.Error("Expected error for a molecule of a recycled session")
+		}
+	}
+
+	if stats := pool.Stats(); stats.Recycles != 1 || stats.Size != 1 {
+		t.Errorf("Expected one recycle, got %+v", stats)
+	}
+}
+
+func TestSessionPoolClose(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+
+	in, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := pool.Get(context.Background())
+		waiting <- err
+	}()
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := <-waiting; !errors.Is(err, core.ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed for a pending Get, got %v", err)
+	}
+
+	// A session put back after Close is released
+	pool.Put(in)
+	if _, err := in.LoadMoleculeFromString("C"); err == nil {
+		t.Error("Expected error for using a session released by the pool")
+	}
+	if stats := pool.Stats(); stats.Size != 0 {
+		t.Errorf("Expected empty pool after Close, got %+v", stats)
+	}
+}
+
+func TestSessionPoolInvalidSize(t *testing.T) {
+	if _, err := core.NewSessionPool(0, nil); err == nil {
+		t.Error("Expected error for an empty pool")
+	}
+}
+
+func TestSessionPoolDoublePut(t *testing.T) {
+	pool, err := core.NewSessionPool(1, nil)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	in, err := pool.Get(nil)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	pool.Put(in)
+	pool.Put(in)
+
+	if stats := pool.Stats(); stats.Size != 1 || stats.Idle != 1 {
+		t.Errorf("Expected one idle session, got %+v", stats)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	in, err = pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get session after double put: %v", err)
+	}
+	pool.Put(in)
+}
@@ -141,7 +141,7 @@ func TestBondOperations(t *testing.T) {
 		t.Fatalf("Failed to get bond: %v", err)
 	}
 
-	order, err := molecule.BondOrder(bond)
+	order, err := bond.Order()
 	if err != nil {
 		t.Fatalf("Failed to get bond order: %v", err)
 	}
@@ -151,18 +151,129 @@ func TestBondOperations(t *testing.T) {
 	}
 
 	// Test bond source and destination
-	source, err := molecule.BondSource(bond)
+	source, err := bond.Begin()
 	if err != nil {
 		t.Fatalf("Failed to get bond source: %v", err)
 	}
 
-	dest, err := molecule.BondDestination(bond)
+	dest, err := bond.End()
 	if err != nil {
 		t.Fatalf("Failed to get bond destination: %v", err)
 	}
 
-	if source < 0 || dest < 0 {
-		t.Errorf("Invalid bond source/destination: %d, %d", source, dest)
+	srcIndex, _ := source.Index()
+	dstIndex, _ := dest.Index()
+	if srcIndex < 0 || dstIndex < 0 || srcIndex == dstIndex {
+		t.Errorf("Invalid bond source/destination: %d, %d", srcIndex, dstIndex)
+	}
+}
+
+func TestBondTopology(t *testing.T) {
+	// Toluene: bond 0 is the methyl (chain) bond, the rest are ring bonds
+	mol, err := indigoInit.LoadMoleculeFromString("Cc1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	chain, err := mol.GetBond(0)
+	if err != nil {
+		t.Fatalf("Failed to get bond: %v", err)
+	}
+	topology, err := chain.Topology()
+	if err != nil {
+		t.Fatalf("Failed to get topology: %v", err)
+	}
+	if topology != molecule.TOPOLOGY_CHAIN {
+		t.Errorf("Expected chain bond, got %d", topology)
+	}
+
+	ring, err := mol.GetBond(1)
+	if err != nil {
+		t.Fatalf("Failed to get bond: %v", err)
+	}
+	topology, _ = ring.Topology()
+	if topology != molecule.TOPOLOGY_RING {
+		t.Errorf("Expected ring bond, got %d", topology)
+	}
+
+	aromatic, err := ring.IsAromatic()
+	if err != nil {
+		t.Fatalf("Failed to check aromaticity: %v", err)
+	}
+	if !aromatic {
+		t.Error("Expected ring bond to be aromatic")
+	}
+}
+
+func TestBondSetOrderAndRemove(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCC")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	bond, _ := mol.GetBond(0)
+	if err := bond.SetOrder(molecule.BOND_DOUBLE); err != nil {
+		t.Fatalf("Failed to set bond order: %v", err)
+	}
+
+	smiles, _ := mol.ToSmiles()
+	if smiles != "C=CC" {
+		t.Errorf("Expected C=CC, got %s", smiles)
+	}
+
+	if err := bond.Remove(); err != nil {
+		t.Fatalf("Failed to remove bond: %v", err)
+	}
+
+	count, _ := mol.CountBonds()
+	if count != 1 {
+		t.Errorf("Expected 1 bond after removal, got %d", count)
+	}
+}
+
+func TestAtomNeighbors(t *testing.T) {
+	// Isobutane: the central carbon has three neighbors
+	mol, err := indigoInit.LoadMoleculeFromString("CC(C)C")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	atom, _ := mol.GetAtom(1)
+	defer atom.Close()
+	neighbors, err := atom.Neighbors()
+	if err != nil {
+		t.Fatalf("Failed to get neighbors: %v", err)
+	}
+
+	if len(neighbors) != 3 {
+		t.Fatalf("Expected 3 neighbors, got %d", len(neighbors))
+	}
+
+	defer molecule.CloseNeighbors(neighbors)
+
+	seen := make(map[int]bool)
+	for _, nei := range neighbors {
+		index, err := nei.Atom.Index()
+		if err != nil {
+			t.Fatalf("Failed to get neighbor index: %v", err)
+		}
+		if index == 1 || seen[index] {
+			t.Errorf("Unexpected neighbor index %d", index)
+		}
+		seen[index] = true
+
+		symbol, _ := nei.Atom.Symbol()
+		if symbol != "C" {
+			t.Errorf("Expected carbon neighbor, got %s", symbol)
+		}
+
+		order, _ := nei.Bond.Order()
+		if order != molecule.BOND_SINGLE {
+			t.Errorf("Expected single bond to neighbor, got %d", order)
+		}
 	}
 }
 
@@ -205,3 +316,40 @@ func TestImplicitHydrogens(t *testing.T) {
 		t.Logf("Warning: Expected 4 implicit H on C, got %d", implicitH)
 	}
 }
+
+func TestAtomHybridization(t *testing.T) {
+	// Acetonitrile: sp3 methyl carbon, sp nitrile carbon
+	mol, err := indigoInit.LoadMoleculeFromString("CC#N")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	expected := []int{molecule.HYBRIDIZATION_SP3, molecule.HYBRIDIZATION_SP}
+	for i, want := range expected {
+		atom, err := mol.GetAtom(i)
+		if err != nil {
+			t.Fatalf("Failed to get atom %d: %v", i, err)
+		}
+
+		got, err := atom.Hybridization()
+		atom.Close()
+		if err != nil {
+			t.Fatalf("Failed to get hybridization: %v", err)
+		}
+		if got != want {
+			t.Errorf("Atom %d: expected hybridization %d, got %d", i, want, got)
+		}
+	}
+
+	atom, _ := mol.GetAtom(0)
+	defer atom.Close()
+
+	hydrogens, err := atom.CountHydrogens()
+	if err != nil {
+		t.Fatalf("Failed to count hydrogens: %v", err)
+	}
+	if hydrogens != 3 {
+		t.Errorf("Expected 3 hydrogens on methyl carbon, got %d", hydrogens)
+	}
+}
@@ -245,7 +245,7 @@ func TestBuilderSetCharge(t *testing.T) {
 	o, _ := m.AddAtom("O")
 
 	// Set charge to -1
-	err := molecule.SetCharge(o, -1)
+	err := m.SetCharge(o, -1)
 	if err != nil {
 		t.Errorf("failed to set charge: %v", err)
 	}
@@ -259,7 +259,7 @@ func TestBuilderSetIsotope(t *testing.T) {
 	h, _ := m.AddAtom("H")
 
 	// Set isotope to 2 (deuterium)
-	err := molecule.SetIsotope(h, 2)
+	err := m.SetIsotope(h, 2)
 	if err != nil {
 		t.Errorf("failed to set isotope: %v", err)
 	}
@@ -307,3 +307,17 @@ func TestBuildMoleculeWithHydrogens(t *testing.T) {
 		t.Errorf("expected 4 bonds, got %d", bondCount)
 	}
 }
+
+// TestBuilderClosedMolecule tests that handle setters go through the owning molecule
+func TestBuilderClosedMolecule(t *testing.T) {
+	m, _ := indigoInit.CreateMolecule()
+	c, _ := m.AddAtom("C")
+	m.Close()
+
+	if err := m.SetCharge(c, 1); err == nil {
+		t.Error("expected error when setting charge on a closed molecule")
+	}
+	if err := m.ResetAtom(c, "N"); err == nil {
+		t.Error("expected error when resetting atom of a closed molecule")
+	}
+}
@@ -0,0 +1,165 @@
+// Package molecule_test provides tests for structure checking
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_check_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestCheckValidMolecule(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	report, err := mol.Check(molecule.CHECK_VALENCE, molecule.CHECK_RADICALS)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected no issues, got %v", report.Messages())
+	}
+
+	msg, err := mol.CheckBadValence()
+	if err != nil {
+		t.Fatalf("CheckBadValence failed: %v", err)
+	}
+	if msg != "" {
+		t.Errorf("Expected empty bad valence message, got %q", msg)
+	}
+}
+
+func TestCheckBadValence(t *testing.T) {
+	// Pentavalent carbon
+	mol, err := indigoInit.LoadMoleculeFromString("C(C)(C)(C)(C)C")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	report, err := mol.Check(molecule.CHECK_VALENCE)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if report.OK() {
+		t.Fatalf("Expected valence issue, got none (raw: %s)", report.Raw)
+	}
+	if len(report.ByCheck(molecule.CHECK_VALENCE)) == 0 {
+		t.Errorf("Expected issue reported by the valence check, got %+v", report.Issues)
+	}
+
+	msg, err := mol.CheckBadValence()
+	if err != nil {
+		t.Fatalf("CheckBadValence failed: %v", err)
+	}
+	if msg == "" {
+		t.Error("Expected bad valence message")
+	}
+
+	atom, _ := mol.GetAtom(0)
+	defer atom.Close()
+
+	ok, err := atom.CheckValence()
+	if err != nil {
+		t.Fatalf("CheckValence failed: %v", err)
+	}
+	if ok {
+		t.Error("Expected central carbon valence to be wrong")
+	}
+}
+
+func TestCheckQueryAndRGroups(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	isQuery, err := mol.CheckQuery()
+	if err != nil {
+		t.Fatalf("CheckQuery failed: %v", err)
+	}
+	if isQuery {
+		t.Error("Expected plain molecule to have no query features")
+	}
+
+	hasRGroups, err := mol.CheckRGroups()
+	if err != nil {
+		t.Fatalf("CheckRGroups failed: %v", err)
+	}
+	if hasRGroups {
+		t.Error("Expected plain molecule to have no R-groups")
+	}
+}
+
+func TestParseCheckReport(t *testing.T) {
+	data, err := os.ReadFile("testdata/check_report.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	report, err := molecule.ParseCheckReport(string(data))
+	if err != nil {
+		t.Fatalf("ParseCheckReport failed: %v", err)
+	}
+	if len(report.Issues) != 4 {
+		t.Fatalf("Expected 4 issues, got %+v", report.Issues)
+	}
+	valence := report.ByCheck(molecule.CHECK_VALENCE)
+	if len(valence) != 1 || len(valence[0].Atoms) != 1 || valence[0].Atoms[0] != 0 || valence[0].Index != -1 {
+		t.Errorf("Unexpected valence issue: %+v", valence)
+	}
+	bonds := report.ByCheck(molecule.CHECK_OVERLAPPING_BONDS)
+	if len(bonds) != 1 || len(bonds[0].Bonds) != 1 || bonds[0].Bonds[0] != 1 || bonds[0].Atoms != nil {
+		t.Errorf("Unexpected overlapping bonds issue: %+v", bonds)
+	}
+	stereo := report.ByCheck(molecule.CHECK_STEREO)
+	// The component container is not an issue, only its subresult is
+	if len(stereo) != 1 || stereo[0].Index != 1 || len(stereo[0].Atoms) != 1 || stereo[0].Atoms[0] != 4 {
+		t.Errorf("Unexpected stereo issues: %+v", stereo)
+	}
+
+	report, err = molecule.ParseCheckReport("{}")
+	if err != nil {
+		t.Fatalf("ParseCheckReport failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected empty report to be OK, got %+v", report.Issues)
+	}
+
+	// Fields added by newer Indigo versions are ignored
+	report, err = molecule.ParseCheckReport(`{"version":2,"checks":[{"id":"valence","message":"bad valence","ids":[1],"level":"error"}]}`)
+	if err != nil {
+		t.Fatalf("ParseCheckReport failed for unknown fields: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Check != molecule.CHECK_VALENCE {
+		t.Errorf("Unexpected issues: %+v", report.Issues)
+	}
+
+	for _, bad := range []string{"not json", `{"checks":"bad valence"}`, `{"checks":[{"message":"no id"}]}`} {
+		if _, err := molecule.ParseCheckReport(bad); !errors.Is(err, indigoerr.ErrParse) {
+			t.Errorf("Expected parse error for %s, got %v", bad, err)
+		}
+	}
+}
+
+func TestCheckFlags(t *testing.T) {
+	if flags := molecule.CheckFlags(); flags != "" {
+		t.Errorf("Expected empty flags, got %q", flags)
+	}
+	if flags := molecule.CheckFlags(molecule.CHECK_VALENCE, molecule.CHECK_AMBIGUOUS_H); flags != "valence;ambiguous_h" {
+		t.Errorf("Unexpected flags %q", flags)
+	}
+}
@@ -0,0 +1,108 @@
+// Package molecule_test provides tests for molecular descriptors and drug-likeness rules
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_descriptors_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestDescriptors(t *testing.T) {
+	// Aspirin
+	mol, err := indigoInit.LoadMoleculeFromString("CC(=O)Oc1ccccc1C(=O)O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	d, err := mol.Descriptors()
+	if err != nil {
+		t.Fatalf("Descriptors failed: %v", err)
+	}
+
+	if d.MolecularWeight < 180 || d.MolecularWeight > 181 {
+		t.Errorf("Expected molecular weight around 180.16, got %f", d.MolecularWeight)
+	}
+	if d.HeavyAtoms != 13 {
+		t.Errorf("Expected 13 heavy atoms, got %d", d.HeavyAtoms)
+	}
+	if d.Hydrogens != 8 {
+		t.Errorf("Expected 8 hydrogens, got %d", d.Hydrogens)
+	}
+	if d.TotalAtoms() != 21 {
+		t.Errorf("Expected 21 atoms in total, got %d", d.TotalAtoms())
+	}
+	if d.Rings != 1 {
+		t.Errorf("Expected 1 ring, got %d", d.Rings)
+	}
+	if d.HBondDonors != 1 {
+		t.Errorf("Expected 1 H-bond donor, got %d", d.HBondDonors)
+	}
+
+	if r := d.Lipinski(); !r.Passed || len(r.Violations) != 0 {
+		t.Errorf("Expected aspirin to pass Lipinski, got %+v", r)
+	}
+	if r := d.Veber(); !r.Passed {
+		t.Errorf("Expected aspirin to pass Veber, got %+v", r)
+	}
+}
+
+func TestDescriptorsClosedMolecule(t *testing.T) {
+	mol, _ := indigoInit.LoadMoleculeFromString("CCO")
+	mol.Close()
+
+	if _, err := mol.Descriptors(); err == nil {
+		t.Error("Expected error on closed molecule")
+	}
+}
+
+func TestLipinskiRule(t *testing.T) {
+	oneViolation := molecule.Descriptors{MolecularWeight: 550, LogP: 3, HBondDonors: 2, HBondAcceptors: 5}
+	r := oneViolation.Lipinski()
+	if !r.Passed || len(r.Violations) != 1 {
+		t.Errorf("Expected pass with one violation, got %+v", r)
+	}
+
+	twoViolations := molecule.Descriptors{MolecularWeight: 550, LogP: 6, HBondDonors: 2, HBondAcceptors: 5}
+	r = twoViolations.Lipinski()
+	if r.Passed || len(r.Violations) != 2 {
+		t.Errorf("Expected failure with two violations, got %+v", r)
+	}
+}
+
+func TestVeberGhoseLeadLikeRules(t *testing.T) {
+	d := molecule.Descriptors{
+		MolecularWeight:   300,
+		LogP:              2,
+		MolarRefractivity: 80,
+		TPSA:              160,
+		RotatableBonds:    4,
+		HeavyAtoms:        22,
+		Hydrogens:         20,
+	}
+
+	if r := d.Veber(); r.Passed || len(r.Violations) != 1 {
+		t.Errorf("Expected Veber TPSA violation, got %+v", r)
+	}
+	if r := d.Ghose(); !r.Passed {
+		t.Errorf("Expected Ghose pass, got %+v", r)
+	}
+	if r := d.LeadLike(); !r.Passed {
+		t.Errorf("Expected lead-like pass, got %+v", r)
+	}
+
+	d.MolecularWeight = 150
+	if r := d.Ghose(); r.Passed || len(r.Violations) != 1 {
+		t.Errorf("Expected Ghose molecular weight violation, got %+v", r)
+	}
+	if r := d.LeadLike(); r.Passed || len(r.Violations) != 1 {
+		t.Errorf("Expected lead-like molecular weight violation, got %+v", r)
+	}
+}
@@ -0,0 +1,137 @@
+// Package molecule_test provides tests for the structured errors
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_errors_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestErrorParse(t *testing.T) {
+	_, err := indigoInit.LoadMoleculeFromString("C1CC(")
+	if err == nil {
+		t.Fatal("Expected error for invalid SMILES")
+	}
+
+	var ierr *indigoerr.IndigoError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("Expected IndigoError, got %T", err)
+	}
+	if ierr.Category != indigoerr.CATEGORY_PARSE || !errors.Is(err, indigoerr.ErrParse) {
+		t.Errorf("Expected parse error, got %s: %v", ierr.Category, err)
+	}
+	if ierr.Op != "load molecule from string" || ierr.Message == "" {
+		t.Errorf("Expected operation and native message, got %+v", ierr)
+	}
+}
+
+func TestErrorClosed(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	mol.Close()
+
+	_, err = mol.CountAtoms()
+	if !errors.Is(err, indigoerr.ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if errors.Is(err, indigoerr.ErrParse) {
+		t.Error("Expected closed error not to match ErrParse")
+	}
+}
+
+func TestErrorNoMatch(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	query, err := indigoInit.LoadQueryMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	_, err = mol.SubstructureMatcher(query)
+	if !errors.Is(err, indigoerr.ErrNoMatch) || !errors.Is(err, indigoerr.ErrNotFound) {
+		t.Errorf("Expected ErrNoMatch, got %v", err)
+	}
+}
+
+func TestErrorWrongSession(t *testing.T) {
+	other, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer other.Close()
+
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	foreign, err := other.LoadMoleculeFromString("CO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer foreign.Close()
+
+	_, _, err = mol.ExactMatch(foreign, nil)
+	if !errors.Is(err, indigoerr.ErrWrongSession) || !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrWrongSession, got %v", err)
+	}
+}
+
+func TestErrorOption(t *testing.T) {
+	err := indigoInit.SetOption("no-such-option", true, nil, nil)
+	if !errors.Is(err, indigoerr.ErrOption) {
+		t.Errorf("Expected ErrOption, got %v", err)
+	}
+}
+
+func TestErrorClassify(t *testing.T) {
+	cases := map[string]indigoerr.Category{
+		"SMILES loader: unexpected end of input":         indigoerr.CATEGORY_PARSE,
+		"element: bad valence on N having 4 drawn bonds": indigoerr.CATEGORY_VALENCE,
+		"option manager: Property \"x\" not defined":     indigoerr.CATEGORY_OPTION,
+		"file scanner: can not open file x.mol":          indigoerr.CATEGORY_IO,
+		"something else went wrong":                      indigoerr.CATEGORY_INTERNAL,
+	}
+	for msg, expected := range cases {
+		if got := indigoerr.Classify(msg); got != expected {
+			t.Errorf("Classify(%q) = %s, expected %s", msg, got, expected)
+		}
+	}
+}
+
+func TestErrorInvalidArguments(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if err := mol.SetProperty("WEIGHT", "heavy"); err != nil {
+		t.Fatalf("Failed to set property: %v", err)
+	}
+	if _, err := mol.PropertyFloat("WEIGHT"); !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a non-numeric property, got %v", err)
+	}
+
+	if _, err := molecule.NewFingerprint("sim", nil).CountBits(); !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for an empty fingerprint, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package molecule_test provides tests for molecule fingerprints
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_fingerprint_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestMoleculeFingerprint(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccccc1O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	fp, err := mol.Fingerprint(molecule.FINGERPRINT_SIM)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	if len(fp.Bytes()) == 0 {
+		t.Fatal("Expected non-empty fingerprint data")
+	}
+
+	count, err := fp.CountBits()
+	if err != nil {
+		t.Fatalf("CountBits failed: %v", err)
+	}
+	if count == 0 {
+		t.Error("Expected phenol fingerprint to have bits set")
+	}
+
+	bits, err := fp.OnBits()
+	if err != nil {
+		t.Fatalf("OnBits failed: %v", err)
+	}
+	if len(bits) != count {
+		t.Errorf("Expected %d on bits, got %d", count, len(bits))
+	}
+}
+
+func TestFingerprintTanimoto(t *testing.T) {
+	phenol, err := indigoInit.LoadMoleculeFromString("c1ccccc1O")
+	if err != nil {
+		t.Fatalf("Failed to load phenol: %v", err)
+	}
+	defer phenol.Close()
+
+	ethanol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load ethanol: %v", err)
+	}
+	defer ethanol.Close()
+
+	fp1, err := phenol.Fingerprint(molecule.FINGERPRINT_SIM)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	fp2, err := ethanol.Fingerprint(molecule.FINGERPRINT_SIM)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	self, err := fp1.Tanimoto(fp1)
+	if err != nil {
+		t.Fatalf("Tanimoto failed: %v", err)
+	}
+	if self < 0.999 {
+		t.Errorf("Expected self similarity 1.0, got %f", self)
+	}
+
+	sim, err := fp1.Tanimoto(fp2)
+	if err != nil {
+		t.Fatalf("Tanimoto failed: %v", err)
+	}
+	if sim >= 1.0 {
+		t.Errorf("Expected phenol/ethanol similarity below 1.0, got %f", sim)
+	}
+
+	if _, err := fp1.Tversky(fp2, 0.5, 0.5); err != nil {
+		t.Errorf("Tversky failed: %v", err)
+	}
+	if _, err := fp1.Euclid(fp2); err != nil {
+		t.Errorf("Euclid failed: %v", err)
+	}
+	if _, err := fp1.CommonBits(fp2); err != nil {
+		t.Errorf("CommonBits failed: %v", err)
+	}
+}
+
+func TestFingerprintBitArithmetic(t *testing.T) {
+	// Bits 0 and 2 against bits 0 and 15: one bit in common out of three
+	fp1 := molecule.NewFingerprint(molecule.FINGERPRINT_SIM, []byte{0x05, 0x00})
+	fp2 := molecule.NewFingerprint(molecule.FINGERPRINT_SIM, []byte{0x01, 0x80})
+
+	if bits, err := fp1.OnBits(); err != nil || !reflect.DeepEqual(bits, []int{0, 2}) {
+		t.Errorf("Expected on bits [0 2], got %v (%v)", bits, err)
+	}
+	if bits, err := fp2.OnBits(); err != nil || !reflect.DeepEqual(bits, []int{0, 15}) {
+		t.Errorf("Expected on bits [0 15], got %v (%v)", bits, err)
+	}
+	if common, err := fp1.CommonBits(fp2); err != nil || common != 1 {
+		t.Errorf("Expected 1 common bit, got %d (%v)", common, err)
+	}
+
+	cases := []struct {
+		metrics string
+		want    float64
+	}{
+		{"tanimoto", 1.0 / 3},
+		{"", 1.0 / 3},
+		{"euclid-sub", 0.5},
+		{"tversky", 0.5},
+		{"tversky 1 0", 0.5},
+	}
+	for _, c := range cases {
+		sim, err := fp1.Similarity(fp2, c.metrics)
+		if err != nil {
+			t.Errorf("Similarity %q failed: %v", c.metrics, err)
+			continue
+		}
+		if math.Abs(sim-c.want) > 1e-9 {
+			t.Errorf("Expected %q similarity %f, got %f", c.metrics, c.want, sim)
+		}
+	}
+
+	if _, err := fp1.Similarity(fp2, "cosine"); err == nil {
+		t.Error("Expected error for unknown metrics")
+	}
+	if _, err := fp1.Tanimoto(molecule.NewFingerprint(molecule.FINGERPRINT_SIM, []byte{0x05})); err == nil {
+		t.Error("Expected error comparing fingerprints of different sizes")
+	}
+}
+
+func TestFingerprintRoundTrip(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CC(=O)Oc1ccccc1C(=O)O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	fp, err := mol.Fingerprint(molecule.FINGERPRINT_SUB)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	// Persisted bytes must compare identical after reloading
+	restored := molecule.NewFingerprint(fp.Type, fp.Bytes())
+	sim, err := fp.Tanimoto(restored)
+	if err != nil {
+		t.Fatalf("Tanimoto failed: %v", err)
+	}
+	if sim < 0.999 {
+		t.Errorf("Expected restored fingerprint to be identical, got similarity %f", sim)
+	}
+
+	other, err := mol.Fingerprint(molecule.FINGERPRINT_SIM)
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	if _, err := fp.Tanimoto(other); err == nil {
+		t.Error("Expected error comparing fingerprints of different types")
+	}
+}
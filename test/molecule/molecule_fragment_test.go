@@ -0,0 +1,218 @@
+// Package molecule_test provides tests for fragment selection and salt stripping
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_fragment_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+// rgroupMolfile is a methyl scaffold with R1 and the R1 fragments -OH and -NH2
+const rgroupMolfile = `$MDL  REV  1
+$MOL
+$HDR
+
+  -INDIGO-
+
+$END HDR
+$CTAB
+  2  1  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    1.0000    0.0000    0.0000 R#  0  0  0  0  0  0  0  0  0  0  0  0
+  1  2  1  0  0  0  0
+M  RGP  1   2   1
+M  END
+$END CTAB
+$RGP
+  1
+$CTAB
+  1  0  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 O   0  0  0  0  0  0  0  0  0  0  0  0
+M  APO  1   1   1
+M  END
+$END CTAB
+$CTAB
+  1  0  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 N   0  0  0  0  0  0  0  0  0  0  0  0
+M  APO  1   1   1
+M  END
+$END CTAB
+$END RGP
+$END MOL
+`
+
+func TestRGroupComposition(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString(rgroupMolfile)
+	if err != nil {
+		t.Fatalf("Failed to load R-group file: %v", err)
+	}
+	defer mol.Close()
+
+	compositions, err := mol.RGroupComposition("", "")
+	if err != nil {
+		t.Fatalf("RGroupComposition failed: %v", err)
+	}
+	defer func() {
+		for i := range compositions {
+			compositions[i].Close()
+		}
+	}()
+
+	if len(compositions) != 2 {
+		t.Fatalf("Expected 2 compositions, got %d", len(compositions))
+	}
+	for i, c := range compositions {
+		if c.Molecule == nil || c.Fragmented == nil {
+			t.Fatalf("Composition %d: expected both molecules, got %+v", i, c)
+		}
+		count, err := c.Molecule.CountAtoms()
+		if err != nil || count != 2 {
+			t.Errorf("Composition %d: expected 2 atoms, got %d (%v)", i, count, err)
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO.[Na+].[Cl-]")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	components, err := mol.ComponentList()
+	if err != nil {
+		t.Fatalf("Failed to get components: %v", err)
+	}
+	if len(components) != 3 {
+		t.Fatalf("Expected 3 components, got %d", len(components))
+	}
+
+	expected := []string{"CCO", "[Na+]", "[Cl-]"}
+	for i, component := range components {
+		smiles, _ := component.ToSmiles()
+		if smiles != expected[i] {
+			t.Errorf("Component %d: expected %s, got %s", i, expected[i], smiles)
+		}
+		component.Close()
+	}
+}
+
+func TestLargestFragment(t *testing.T) {
+	// Iodide outweighs the two-carbon amine but has fewer heavy atoms
+	mol, err := indigoInit.LoadMoleculeFromString("[I-].CC[NH3+]")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	byAtoms, err := mol.LargestFragment(molecule.FRAGMENT_HEAVY_ATOMS)
+	if err != nil {
+		t.Fatalf("Failed to get largest fragment: %v", err)
+	}
+	defer byAtoms.Close()
+	if smiles, _ := byAtoms.ToSmiles(); smiles != "CC[NH3+]" {
+		t.Errorf("Expected CC[NH3+] by heavy atoms, got %s", smiles)
+	}
+
+	byWeight, err := mol.LargestFragment(molecule.FRAGMENT_MOLECULAR_WEIGHT)
+	if err != nil {
+		t.Fatalf("Failed to get largest fragment: %v", err)
+	}
+	defer byWeight.Close()
+	if smiles, _ := byWeight.ToSmiles(); smiles != "[I-]" {
+		t.Errorf("Expected [I-] by molecular weight, got %s", smiles)
+	}
+
+	if _, err := mol.LargestFragment(42); err == nil {
+		t.Error("Expected error for unknown criteria")
+	}
+}
+
+func TestSaltStripper(t *testing.T) {
+	stripper, err := molecule.DefaultSaltStripper()
+	if err != nil {
+		t.Fatalf("Failed to create salt stripper: %v", err)
+	}
+
+	// Nicotine dihydrochloride monohydrate
+	mol, err := indigoInit.LoadMoleculeFromString("CN1CCCC1c1cccnc1.Cl.Cl.O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	result, err := stripper.Strip(mol)
+	if err != nil {
+		t.Fatalf("Failed to strip salts: %v", err)
+	}
+	defer result.Parent.Close()
+
+	if len(result.Removed) != 3 {
+		t.Errorf("Expected 3 removed components, got %v", result.Removed)
+	}
+	count, _ := result.Parent.CountComponents()
+	if count != 1 {
+		t.Errorf("Expected a single parent component, got %d", count)
+	}
+
+	original, _ := mol.CountComponents()
+	if original != 4 {
+		t.Errorf("Expected input molecule to be unchanged, got %d components", original)
+	}
+}
+
+func TestSaltStripperCustomDictionary(t *testing.T) {
+	stripper, err := molecule.NewSaltStripper("[Na+].[Cl-]")
+	if err != nil {
+		t.Fatalf("Failed to create salt stripper: %v", err)
+	}
+	if stripper.Len() != 2 {
+		t.Errorf("Expected 2 dictionary components, got %d", stripper.Len())
+	}
+
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccccc1C[NH3+].[Cl-]")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	result, err := stripper.Strip(mol)
+	if err != nil {
+		t.Fatalf("Failed to strip salts: %v", err)
+	}
+	defer result.Parent.Close()
+
+	if len(result.Removed) != 1 || result.Removed[0] != "[Na+].[Cl-]" {
+		t.Errorf("Expected chloride from the [Na+].[Cl-] entry to be removed, got %v", result.Removed)
+	}
+	smiles, _ := result.Parent.ToCanonicalSmiles()
+	expected, _ := indigoInit.LoadMoleculeFromString("c1ccccc1C[NH3+]")
+	defer expected.Close()
+	want, _ := expected.ToCanonicalSmiles()
+	if smiles != want {
+		t.Errorf("Expected parent %s, got %s", want, smiles)
+	}
+
+	// Nothing is removed when every component is a salt
+	onlySalt, _ := indigoInit.LoadMoleculeFromString("[Na+].[Cl-]")
+	defer onlySalt.Close()
+	result, err = stripper.Strip(onlySalt)
+	if err != nil {
+		t.Fatalf("Failed to strip salts: %v", err)
+	}
+	defer result.Parent.Close()
+	if len(result.Removed) != 0 {
+		t.Errorf("Expected nothing removed from an all-salt molecule, got %v", result.Removed)
+	}
+
+	if _, err := molecule.NewSaltStripper("not a smiles"); err == nil {
+		t.Error("Expected error for invalid SMILES entry")
+	}
+}
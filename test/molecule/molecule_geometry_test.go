@@ -0,0 +1,225 @@
+// Package molecule_test provides tests for coordinates and geometry utilities
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_geometry_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+const geometryEpsilon = 1e-4
+
+func TestAtomXYZ(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	atom, err := mol.GetAtom(0)
+	if err != nil {
+		t.Fatalf("Failed to get atom: %v", err)
+	}
+	defer atom.Close()
+
+	if err := atom.SetXYZ(1.5, -2.0, 3.25); err != nil {
+		t.Fatalf("SetXYZ failed: %v", err)
+	}
+
+	p, err := atom.XYZ()
+	if err != nil {
+		t.Fatalf("XYZ failed: %v", err)
+	}
+	if p.X != 1.5 || p.Y != -2.0 || p.Z != 3.25 {
+		t.Errorf("Expected (1.5, -2, 3.25), got %+v", p)
+	}
+
+	is3D, err := mol.Is3D()
+	if err != nil {
+		t.Fatalf("Is3D failed: %v", err)
+	}
+	if !is3D {
+		t.Error("Expected molecule to be 3D after setting a Z coordinate")
+	}
+}
+
+func TestHasCoordinates(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccccc1O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	has, err := mol.HasCoordinates()
+	if err != nil {
+		t.Fatalf("HasCoordinates failed: %v", err)
+	}
+	if has {
+		t.Error("Expected SMILES molecule to have no coordinates")
+	}
+
+	if err := mol.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	has, err = mol.HasCoordinates()
+	if err != nil {
+		t.Fatalf("HasCoordinates failed: %v", err)
+	}
+	if !has {
+		t.Error("Expected coordinates after layout")
+	}
+
+	is3D, err := mol.Is3D()
+	if err != nil {
+		t.Fatalf("Is3D failed: %v", err)
+	}
+	if is3D {
+		t.Error("Expected layout coordinates to be 2D")
+	}
+
+	points, err := mol.Coordinates()
+	if err != nil {
+		t.Fatalf("Coordinates failed: %v", err)
+	}
+	if len(points) != 7 {
+		t.Errorf("Expected 7 points, got %d", len(points))
+	}
+
+	if err := mol.ClearCoordinates(); err != nil {
+		t.Fatalf("ClearCoordinates failed: %v", err)
+	}
+
+	has, err = mol.HasCoordinates()
+	if err != nil {
+		t.Fatalf("HasCoordinates failed: %v", err)
+	}
+	if has {
+		t.Error("Expected no coordinates after ClearCoordinates")
+	}
+}
+
+func TestCoordinatesAfterRemoval(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	atom, err := mol.GetAtom(2)
+	if err != nil {
+		t.Fatalf("Failed to get atom: %v", err)
+	}
+	err = atom.SetXYZ(4, 5, 6)
+	atom.Close()
+	if err != nil {
+		t.Fatalf("SetXYZ failed: %v", err)
+	}
+
+	if err := mol.RemoveAtoms([]int{1}); err != nil {
+		t.Fatalf("RemoveAtoms failed: %v", err)
+	}
+
+	// The oxygen keeps index 2, so its point stays at position 2
+	points, err := mol.Coordinates()
+	if err != nil {
+		t.Fatalf("Coordinates failed: %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("Expected 3 points, got %d", len(points))
+	}
+	if p := points[2]; p.X != 4 || p.Y != 5 || p.Z != 6 {
+		t.Errorf("Expected (4, 5, 6) at index 2, got %+v", p)
+	}
+}
+
+func TestAlignAtoms(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if err := mol.Layout(); err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+
+	reference, err := mol.Coordinates()
+	if err != nil {
+		t.Fatalf("Coordinates failed: %v", err)
+	}
+
+	// Shift the molecule and align it back onto its original position
+	for i, p := range reference {
+		atom, err := mol.GetAtom(i)
+		if err != nil {
+			t.Fatalf("Failed to get atom: %v", err)
+		}
+		if err := atom.SetXYZ(p.X+5, p.Y-3, p.Z); err != nil {
+			t.Fatalf("SetXYZ failed: %v", err)
+		}
+		atom.Close()
+	}
+
+	rmsd, err := mol.AlignAtoms([]int{0, 1, 2}, reference)
+	if err != nil {
+		t.Fatalf("AlignAtoms failed: %v", err)
+	}
+	if rmsd > 0.01 {
+		t.Errorf("Expected RMSD close to 0, got %f", rmsd)
+	}
+
+	if _, err := mol.AlignAtoms([]int{0, 1}, reference); err == nil {
+		t.Error("Expected error for mismatched atom and position counts")
+	}
+}
+
+func TestGeometryHelpers(t *testing.T) {
+	origin := molecule.Point{}
+	x := molecule.Point{X: 1}
+	y := molecule.Point{Y: 1}
+
+	if d := molecule.Distance(origin, molecule.Point{X: 3, Y: 4}); math.Abs(d-5) > geometryEpsilon {
+		t.Errorf("Expected distance 5, got %f", d)
+	}
+
+	if a := molecule.Angle(x, origin, y); math.Abs(a-90) > geometryEpsilon {
+		t.Errorf("Expected angle 90, got %f", a)
+	}
+
+	// Trans and cis arrangements around the Y axis
+	a := molecule.Point{X: 1}
+	b := molecule.Point{}
+	c := molecule.Point{Y: 1}
+	trans := molecule.Point{X: -1, Y: 1}
+	cis := molecule.Point{X: 1, Y: 1}
+	gauche := molecule.Point{Y: 1, Z: 1}
+
+	if d := molecule.Dihedral(a, b, c, trans); math.Abs(math.Abs(d)-180) > geometryEpsilon {
+		t.Errorf("Expected dihedral 180, got %f", d)
+	}
+	if d := molecule.Dihedral(a, b, c, cis); math.Abs(d) > geometryEpsilon {
+		t.Errorf("Expected dihedral 0, got %f", d)
+	}
+	if d := molecule.Dihedral(a, b, c, gauche); math.Abs(math.Abs(d)-90) > geometryEpsilon {
+		t.Errorf("Expected dihedral +/-90, got %f", d)
+	}
+
+	points := []molecule.Point{{X: 0, Y: 0, Z: 0}, {X: 2, Y: 4, Z: -2}}
+	if c := molecule.Centroid(points); c != (molecule.Point{X: 1, Y: 2, Z: -1}) {
+		t.Errorf("Expected centroid (1, 2, -1), got %+v", c)
+	}
+
+	min, max := molecule.BoundingBox(points)
+	if min != (molecule.Point{X: 0, Y: 0, Z: -2}) || max != (molecule.Point{X: 2, Y: 4, Z: 0}) {
+		t.Errorf("Unexpected bounding box %+v - %+v", min, max)
+	}
+}
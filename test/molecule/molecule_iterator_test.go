@@ -0,0 +1,245 @@
+// Package molecule_test provides tests for atom, bond and component iterators
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_iterator_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestAtomsIterator(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	it, err := mol.Atoms()
+	if err != nil {
+		t.Fatalf("Atoms failed: %v", err)
+	}
+	defer it.Close()
+
+	var symbols []string
+	for it.Next() {
+		symbol, err := it.Atom().Symbol()
+		if err != nil {
+			t.Fatalf("Symbol failed: %v", err)
+		}
+		symbols = append(symbols, symbol)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iteration failed: %v", err)
+	}
+
+	if len(symbols) != 3 || symbols[2] != "O" {
+		t.Errorf("Expected [C C O], got %v", symbols)
+	}
+}
+
+func TestBondsForEach(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("C=CC#N")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	it, err := mol.Bonds()
+	if err != nil {
+		t.Fatalf("Bonds failed: %v", err)
+	}
+
+	var orders []int
+	err = it.ForEach(func(b *molecule.Bond) error {
+		order, err := b.Order()
+		if err != nil {
+			return err
+		}
+		orders = append(orders, order)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+
+	expected := []int{molecule.BOND_DOUBLE, molecule.BOND_SINGLE, molecule.BOND_TRIPLE}
+	if len(orders) != len(expected) {
+		t.Fatalf("Expected %d bonds, got %d", len(expected), len(orders))
+	}
+	for i := range expected {
+		if orders[i] != expected[i] {
+			t.Errorf("Bond %d: expected order %d, got %d", i, expected[i], orders[i])
+		}
+	}
+}
+
+func TestIteratorElementsClose(t *testing.T) {
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	var mu sync.Mutex
+	var errs []string
+	in.SetErrorHandler(func(msg string) {
+		mu.Lock()
+		errs = append(errs, msg)
+		mu.Unlock()
+	})
+
+	mol, err := in.LoadMoleculeFromString("C=CC#N")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	atoms, err := mol.Atoms()
+	if err != nil {
+		t.Fatalf("Atoms failed: %v", err)
+	}
+	// Closing an element owned by the iterator must not free it a second time
+	count := 0
+	err = atoms.ForEach(func(a *molecule.Atom) error {
+		count++
+		return a.Close()
+	})
+	if err != nil || count != 4 {
+		t.Errorf("Expected 4 atoms, got %d (%v)", count, err)
+	}
+
+	bonds, err := mol.Bonds()
+	if err != nil {
+		t.Fatalf("Bonds failed: %v", err)
+	}
+	count = 0
+	for bonds.Next() {
+		if err := bonds.Bond().Close(); err != nil {
+			t.Errorf("Failed to close bond: %v", err)
+		}
+		count++
+	}
+	if err := bonds.Close(); err != nil || count != 3 {
+		t.Errorf("Expected 3 bonds, got %d (%v)", count, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 0 {
+		t.Errorf("Expected no native errors, got %v", errs)
+	}
+}
+
+func TestForEachStopsOnError(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCCCCC")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	it, err := mol.Atoms()
+	if err != nil {
+		t.Fatalf("Atoms failed: %v", err)
+	}
+
+	stop := errors.New("stop")
+	visited := 0
+	err = it.ForEach(func(a *molecule.Atom) error {
+		visited++
+		if visited == 2 {
+			return stop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected stop error, got %v", err)
+	}
+	if visited != 2 {
+		t.Errorf("Expected iteration to stop after 2 atoms, visited %d", visited)
+	}
+}
+
+func TestComponentsIterator(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO.[Na+].[Cl-]")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	it, err := mol.Components()
+	if err != nil {
+		t.Fatalf("Components failed: %v", err)
+	}
+
+	var sizes []int
+	err = it.ForEach(func(component *molecule.Molecule) error {
+		count, err := component.CountAtoms()
+		if err != nil {
+			return err
+		}
+		sizes = append(sizes, count)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+
+	if len(sizes) != 3 || sizes[0] != 3 {
+		t.Errorf("Expected components of sizes [3 1 1], got %v", sizes)
+	}
+}
+
+func TestPseudoatomsAndRSites(t *testing.T) {
+	mol, err := indigoInit.CreateQueryMolecule()
+	if err != nil {
+		t.Fatalf("Failed to create molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if _, err := mol.AddAtom("C"); err != nil {
+		t.Fatalf("Failed to add atom: %v", err)
+	}
+	if _, err := mol.AddRSite("R1"); err != nil {
+		t.Fatalf("Failed to add R-site: %v", err)
+	}
+
+	it, err := mol.RSites()
+	if err != nil {
+		t.Fatalf("RSites failed: %v", err)
+	}
+
+	count := 0
+	err = it.ForEach(func(a *molecule.Atom) error {
+		if !a.IsRSite() {
+			t.Error("Expected R-site atom")
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 R-site, got %d", count)
+	}
+
+	pseudo, err := mol.Pseudoatoms()
+	if err != nil {
+		t.Fatalf("Pseudoatoms failed: %v", err)
+	}
+	defer pseudo.Close()
+	if pseudo.Next() {
+		t.Error("Expected no pseudoatoms")
+	}
+}
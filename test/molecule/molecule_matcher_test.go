@@ -0,0 +1,244 @@
+// Package molecule_test provides tests for substructure matchers and match mappings
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_matcher_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestMatchesMappings(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("OCCC(=O)O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	// Carbonyl: C=O
+	query, err := indigoInit.LoadQueryMoleculeFromString("C=O")
+	if err != nil {
+		t.Fatalf("Failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	it, err := mol.Matches(query, "", 0)
+	if err != nil {
+		t.Fatalf("Matches failed: %v", err)
+	}
+
+	var matches []*molecule.Match
+	err = it.ForEach(func(m *molecule.Match) error {
+		matches = append(matches, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach failed: %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+
+	m := matches[0]
+	if len(m.Atoms) != 2 || m.Atoms[0] != 3 || m.Atoms[1] != 4 {
+		t.Errorf("Expected atom mapping [3 4], got %v", m.Atoms)
+	}
+	if len(m.Bonds) != 1 || m.Bonds[0] != 3 {
+		t.Errorf("Expected bond mapping [3], got %v", m.Bonds)
+	}
+
+	// The iterator has moved on, so the match can no longer be highlighted
+	if _, err := m.HighlightedTarget(); err == nil {
+		t.Error("Expected error for highlighting a stale match")
+	}
+}
+
+func TestMatchesLimitAndHighlight(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	query, err := indigoInit.LoadQueryMoleculeFromString("cc")
+	if err != nil {
+		t.Fatalf("Failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	it, err := mol.Matches(query, "", 3)
+	if err != nil {
+		t.Fatalf("Matches failed: %v", err)
+	}
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+
+		highlighted, err := it.Match().HighlightedTarget()
+		if err != nil {
+			t.Fatalf("HighlightedTarget failed: %v", err)
+		}
+		atoms, err := highlighted.CountAtoms()
+		highlighted.Close()
+		if err != nil {
+			t.Fatalf("CountAtoms failed: %v", err)
+		}
+		if atoms != 6 {
+			t.Errorf("Expected highlighted target with 6 atoms, got %d", atoms)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iteration failed: %v", err)
+	}
+
+	if count != 3 {
+		t.Errorf("Expected 3 matches with limit, got %d", count)
+	}
+}
+
+func TestMatcherIgnoreAtom(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("OCCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	query, err := indigoInit.LoadQueryMoleculeFromString("CO")
+	if err != nil {
+		t.Fatalf("Failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	matcher, err := mol.NewMatcher("")
+	if err != nil {
+		t.Fatalf("NewMatcher failed: %v", err)
+	}
+	defer matcher.Close()
+
+	count, err := matcher.Count(query, 0)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 matches, got %d", count)
+	}
+
+	if err := matcher.IgnoreAtom(0); err != nil {
+		t.Fatalf("IgnoreAtom failed: %v", err)
+	}
+
+	it, err := matcher.Matches(query, 0)
+	if err != nil {
+		t.Fatalf("Matches failed: %v", err)
+	}
+	defer it.Close()
+
+	matched := 0
+	for it.Next() {
+		matched++
+		for _, idx := range it.Match().Atoms {
+			if idx == 0 {
+				t.Error("Expected ignored atom 0 not to be matched")
+			}
+		}
+	}
+	if matched != 1 {
+		t.Errorf("Expected 1 match with ignored atom, got %d", matched)
+	}
+
+	if err := matcher.UnignoreAtom(0); err != nil {
+		t.Fatalf("UnignoreAtom failed: %v", err)
+	}
+	if err := matcher.UnignoreAllAtoms(); err != nil {
+		t.Fatalf("UnignoreAllAtoms failed: %v", err)
+	}
+
+	count, err = matcher.Count(query, 0)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 matches after unignoring, got %d", count)
+	}
+}
+
+func TestStaleMatchAfterNext(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	query, err := indigoInit.LoadQueryMoleculeFromString("cc")
+	if err != nil {
+		t.Fatalf("Failed to load query: %v", err)
+	}
+	defer query.Close()
+
+	it, err := mol.Matches(query, "", 0)
+	if err != nil {
+		t.Fatalf("Matches failed: %v", err)
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		t.Fatalf("Expected a match: %v", it.Err())
+	}
+	first := it.Match()
+	if !it.Next() {
+		t.Fatalf("Expected a second match: %v", it.Err())
+	}
+
+	// The freed handle of the first match may be reused by the second one
+	if _, err := first.HighlightedTarget(); err == nil {
+		t.Error("Expected error for highlighting a match the iterator moved past")
+	}
+	highlighted, err := it.Match().HighlightedTarget()
+	if err != nil {
+		t.Fatalf("HighlightedTarget failed: %v", err)
+	}
+	highlighted.Close()
+}
+
+func TestMatchesQueryClosed(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("OCC(O)CO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	query, err := indigoInit.LoadQueryMoleculeFromString("CO")
+	if err != nil {
+		t.Fatalf("Failed to load query: %v", err)
+	}
+
+	it, err := mol.Matches(query, "", 0)
+	if err != nil {
+		t.Fatalf("Matches failed: %v", err)
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		t.Fatalf("Expected a first match: %v", it.Err())
+	}
+
+	// The mapping needs the query, so a closed query stops the iteration
+	query.Close()
+	if it.Next() {
+		t.Error("Expected no match after closing the query")
+	}
+	if !errors.Is(it.Err(), indigoerr.ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", it.Err())
+	}
+}
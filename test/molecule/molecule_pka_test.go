@@ -0,0 +1,73 @@
+// Package molecule_test provides tests for pKa prediction
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_pka_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+)
+
+func TestPkaSitesAcid(t *testing.T) {
+	// Acetic acid: the hydroxyl oxygen (index 3) is the acidic site
+	mol, err := indigoInit.LoadMoleculeFromString("CC(=O)O")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	sites, err := mol.PkaSites()
+	if err != nil {
+		t.Fatalf("PkaSites failed: %v", err)
+	}
+
+	found := false
+	for _, site := range sites {
+		if site.Acidic && site.AtomIndex == 3 {
+			found = true
+			if site.Pka < 2 || site.Pka > 7 {
+				t.Errorf("Expected carboxylic acid pKa around 4.8, got %f", site.Pka)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected acidic site on atom 3, got %+v", sites)
+	}
+}
+
+func TestPkaSitesBase(t *testing.T) {
+	// Ethylamine: the nitrogen (index 2) is the basic site
+	mol, err := indigoInit.LoadMoleculeFromString("CCN")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	sites, err := mol.PkaSitesWithLevel(5, 0)
+	if err != nil {
+		t.Fatalf("PkaSitesWithLevel failed: %v", err)
+	}
+
+	found := false
+	for _, site := range sites {
+		if !site.Acidic && site.AtomIndex == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected basic site on atom 2, got %+v", sites)
+	}
+}
+
+func TestPkaSitesClosedMolecule(t *testing.T) {
+	mol, _ := indigoInit.LoadMoleculeFromString("CCO")
+	mol.Close()
+
+	if _, err := mol.PkaSites(); err == nil {
+		t.Error("Expected error on closed molecule")
+	}
+}
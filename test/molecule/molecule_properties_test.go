@@ -1,8 +1,12 @@
 package molecule_test
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
 )
 
 // TestGrossFormula tests getting gross formula
@@ -299,6 +303,84 @@ func TestMassComposition(t *testing.T) {
 	}
 }
 
+// TestHydrogenBondCounts tests counting hydrogen bond donors and acceptors
+func TestHydrogenBondCounts(t *testing.T) {
+	// Acetic acid: two oxygen acceptors, one OH donor
+	m, err := indigoInit.LoadMoleculeFromString("CC(=O)O")
+	if err != nil {
+		t.Fatalf("failed to load molecule: %v", err)
+	}
+	defer m.Close()
+
+	acceptors, err := m.NumHydrogenBondAcceptors()
+	if err != nil {
+		t.Fatalf("failed to get H-bond acceptors: %v", err)
+	}
+	if acceptors != 2 {
+		t.Errorf("expected 2 H-bond acceptors, got %d", acceptors)
+	}
+
+	donors, err := m.NumHydrogenBondDonors()
+	if err != nil {
+		t.Fatalf("failed to get H-bond donors: %v", err)
+	}
+	if donors != 1 {
+		t.Errorf("expected 1 H-bond donor, got %d", donors)
+	}
+}
+
+// TestLogPAndMolarRefractivity tests Crippen logP and molar refractivity
+func TestLogPAndMolarRefractivity(t *testing.T) {
+	benzene, err := indigoInit.LoadMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("failed to load molecule: %v", err)
+	}
+	defer benzene.Close()
+
+	ethanol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("failed to load molecule: %v", err)
+	}
+	defer ethanol.Close()
+
+	benzeneLogP, err := benzene.LogP()
+	if err != nil {
+		t.Fatalf("failed to get logP: %v", err)
+	}
+	ethanolLogP, err := ethanol.LogP()
+	if err != nil {
+		t.Fatalf("failed to get logP: %v", err)
+	}
+	if benzeneLogP <= ethanolLogP {
+		t.Errorf("expected benzene logP (%f) above ethanol logP (%f)", benzeneLogP, ethanolLogP)
+	}
+
+	mr, err := benzene.MolarRefractivity()
+	if err != nil {
+		t.Fatalf("failed to get molar refractivity: %v", err)
+	}
+	if mr <= 0 {
+		t.Errorf("expected positive molar refractivity, got %f", mr)
+	}
+}
+
+// TestCountHydrogens tests counting all hydrogens of a molecule
+func TestCountHydrogens(t *testing.T) {
+	m, err := indigoInit.LoadMoleculeFromString("[H]OCC")
+	if err != nil {
+		t.Fatalf("failed to load molecule: %v", err)
+	}
+	defer m.Close()
+
+	count, err := m.CountHydrogens()
+	if err != nil {
+		t.Fatalf("failed to count hydrogens: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("expected 6 hydrogens, got %d", count)
+	}
+}
+
 // TestPropertiesOnClosedMolecule tests that properties fail on closed molecule
 func TestPropertiesOnClosedMolecule(t *testing.T) {
 	m, _ := indigoInit.LoadMoleculeFromString("CCO")
@@ -320,3 +402,54 @@ func TestPropertiesOnClosedMolecule(t *testing.T) {
 		t.Error("expected error on closed molecule")
 	}
 }
+
+// TestPropertyMap tests listing, setting and clearing properties
+func TestPropertyMap(t *testing.T) {
+	m, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("failed to load molecule: %v", err)
+	}
+	defer m.Close()
+
+	err = m.SetProperties(map[string]string{"ID": "MOL-1", "IC50": " 12.5 ", "COUNT": "3", "ACTIVE": "Yes"})
+	if err != nil {
+		t.Fatalf("failed to set properties: %v", err)
+	}
+
+	props, err := m.Properties()
+	if err != nil {
+		t.Fatalf("failed to list properties: %v", err)
+	}
+	if len(props) != 4 || props["ID"] != "MOL-1" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+
+	ic50, err := m.PropertyFloat("IC50")
+	if err != nil || ic50 != 12.5 {
+		t.Errorf("expected IC50 12.5, got %v (%v)", ic50, err)
+	}
+	count, err := m.PropertyInt("COUNT")
+	if err != nil || count != 3 {
+		t.Errorf("expected COUNT 3, got %v (%v)", count, err)
+	}
+	active, err := m.PropertyBool("ACTIVE")
+	if err != nil || !active {
+		t.Errorf("expected ACTIVE true, got %v (%v)", active, err)
+	}
+
+	_, err = m.PropertyInt("ID")
+	if err == nil || !strings.Contains(err.Error(), "ID") {
+		t.Errorf("expected parse error naming the property, got %v", err)
+	}
+	if !errors.Is(err, indigoerr.ErrParse) || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected ErrParse wrapping strconv.ErrSyntax, got %v", err)
+	}
+
+	if err := m.ClearProperties(); err != nil {
+		t.Fatalf("failed to clear properties: %v", err)
+	}
+	props, _ = m.Properties()
+	if len(props) != 0 {
+		t.Errorf("expected no properties after clear, got %v", props)
+	}
+}
@@ -0,0 +1,160 @@
+// Package molecule_test provides tests for query constraints
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_query_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+// buildRingHeteroQuery builds a query for an aromatic ring C or N bonded to a ring atom
+func buildRingHeteroQuery(t *testing.T) *molecule.Molecule {
+	t.Helper()
+
+	query, err := indigoInit.CreateQueryMolecule()
+	if err != nil {
+		t.Fatalf("Failed to create query molecule: %v", err)
+	}
+
+	a1, err := query.AddAtom("C")
+	if err != nil {
+		t.Fatalf("Failed to add atom: %v", err)
+	}
+	a2, err := query.AddAtom("C")
+	if err != nil {
+		t.Fatalf("Failed to add atom: %v", err)
+	}
+	if _, err := query.AddBond(a1, a2, molecule.BOND_AROMATIC); err != nil {
+		t.Fatalf("Failed to add bond: %v", err)
+	}
+
+	q, err := query.QueryAtom(0)
+	if err != nil {
+		t.Fatalf("QueryAtom failed: %v", err)
+	}
+	defer q.Close()
+	q.AtomicNumberIn(6, 7).Charge(0).InRing(true).RingBonds(2).Aromatic(true)
+	if err := q.Err(); err != nil {
+		t.Fatalf("Failed to build atom query: %v", err)
+	}
+
+	b, err := query.QueryBond(0)
+	if err != nil {
+		t.Fatalf("QueryBond failed: %v", err)
+	}
+	defer b.Close()
+	b.Topology(molecule.TOPOLOGY_RING)
+	if err := b.Err(); err != nil {
+		t.Fatalf("Failed to build bond query: %v", err)
+	}
+
+	return query
+}
+
+func TestQueryConstraints(t *testing.T) {
+	query := buildRingHeteroQuery(t)
+	defer query.Close()
+
+	tests := []struct {
+		smiles string
+		match  bool
+	}{
+		{"c1ccncc1", true},
+		{"c1ccccc1", true},
+		{"CCCC", false},
+		{"C1CCCCC1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.smiles, func(t *testing.T) {
+			mol, err := indigoInit.LoadMoleculeFromString(tt.smiles)
+			if err != nil {
+				t.Fatalf("Failed to load molecule: %v", err)
+			}
+			defer mol.Close()
+
+			found, err := mol.HasSubstructure(query, nil)
+			if err != nil {
+				t.Fatalf("HasSubstructure failed: %v", err)
+			}
+			if found != tt.match {
+				t.Errorf("Expected match %v, got %v", tt.match, found)
+			}
+		})
+	}
+}
+
+func TestQueryConstraintsRoundTrip(t *testing.T) {
+	query := buildRingHeteroQuery(t)
+	defer query.Close()
+
+	smarts, err := query.ToSmarts()
+	if err != nil {
+		t.Fatalf("ToSmarts failed: %v", err)
+	}
+	if smarts == "" {
+		t.Fatal("Expected non-empty SMARTS")
+	}
+
+	reloaded, err := indigoInit.LoadSmartsFromString(smarts)
+	if err != nil {
+		t.Fatalf("Failed to reload SMARTS %s: %v", smarts, err)
+	}
+	defer reloaded.Close()
+
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccncc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	found, err := mol.HasSubstructure(reloaded, nil)
+	if err != nil {
+		t.Fatalf("HasSubstructure failed: %v", err)
+	}
+	if !found {
+		t.Errorf("Expected reloaded SMARTS %s to match pyridine", smarts)
+	}
+
+	molfile, err := query.ToMolfile()
+	if err != nil {
+		t.Fatalf("ToMolfile failed: %v", err)
+	}
+
+	fromMolfile, err := indigoInit.LoadQueryMoleculeFromString(molfile)
+	if err != nil {
+		t.Fatalf("Failed to reload query molfile: %v", err)
+	}
+	defer fromMolfile.Close()
+}
+
+func TestQueryBuilderError(t *testing.T) {
+	query, err := indigoInit.CreateQueryMolecule()
+	if err != nil {
+		t.Fatalf("Failed to create query molecule: %v", err)
+	}
+	defer query.Close()
+
+	a1, _ := query.AddAtom("C")
+	a2, _ := query.AddAtom("C")
+	if _, err := query.AddBond(a1, a2, molecule.BOND_SINGLE); err != nil {
+		t.Fatalf("Failed to add bond: %v", err)
+	}
+
+	b, err := query.QueryBond(0)
+	if err != nil {
+		t.Fatalf("QueryBond failed: %v", err)
+	}
+	defer b.Close()
+
+	if err := b.Topology(42).Err(); err == nil {
+		t.Error("Expected error for unknown topology")
+	}
+}
@@ -0,0 +1,238 @@
+// Package molecule_test provides tests for multi-record file readers
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_reader_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+const readerSDF = `ethanol
+  test
+
+  3  2  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    1.2990    0.7500    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+    2.5981    0.0000    0.0000 O   0  0  0  0  0  0  0  0  0  0  0  0
+  1  2  1  0  0  0  0
+  2  3  1  0  0  0  0
+M  END
+> <ID>
+MOL-1
+
+> <ACTIVITY>
+7.5
+
+$$$$
+broken
+  test
+
+  2  1  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 Xx  0  0  0  0  0  0  0  0  0  0  0  0
+M  END
+> <ID>
+MOL-2
+
+$$$$
+methane
+  test
+
+  1  0  0  0  0  0  0  0  0  0999 V2000
+    0.0000    0.0000    0.0000 C   0  0  0  0  0  0  0  0  0  0  0  0
+M  END
+> <ID>
+MOL-3
+
+$$$$
+`
+
+func TestIterateSDF(t *testing.T) {
+	rd, err := indigoInit.IterateSDF(strings.NewReader(readerSDF))
+	if err != nil {
+		t.Fatalf("Failed to create SDF reader: %v", err)
+	}
+	defer rd.Close()
+
+	var ids []string
+	var failed []int
+	for rd.Next() {
+		rec := rd.Record()
+		ids = append(ids, rec.Properties["ID"])
+		if rec.Err != nil {
+			failed = append(failed, rec.Index)
+			continue
+		}
+		if rec.Molecule == nil {
+			t.Fatalf("Record %d has no molecule", rec.Index)
+		}
+		if rec.Raw == "" {
+			t.Errorf("Record %d has no raw data", rec.Index)
+		}
+		rec.Molecule.Close()
+	}
+	if err := rd.Err(); err != nil {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+
+	if len(ids) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(ids))
+	}
+	if ids[0] != "MOL-1" || ids[2] != "MOL-3" {
+		t.Errorf("Unexpected record IDs: %v", ids)
+	}
+	if len(failed) != 1 || failed[0] != 1 {
+		t.Errorf("Expected only record 1 to fail, got %v", failed)
+	}
+}
+
+func TestIterateSDFProperties(t *testing.T) {
+	rd, err := indigoInit.IterateSDF(strings.NewReader(readerSDF))
+	if err != nil {
+		t.Fatalf("Failed to create SDF reader: %v", err)
+	}
+	defer rd.Close()
+
+	if !rd.Next() {
+		t.Fatalf("Expected a record: %v", rd.Err())
+	}
+	rec := rd.Record()
+	if rec.Err != nil {
+		t.Fatalf("Failed to read record: %v", rec.Err)
+	}
+	defer rec.Molecule.Close()
+
+	if rec.Properties["ACTIVITY"] != "7.5" {
+		t.Errorf("Expected ACTIVITY 7.5, got %q", rec.Properties["ACTIVITY"])
+	}
+
+	smiles, _ := rec.Molecule.ToSmiles()
+	if smiles != "CCO" {
+		t.Errorf("Expected CCO, got %s", smiles)
+	}
+}
+
+func TestIterateSmilesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.smi")
+	if err := os.WriteFile(path, []byte("CCO\nc1ccccc1\nCC(=O)O\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write SMILES file: %v", err)
+	}
+
+	rd, err := indigoInit.IterateSmilesFile(path)
+	if err != nil {
+		t.Fatalf("Failed to create SMILES reader: %v", err)
+	}
+	defer rd.Close()
+
+	count := 0
+	for rd.Next() {
+		rec := rd.Record()
+		if rec.Err != nil {
+			t.Errorf("Failed to read record %d: %v", rec.Index, rec.Err)
+			continue
+		}
+		if rec.Index != count {
+			t.Errorf("Expected index %d, got %d", count, rec.Index)
+		}
+		rec.Molecule.Close()
+		count++
+	}
+	if err := rd.Err(); err != nil {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 molecules, got %d", count)
+	}
+}
+
+func TestIterateSmiles(t *testing.T) {
+	rd, err := indigoInit.IterateSmiles(strings.NewReader("CCO\nc1ccccc1\nCC(=O)O\n"))
+	if err != nil {
+		t.Fatalf("Failed to create SMILES reader: %v", err)
+	}
+	defer rd.Close()
+
+	count := 0
+	for rd.Next() {
+		rec := rd.Record()
+		// SMILES records have no data fields, which must not keep them from being parsed
+		if rec.Err != nil || rec.Molecule == nil {
+			t.Errorf("Failed to read record %d: %v", rec.Index, rec.Err)
+			continue
+		}
+		rec.Molecule.Close()
+		count++
+	}
+	if err := rd.Err(); err != nil {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 molecules, got %d", count)
+	}
+}
+
+func TestIterateMissingFile(t *testing.T) {
+	if _, err := indigoInit.IterateSDFile(filepath.Join(t.TempDir(), "missing.sdf")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestIterateSDFPropertyNames(t *testing.T) {
+	rd, err := indigoInit.IterateSDF(strings.NewReader(readerSDF))
+	if err != nil {
+		t.Fatalf("Failed to create SDF reader: %v", err)
+	}
+	defer rd.Close()
+
+	if !rd.Next() {
+		t.Fatalf("Expected a record: %v", rd.Err())
+	}
+	rec := rd.Record()
+	if rec.Molecule != nil {
+		defer rec.Molecule.Close()
+	}
+
+	// Each data field must be keyed by its name, not by its value
+	expected := map[string]string{"ID": "MOL-1", "ACTIVITY": "7.5"}
+	if len(rec.Properties) != len(expected) {
+		t.Fatalf("Expected properties %v, got %v", expected, rec.Properties)
+	}
+	for name, value := range expected {
+		if got, ok := rec.Properties[name]; !ok || got != value {
+			t.Errorf("Expected %s=%q, got %v", name, value, rec.Properties)
+		}
+	}
+}
+
+// failingReader returns an error after the first read
+type failingReader struct {
+	done bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, errors.New("connection reset")
+	}
+	r.done = true
+	return copy(p, readerSDF[:20]), nil
+}
+
+func TestIterateSDFReadError(t *testing.T) {
+	_, err := indigoInit.IterateSDF(&failingReader{})
+	if !errors.Is(err, indigoerr.ErrIO) {
+		t.Errorf("Expected ErrIO, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("Expected the cause in the error, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package molecule_test provides tests for R-group decomposition and scaffold detection
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_rgroup_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/indigoerr"
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func loadMolecules(t *testing.T, smiles ...string) []*molecule.Molecule {
+	t.Helper()
+
+	mols := make([]*molecule.Molecule, 0, len(smiles))
+	for _, s := range smiles {
+		mol, err := indigoInit.LoadMoleculeFromString(s)
+		if err != nil {
+			t.Fatalf("Failed to load molecule %s: %v", s, err)
+		}
+		mols = append(mols, mol)
+	}
+
+	t.Cleanup(func() {
+		for _, mol := range mols {
+			mol.Close()
+		}
+	})
+	return mols
+}
+
+func TestRGroupDecomposer(t *testing.T) {
+	scaffold, err := indigoInit.LoadQueryMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load scaffold: %v", err)
+	}
+	defer scaffold.Close()
+
+	mols := loadMolecules(t, "Cc1ccccc1", "Oc1ccccc1", "CCCC")
+
+	dec, err := molecule.NewRGroupDecomposer(scaffold)
+	if err != nil {
+		t.Fatalf("Failed to create decomposer: %v", err)
+	}
+	defer dec.Close()
+
+	results, err := dec.Decompose(mols)
+	if err != nil {
+		t.Fatalf("Decompose failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	defer func() {
+		for i := range results {
+			results[i].Close()
+		}
+	}()
+
+	for _, res := range results[:2] {
+		if res.Err != nil {
+			t.Errorf("Molecule %d: unexpected error %v", res.Index, res.Err)
+			continue
+		}
+		if len(res.RGroups) != 1 {
+			t.Errorf("Molecule %d: expected 1 R-group, got %v", res.Index, res.RGroups)
+		}
+		if res.Scaffold == nil || res.Highlighted == nil {
+			t.Errorf("Molecule %d: expected scaffold and highlighted molecule", res.Index)
+		}
+	}
+
+	if results[2].Err == nil {
+		t.Error("Expected butane not to match the benzene scaffold")
+	}
+
+	full, err := dec.FullScaffold()
+	if err != nil {
+		t.Fatalf("FullScaffold failed: %v", err)
+	}
+	defer full.Close()
+
+	count, err := full.CountAtoms()
+	if err != nil {
+		t.Fatalf("CountAtoms failed: %v", err)
+	}
+	if count < 7 {
+		t.Errorf("Expected scaffold with at least one R-site, got %d atoms", count)
+	}
+}
+
+func TestDecomposeMolecules(t *testing.T) {
+	scaffold, err := indigoInit.LoadQueryMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load scaffold: %v", err)
+	}
+	defer scaffold.Close()
+
+	mols := loadMolecules(t, "Cc1ccccc1", "Cc1ccc(O)cc1")
+
+	full, results, err := molecule.DecomposeMolecules(scaffold, mols)
+	if err != nil {
+		t.Fatalf("DecomposeMolecules failed: %v", err)
+	}
+	defer full.Close()
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for i := range results {
+		defer results[i].Close()
+		if results[i].Err != nil {
+			t.Errorf("Molecule %d: unexpected error %v", i, results[i].Err)
+		}
+	}
+}
+
+func TestNilScaffold(t *testing.T) {
+	if _, err := molecule.NewRGroupDecomposer(nil); !errors.Is(err, indigoerr.ErrClosed) {
+		t.Errorf("Expected ErrClosed for a nil scaffold, got %v", err)
+	}
+	if _, _, err := molecule.DecomposeMolecules(nil, nil); !errors.Is(err, indigoerr.ErrClosed) {
+		t.Errorf("Expected ErrClosed for a nil scaffold, got %v", err)
+	}
+}
+
+func TestExtractCommonScaffold(t *testing.T) {
+	mols := loadMolecules(t, "Cc1ccc2ccccc2c1", "Oc1ccc2ccccc2c1", "NCc1ccc2ccccc2c1")
+
+	extracted, err := molecule.ExtractCommonScaffold(mols, "exact")
+	if err != nil {
+		t.Fatalf("ExtractCommonScaffold failed: %v", err)
+	}
+	defer extracted.Close()
+
+	scaffolds, err := molecule.AllScaffolds(extracted)
+	if err != nil {
+		t.Fatalf("AllScaffolds failed: %v", err)
+	}
+	if len(scaffolds) == 0 {
+		t.Fatal("Expected at least one scaffold")
+	}
+
+	for _, s := range scaffolds {
+		defer s.Close()
+	}
+
+	rings, err := scaffolds[0].CountSSSR()
+	if err != nil {
+		t.Fatalf("CountSSSR failed: %v", err)
+	}
+	if rings != 2 {
+		t.Errorf("Expected naphthalene scaffold with 2 rings, got %d", rings)
+	}
+}
+
+func TestAllScaffoldsNoNativeErrors(t *testing.T) {
+	in, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer in.Close()
+
+	var mu sync.Mutex
+	var errs []string
+	in.SetErrorHandler(func(msg string) {
+		mu.Lock()
+		errs = append(errs, msg)
+		mu.Unlock()
+	})
+
+	var mols []*molecule.Molecule
+	for _, smiles := range []string{"Cc1ccc2ccccc2c1", "Oc1ccc2ccccc2c1"} {
+		mol, err := in.LoadMoleculeFromString(smiles)
+		if err != nil {
+			t.Fatalf("Failed to load molecule %s: %v", smiles, err)
+		}
+		defer mol.Close()
+		mols = append(mols, mol)
+	}
+
+	extracted, err := molecule.ExtractCommonScaffold(mols, "exact")
+	if err != nil {
+		t.Fatalf("ExtractCommonScaffold failed: %v", err)
+	}
+	defer extracted.Close()
+
+	scaffolds, err := molecule.AllScaffolds(extracted)
+	if err != nil {
+		t.Fatalf("AllScaffolds failed: %v", err)
+	}
+	for _, s := range scaffolds {
+		s.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 0 {
+		t.Errorf("Expected no native errors, got %v", errs)
+	}
+}
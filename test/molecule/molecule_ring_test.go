@@ -0,0 +1,160 @@
+// Package molecule_test provides tests for ring perception
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_ring_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestSSSR(t *testing.T) {
+	// Naphthalene has two fused aromatic rings
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccc2ccccc2c1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	rings, err := mol.SSSR()
+	if err != nil {
+		t.Fatalf("SSSR failed: %v", err)
+	}
+
+	if len(rings) != 2 {
+		t.Fatalf("Expected 2 SSSR rings, got %d", len(rings))
+	}
+
+	for i, ring := range rings {
+		if len(ring.Atoms) != 6 || len(ring.Bonds) != 6 {
+			t.Errorf("Ring %d: expected 6 atoms and 6 bonds, got %d and %d", i, len(ring.Atoms), len(ring.Bonds))
+		}
+		if !ring.Aromatic {
+			t.Errorf("Ring %d: expected aromatic ring", i)
+		}
+	}
+}
+
+func TestSSSRKekule(t *testing.T) {
+	// Kekule benzene is aromatic as well, without aromatizing the molecule itself
+	mol, err := indigoInit.LoadMoleculeFromString("C1=CC=CC=C1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	rings, err := mol.SSSR()
+	if err != nil {
+		t.Fatalf("SSSR failed: %v", err)
+	}
+	if len(rings) != 1 || !rings[0].Aromatic {
+		t.Fatalf("Expected one aromatic ring, got %+v", rings)
+	}
+
+	bond, err := mol.GetBond(0)
+	if err != nil {
+		t.Fatalf("Failed to get bond: %v", err)
+	}
+	defer bond.Close()
+	if order, _ := bond.Order(); order == molecule.BOND_AROMATIC {
+		t.Error("Expected the molecule to keep its Kekule bonds")
+	}
+}
+
+func TestRingsBySize(t *testing.T) {
+	// Cyclopropylbenzene
+	mol, err := indigoInit.LoadMoleculeFromString("C1CC1c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	small, err := mol.Rings(3, 3)
+	if err != nil {
+		t.Fatalf("Rings failed: %v", err)
+	}
+	if len(small) != 1 {
+		t.Fatalf("Expected 1 three-membered ring, got %d", len(small))
+	}
+	if small[0].Aromatic {
+		t.Error("Expected cyclopropane ring not to be aromatic")
+	}
+
+	all, err := mol.Rings(3, 8)
+	if err != nil {
+		t.Fatalf("Rings failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("Expected 2 rings, got %d", len(all))
+	}
+}
+
+func TestSubtrees(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCCC")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	subtrees, err := mol.Subtrees(2, 2)
+	if err != nil {
+		t.Fatalf("Subtrees failed: %v", err)
+	}
+
+	// Butane has three two-atom fragments, one per bond
+	if len(subtrees) != 3 {
+		t.Fatalf("Expected 3 subtrees, got %d", len(subtrees))
+	}
+	for _, st := range subtrees {
+		if len(st.Atoms) != 2 || len(st.Bonds) != 1 {
+			t.Errorf("Expected 2 atoms and 1 bond, got %v", st)
+		}
+	}
+}
+
+func TestRingSystems(t *testing.T) {
+	// Naphthalene linked to cyclohexane: two ring systems
+	mol, err := indigoInit.LoadMoleculeFromString("c1ccc2cc(C3CCCCC3)ccc2c1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	systems, err := mol.RingSystems()
+	if err != nil {
+		t.Fatalf("RingSystems failed: %v", err)
+	}
+
+	if len(systems) != 2 {
+		t.Fatalf("Expected 2 ring systems, got %d", len(systems))
+	}
+
+	var fused, single int
+	for _, sys := range systems {
+		switch len(sys.Rings) {
+		case 2:
+			fused++
+			if !sys.Aromatic || sys.AromaticRings != 2 {
+				t.Errorf("Expected aromatic naphthalene system, got %+v", sys)
+			}
+			if len(sys.Atoms) != 10 {
+				t.Errorf("Expected 10 atoms in naphthalene system, got %d", len(sys.Atoms))
+			}
+		case 1:
+			single++
+			if sys.Aromatic {
+				t.Error("Expected cyclohexane system not to be aromatic")
+			}
+		}
+	}
+
+	if fused != 1 || single != 1 {
+		t.Errorf("Expected one fused and one single ring system, got %d and %d", fused, single)
+	}
+}
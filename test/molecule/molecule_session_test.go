@@ -0,0 +1,139 @@
+// Package molecule_test provides tests for binding molecules to their owning session
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_session_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/core"
+)
+
+func TestSessionMixedObjects(t *testing.T) {
+	other, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer other.Close()
+
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	foreign, err := other.LoadMoleculeFromString("C")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer foreign.Close()
+
+	if err := mol.Merge(foreign); err == nil {
+		t.Error("Expected error for merging a molecule of another session")
+	}
+	if _, err := mol.HasSubstructure(foreign, nil); err == nil {
+		t.Error("Expected error for matching a molecule of another session")
+	}
+
+	// Both molecules stay usable in their own sessions
+	if count, err := mol.CountAtoms(); err != nil || count != 3 {
+		t.Errorf("Expected 3 atoms, got %d (%v)", count, err)
+	}
+	if count, err := foreign.CountAtoms(); err != nil || count != 1 {
+		t.Errorf("Expected 1 atom, got %d (%v)", count, err)
+	}
+}
+
+func TestSessionClosed(t *testing.T) {
+	other, err := core.IndigoInit()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	mol, err := other.LoadMoleculeFromString("c1ccccc1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+
+	other.Close()
+
+	if _, err := mol.CountAtoms(); err == nil {
+		t.Error("Expected error for using a molecule of a closed session")
+	}
+	if _, err := other.LoadMoleculeFromString("C"); err == nil {
+		t.Error("Expected error for loading into a closed session")
+	}
+	if err := mol.Close(); err != nil {
+		t.Errorf("Expected molecule of a closed session to close cleanly, got %v", err)
+	}
+}
+
+func TestSessionConcurrent(t *testing.T) {
+	const workers = 4
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			in, err := core.IndigoInit()
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer in.Close()
+
+			for i := 0; i < 50; i++ {
+				// Every worker uses a chain of a different length
+				mol, err := in.LoadMoleculeFromString(strings.Repeat("C", w+2))
+				if err != nil {
+					errs <- err
+					return
+				}
+				count, err := mol.CountAtoms()
+				mol.Close()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if count != w+2 {
+					errs <- fmt.Errorf("worker %d: expected %d atoms, got %d", w, w+2, count)
+					return
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSessionPublic(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if mol.Session() != indigoInit.Session() {
+		t.Error("Expected the molecule to belong to the session that loaded it")
+	}
+
+	var s *core.Session = mol.Session()
+	if s.ID() != indigoInit.GetSessionID() || s.Closed() {
+		t.Errorf("Expected open session %d, got %d", indigoInit.GetSessionID(), s.ID())
+	}
+}
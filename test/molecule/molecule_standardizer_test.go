@@ -0,0 +1,147 @@
+// Package molecule_test provides tests for the standardization pipeline
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_standardizer_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func canonicalSmiles(t *testing.T, smiles string) string {
+	t.Helper()
+
+	mol, err := indigoInit.LoadMoleculeFromString(smiles)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", smiles, err)
+	}
+	defer mol.Close()
+
+	canonical, err := mol.ToCanonicalSmiles()
+	if err != nil {
+		t.Fatalf("Failed to get canonical SMILES: %v", err)
+	}
+	return canonical
+}
+
+func TestStandardizerReport(t *testing.T) {
+	s, err := molecule.NewStandardizer(
+		molecule.StripSaltsStep(nil),
+		molecule.NeutralizeStep(),
+		molecule.ClearStereoStep(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create standardizer: %v", err)
+	}
+
+	// Sodium salt of an achiral carboxylic acid: the stereo step has nothing to do
+	mol, err := indigoInit.LoadMoleculeFromString("CCC(=O)[O-].[Na+]")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	parent, report, err := s.Run(mol)
+	if err != nil {
+		t.Fatalf("Failed to standardize: %v", err)
+	}
+	defer parent.Close()
+
+	if want := canonicalSmiles(t, "CCC(=O)O"); report.Output != want {
+		t.Errorf("Expected %s, got %s", want, report.Output)
+	}
+	if !report.Changed() {
+		t.Error("Expected the report to show a change")
+	}
+
+	changed := report.ChangedSteps()
+	if len(changed) != 2 || changed[0] != molecule.STEP_STRIP_SALTS || changed[1] != molecule.STEP_NEUTRALIZE {
+		t.Errorf("Expected strip-salts and neutralize to change the molecule, got %v", changed)
+	}
+	if len(report.Steps) != 3 || report.Steps[0].Removed[0] != "[Na+]" {
+		t.Errorf("Unexpected step results: %+v", report.Steps)
+	}
+
+	smiles, _ := mol.ToSmiles()
+	if smiles != "CCC(=O)[O-].[Na+]" {
+		t.Errorf("Expected the input to be unchanged, got %s", smiles)
+	}
+}
+
+func TestNeutralizeKeepsHypervalentAndMetalCentres(t *testing.T) {
+	s, err := molecule.NewStandardizer(molecule.NeutralizeStep())
+	if err != nil {
+		t.Fatalf("Failed to create standardizer: %v", err)
+	}
+
+	cases := map[string]string{
+		"[B-](F)(F)(F)F":               "[B-](F)(F)(F)F",     // tetrafluoroborate does not become BH(F)4
+		"F[P-](F)(F)(F)(F)F":           "F[P-](F)(F)(F)(F)F", // hexafluorophosphate stays hypervalent
+		"[Na+].[O-]c1ccccc1":           "[Na+].[O-]c1ccccc1", // the metal ion keeps its counter-ion
+		"[Zn+2].CC(=O)[O-].CC(=O)[O-]": "[Zn+2].CC(=O)[O-].CC(=O)[O-]",
+	}
+
+	for input, expected := range cases {
+		mol, err := indigoInit.LoadMoleculeFromString(input)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", input, err)
+		}
+
+		result, report, err := s.Run(mol)
+		mol.Close()
+		if err != nil {
+			t.Fatalf("Failed to standardize %s: %v", input, err)
+		}
+		result.Close()
+
+		if want := canonicalSmiles(t, expected); report.Output != want {
+			t.Errorf("%s: expected %s, got %s", input, want, report.Output)
+		}
+	}
+}
+
+func TestNeutralizeKeepsChargeSeparation(t *testing.T) {
+	s, err := molecule.NewStandardizer(molecule.NeutralizeStep())
+	if err != nil {
+		t.Fatalf("Failed to create standardizer: %v", err)
+	}
+
+	cases := map[string]string{
+		"C[N+](=O)[O-]":         "C[N+](=O)[O-]",         // nitro stays charge-separated
+		"C[N+](C)(C)CC(=O)[O-]": "C[N+](C)(C)CC(=O)[O-]", // betaine keeps the carboxylate
+		"CC[NH3+]":              "CCN",                   // ammonium loses a hydrogen
+		"[O-]c1ccccc1":          "Oc1ccccc1",             // phenolate gains a hydrogen
+		"[H][N+]([H])([H])CC":   "[H]N([H])CC",           // explicit hydrogens are removed as atoms
+	}
+
+	for input, expected := range cases {
+		mol, err := indigoInit.LoadMoleculeFromString(input)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", input, err)
+		}
+
+		result, report, err := s.Run(mol)
+		mol.Close()
+		if err != nil {
+			t.Fatalf("Failed to standardize %s: %v", input, err)
+		}
+		result.Close()
+
+		if want := canonicalSmiles(t, expected); report.Output != want {
+			t.Errorf("%s: expected %s, got %s", input, want, report.Output)
+		}
+	}
+}
+
+func TestStandardizerUnknownStep(t *testing.T) {
+	_, err := molecule.NewStandardizer(molecule.StandardizeStep{Kind: "bogus"})
+	if err == nil {
+		t.Error("Expected error for unknown step")
+	}
+}
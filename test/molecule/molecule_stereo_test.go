@@ -0,0 +1,135 @@
+// Package molecule_test provides tests for stereochemistry
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_stereo_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestStereocenters(t *testing.T) {
+	// L-alanine
+	mol, err := indigoInit.LoadMoleculeFromString("C[C@@H](C(=O)O)N")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	count, err := mol.CountStereocenters()
+	if err != nil {
+		t.Fatalf("CountStereocenters failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1 stereocenter, got %d", count)
+	}
+
+	centers, err := mol.Stereocenters()
+	if err != nil {
+		t.Fatalf("Stereocenters failed: %v", err)
+	}
+	if len(centers) != 1 {
+		t.Fatalf("Expected 1 stereocenter, got %d", len(centers))
+	}
+
+	center := centers[0]
+	if center.AtomIndex != 1 {
+		t.Errorf("Expected stereocenter on atom 1, got %d", center.AtomIndex)
+	}
+	if center.Type != molecule.STEREO_ABS {
+		t.Errorf("Expected STEREO_ABS, got %d", center.Type)
+	}
+	if center.CIP != "S" {
+		t.Errorf("Expected L-alanine to be S, got %q", center.CIP)
+	}
+}
+
+func TestInvertStereo(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("C[C@@H](C(=O)O)N")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if err := mol.InvertStereo(); err != nil {
+		t.Fatalf("InvertStereo failed: %v", err)
+	}
+
+	centers, err := mol.Stereocenters()
+	if err != nil {
+		t.Fatalf("Stereocenters failed: %v", err)
+	}
+	if len(centers) != 1 || centers[0].CIP != "R" {
+		t.Errorf("Expected inverted alanine to be R, got %+v", centers)
+	}
+
+	if err := mol.ClearStereocenters(); err != nil {
+		t.Fatalf("ClearStereocenters failed: %v", err)
+	}
+
+	count, _ := mol.CountStereocenters()
+	if count != 0 {
+		t.Errorf("Expected 0 stereocenters after clearing, got %d", count)
+	}
+}
+
+func TestStereocenterGroup(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("C[C@@H](C(=O)O)N")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if err := mol.SetStereocenterGroup(1, molecule.STEREO_OR, 1); err != nil {
+		t.Fatalf("SetStereocenterGroup failed: %v", err)
+	}
+
+	centers, err := mol.Stereocenters()
+	if err != nil {
+		t.Fatalf("Stereocenters failed: %v", err)
+	}
+	if len(centers) != 1 {
+		t.Fatalf("Expected 1 stereocenter, got %d", len(centers))
+	}
+	if centers[0].Type != molecule.STEREO_OR || centers[0].Group != 1 {
+		t.Errorf("Expected OR group 1, got type %d group %d", centers[0].Type, centers[0].Group)
+	}
+}
+
+func TestStereoBonds(t *testing.T) {
+	// (E)-2-butene
+	mol, err := indigoInit.LoadMoleculeFromString("C/C=C/C")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	bonds, err := mol.StereoBonds()
+	if err != nil {
+		t.Fatalf("StereoBonds failed: %v", err)
+	}
+	if len(bonds) != 1 {
+		t.Fatalf("Expected 1 stereo bond, got %d", len(bonds))
+	}
+	if bonds[0].Stereo != molecule.STEREO_TRANS {
+		t.Errorf("Expected STEREO_TRANS, got %d", bonds[0].Stereo)
+	}
+	if bonds[0].CIP != "E" {
+		t.Errorf("Expected E label, got %q", bonds[0].CIP)
+	}
+
+	if err := mol.ClearCisTrans(); err != nil {
+		t.Fatalf("ClearCisTrans failed: %v", err)
+	}
+
+	bonds, _ = mol.StereoBonds()
+	if len(bonds) != 0 {
+		t.Errorf("Expected no stereo bonds after clearing, got %d", len(bonds))
+	}
+}
@@ -0,0 +1,89 @@
+// Package molecule_test provides tests for tautomer enumeration
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_tautomer_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+func TestTautomers(t *testing.T) {
+	// 2-hydroxypyridine / 2-pyridone
+	mol, err := indigoInit.LoadMoleculeFromString("Oc1ccccn1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	tautomers, err := mol.Tautomers(molecule.TAUTOMER_INCHI)
+	if err != nil {
+		t.Fatalf("Tautomers failed: %v", err)
+	}
+	defer func() {
+		for _, taut := range tautomers {
+			taut.Close()
+		}
+	}()
+
+	if len(tautomers) < 2 {
+		t.Fatalf("Expected at least 2 tautomers, got %d", len(tautomers))
+	}
+
+	seen := make(map[string]bool)
+	for _, taut := range tautomers {
+		smiles, err := taut.ToCanonicalSmiles()
+		if err != nil {
+			t.Fatalf("Failed to get SMILES: %v", err)
+		}
+		seen[smiles] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Expected distinct tautomers, got %v", seen)
+	}
+}
+
+func TestTautomerKey(t *testing.T) {
+	hydroxy, err := indigoInit.LoadMoleculeFromString("Oc1ccccn1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer hydroxy.Close()
+
+	pyridone, err := indigoInit.LoadMoleculeFromString("O=C1C=CC=CN1")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer pyridone.Close()
+
+	key1, err := hydroxy.TautomerKey(molecule.TAUTOMER_INCHI)
+	if err != nil {
+		t.Fatalf("TautomerKey failed: %v", err)
+	}
+	key2, err := pyridone.TautomerKey(molecule.TAUTOMER_INCHI)
+	if err != nil {
+		t.Fatalf("TautomerKey failed: %v", err)
+	}
+
+	if key1 != key2 {
+		t.Errorf("Expected equal tautomer keys, got %q and %q", key1, key2)
+	}
+}
+
+func TestTautomerRules(t *testing.T) {
+	if err := indigoInit.SetTautomerRule(1, "N,O,P,S,As,Se,Sb,Te", "N,O,P,S,As,Se,Sb,Te"); err != nil {
+		t.Fatalf("SetTautomerRule failed: %v", err)
+	}
+	if err := indigoInit.RemoveTautomerRule(1); err != nil {
+		t.Fatalf("RemoveTautomerRule failed: %v", err)
+	}
+	if err := indigoInit.ClearTautomerRules(); err != nil {
+		t.Fatalf("ClearTautomerRules failed: %v", err)
+	}
+}
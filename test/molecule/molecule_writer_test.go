@@ -0,0 +1,192 @@
+// Package molecule_test provides tests for multi-record file writers
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : molecule_writer_test.go
+// @Software: GoLand
+package molecule_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cx-luo/go-indigo/indigoerr"
+)
+
+func TestSDFWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := indigoInit.NewSDFWriter(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create SDF writer: %v", err)
+	}
+
+	for i, smiles := range []string{"CCO", "c1ccccc1"} {
+		mol, err := indigoInit.LoadMoleculeFromString(smiles)
+		if err != nil {
+			t.Fatalf("Failed to load molecule: %v", err)
+		}
+		err = w.Write(mol, map[string]string{"ID": smiles, "ROW": strconv.Itoa(i)})
+		mol.Close()
+		if err != nil {
+			t.Fatalf("Failed to write molecule: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	if w.Count() != 2 {
+		t.Errorf("Expected 2 records written, got %d", w.Count())
+	}
+	if strings.Count(buf.String(), "$$$$") != 2 {
+		t.Errorf("Expected 2 record separators, got:\n%s", buf.String())
+	}
+
+	rd, err := indigoInit.IterateSDF(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create SDF reader: %v", err)
+	}
+	defer rd.Close()
+
+	var ids []string
+	for rd.Next() {
+		rec := rd.Record()
+		if rec.Err != nil {
+			t.Fatalf("Failed to read record %d: %v", rec.Index, rec.Err)
+		}
+		ids = append(ids, rec.Properties["ID"])
+		rec.Molecule.Close()
+	}
+	if len(ids) != 2 || ids[0] != "CCO" || ids[1] != "c1ccccc1" {
+		t.Errorf("Unexpected IDs read back: %v", ids)
+	}
+}
+
+func TestSDFWriterKeepsMolecule(t *testing.T) {
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	var buf bytes.Buffer
+	w, err := indigoInit.NewSDFWriter(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create SDF writer: %v", err)
+	}
+	if err := w.Write(mol, map[string]string{"ID": "MOL-1"}); err != nil {
+		t.Fatalf("Failed to write molecule: %v", err)
+	}
+
+	has, err := mol.HasProperty("ID")
+	if err != nil {
+		t.Fatalf("Failed to check property: %v", err)
+	}
+	if has {
+		t.Error("Expected written properties not to be set on the molecule")
+	}
+
+	if err := w.Write(nil, nil); !errors.Is(err, indigoerr.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid writing nil, got %v", err)
+	}
+
+	w.Close()
+	if err := w.Write(mol, nil); err == nil {
+		t.Error("Expected error writing to closed writer")
+	}
+}
+
+func TestSmilesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := indigoInit.NewSmilesWriter(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create SMILES writer: %v", err)
+	}
+
+	for _, smiles := range []string{"CCO", "CC(=O)O"} {
+		mol, err := indigoInit.LoadMoleculeFromString(smiles)
+		if err != nil {
+			t.Fatalf("Failed to load molecule: %v", err)
+		}
+		err = w.Write(mol)
+		mol.Close()
+		if err != nil {
+			t.Fatalf("Failed to write molecule: %v", err)
+		}
+	}
+	w.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	if strings.TrimSpace(lines[0]) != "CCO" {
+		t.Errorf("Expected CCO, got %q", lines[0])
+	}
+}
+
+func TestCMLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := indigoInit.NewCMLWriter(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create CML writer: %v", err)
+	}
+
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	if err := w.Write(mol, nil); err != nil {
+		t.Fatalf("Failed to write molecule: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	cml := buf.String()
+	if !strings.Contains(cml, "<cml") || !strings.Contains(cml, "</cml>") {
+		t.Errorf("Expected CML header and footer, got:\n%s", cml)
+	}
+	if !strings.Contains(cml, "<molecule") {
+		t.Errorf("Expected a molecule element, got:\n%s", cml)
+	}
+}
+
+func TestSDFFileWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.sdf")
+	w, err := indigoInit.NewSDFFileWriter(filename)
+	if err != nil {
+		t.Fatalf("Failed to create SDF file writer: %v", err)
+	}
+
+	mol, err := indigoInit.LoadMoleculeFromString("CCO")
+	if err != nil {
+		t.Fatalf("Failed to load molecule: %v", err)
+	}
+	defer mol.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := w.Write(mol, map[string]string{"ROW": strconv.Itoa(i)}); err != nil {
+			t.Fatalf("Failed to write molecule: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if strings.Count(string(data), "$$$$") != 3 || !strings.Contains(string(data), "<ROW>") {
+		t.Errorf("Expected 3 records with data fields, got:\n%s", data)
+	}
+}
@@ -0,0 +1,48 @@
+// Package reaction_test provides tests for reaction structure checking
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/16
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_check_test.go
+// @Software: GoLand
+package reaction_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+	"github.com/cx-luo/go-indigo/reaction"
+)
+
+// TestReactionCheck tests checking every component of a reaction
+func TestReactionCheck(t *testing.T) {
+	rxn, err := indigoInit.LoadReactionFromString("CC(=O)O.CCO>>CC(=O)OCC.C(C)(C)(C)(C)C")
+	if err != nil {
+		t.Fatalf("failed to load reaction: %v", err)
+	}
+	defer rxn.Close()
+
+	report, err := rxn.Check(molecule.CHECK_VALENCE)
+	if err != nil {
+		t.Fatalf("failed to check reaction: %v", err)
+	}
+
+	if len(report.Components) != 4 {
+		t.Fatalf("expected 4 checked components, got %d", len(report.Components))
+	}
+	if report.OK() {
+		t.Fatal("expected valence issue in the second product")
+	}
+
+	for _, c := range report.Components {
+		bad := c.Role == reaction.ROLE_PRODUCT && c.Index == 1
+		if bad == c.Report.OK() {
+			t.Errorf("%s %d: unexpected result %v", c.Role, c.Index, c.Report.Messages())
+		}
+	}
+
+	if len(report.Messages()) == 0 {
+		t.Error("expected at least one message")
+	}
+}
@@ -0,0 +1,218 @@
+// Package reaction_test provides tests for reaction product enumeration
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_enumerate_test.go
+// @Software: GoLand
+package reaction_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+	"github.com/cx-luo/go-indigo/reaction"
+)
+
+// TestEnumerateAmideCoupling tests enumerating an amide coupling over two reactant sets
+func TestEnumerateAmideCoupling(t *testing.T) {
+	template, err := indigoInit.LoadReactionSmartsFromString("[C:1](=[O:2])[OH].[N;H2:3]>>[C:1](=[O:2])[N:3]")
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+	defer template.Close()
+
+	acids := loadMolecules(t, "CC(=O)O", "OC(=O)c1ccccc1")
+	amines := loadMolecules(t, "CN", "NCC", "Nc1ccccc1")
+
+	if err := acids[0].SetName("acetic acid"); err != nil {
+		t.Fatalf("failed to set name: %v", err)
+	}
+
+	products, err := reaction.Enumerate(template, [][]*molecule.Molecule{acids, amines})
+	if err != nil {
+		t.Fatalf("failed to enumerate products: %v", err)
+	}
+	defer func() {
+		for _, p := range products {
+			p.Close()
+		}
+	}()
+
+	if len(products) != 6 {
+		t.Fatalf("expected 6 products, got %d", len(products))
+	}
+
+	seen := make(map[[2]int]bool)
+	for _, p := range products {
+		sources, err := reaction.Provenance(p)
+		if err != nil {
+			t.Fatalf("failed to get provenance: %v", err)
+		}
+		if len(sources) != 2 {
+			t.Fatalf("expected 2 reactants per product, got %v", sources)
+		}
+		if sources[0][0] != 0 || sources[1][0] != 1 {
+			t.Errorf("unexpected reactant sets %v", sources)
+		}
+		seen[[2]int{sources[0][1], sources[1][1]}] = true
+
+		count, err := p.CountProducts()
+		if err != nil {
+			t.Fatalf("failed to count products: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected 1 product molecule, got %d", count)
+		}
+	}
+
+	if len(seen) != 6 {
+		t.Errorf("expected 6 distinct reactant combinations, got %d", len(seen))
+	}
+
+	// Inputs keep their names
+	name, err := acids[0].Name()
+	if err != nil {
+		t.Fatalf("failed to get name: %v", err)
+	}
+	if name != "acetic acid" {
+		t.Errorf("expected input name to be unchanged, got %q", name)
+	}
+}
+
+// TestEnumerateProvenance tests that every reactant of a product comes from the position reported by Provenance
+func TestEnumerateProvenance(t *testing.T) {
+	template, err := indigoInit.LoadReactionSmartsFromString("[C:1](=[O:2])[OH].[N;H2:3]>>[C:1](=[O:2])[N:3]")
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+	defer template.Close()
+
+	sets := [][]*molecule.Molecule{
+		loadMolecules(t, "CC(=O)O", "OC(=O)c1ccccc1"),
+		loadMolecules(t, "CN", "Nc1ccccc1"),
+	}
+
+	products, err := reaction.Enumerate(template, sets)
+	if err != nil {
+		t.Fatalf("failed to enumerate products: %v", err)
+	}
+	defer func() {
+		for _, p := range products {
+			p.Close()
+		}
+	}()
+
+	if len(products) != 4 {
+		t.Fatalf("expected 4 products, got %d", len(products))
+	}
+
+	for i, p := range products {
+		sources, err := reaction.Provenance(p)
+		if err != nil {
+			t.Fatalf("failed to get provenance: %v", err)
+		}
+		reactants, err := p.GetAllReactants()
+		if err != nil {
+			t.Fatalf("failed to get reactants: %v", err)
+		}
+		if len(sources) != 2 || len(reactants) != 2 {
+			t.Fatalf("expected 2 reactants per product, got %v", sources)
+		}
+
+		for j, handle := range reactants {
+			reactant, err := indigoInit.LoadMoleculeFromHandle(handle)
+			if err != nil {
+				t.Fatalf("failed to load reactant: %v", err)
+			}
+			got, err := reactant.ToCanonicalSmiles()
+			reactant.Close()
+			if err != nil {
+				t.Fatalf("failed to get SMILES: %v", err)
+			}
+
+			set, index := sources[j][0], sources[j][1]
+			if set != j || index < 0 || index >= len(sets[set]) {
+				t.Fatalf("product %d: unexpected source %v for reactant %d", i, sources[j], j)
+			}
+			expected, err := sets[set][index].ToCanonicalSmiles()
+			if err != nil {
+				t.Fatalf("failed to get SMILES: %v", err)
+			}
+			if got != expected {
+				t.Errorf("product %d: reactant %d is %s, but its source %v is %s", i, j, got, sources[j], expected)
+			}
+		}
+
+		// Combinations are enumerated in order, the last set varying fastest
+		if sources[0][1] != i/2 || sources[1][1] != i%2 {
+			t.Errorf("product %d: expected sources [0 %d] [1 %d], got %v", i, i/2, i%2, sources)
+		}
+	}
+}
+
+// TestApplyTransform tests in-place SMARTS transformations of a molecule
+// TestProvenanceUntraced tests that reactants without a recorded source are reported as [-1, -1]
+func TestProvenanceUntraced(t *testing.T) {
+	rxn, err := indigoInit.LoadReactionFromString("CC(=O)O.CN>>CC(=O)NC")
+	if err != nil {
+		t.Fatalf("failed to load reaction: %v", err)
+	}
+	defer rxn.Close()
+
+	sources, err := reaction.Provenance(rxn)
+	if err != nil {
+		t.Fatalf("failed to get provenance: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(sources))
+	}
+	for i, source := range sources {
+		if source != [2]int{-1, -1} {
+			t.Errorf("reactant %d: expected [-1 -1], got %v", i, source)
+		}
+	}
+}
+
+func TestApplyTransform(t *testing.T) {
+	mols := loadMolecules(t, "C[N+](=O)[O-]", "CCO")
+	nitro := "[N+:1](=[O:2])[O-:3]>>[N+0:1](=[O:2])=[O:3]"
+
+	changed, err := mols[0].ApplyTransform(nitro)
+	if err != nil {
+		t.Fatalf("failed to apply transform: %v", err)
+	}
+	if !changed {
+		t.Error("expected nitro group to be transformed")
+	}
+
+	changed, err = mols[1].ApplyTransform(nitro)
+	if err != nil {
+		t.Fatalf("failed to apply transform: %v", err)
+	}
+	if changed {
+		t.Error("expected ethanol to be unchanged")
+	}
+}
+
+// loadMolecules loads molecules from SMILES and closes them when the test ends
+func loadMolecules(t *testing.T, smiles ...string) []*molecule.Molecule {
+	t.Helper()
+
+	mols := make([]*molecule.Molecule, 0, len(smiles))
+	for _, s := range smiles {
+		mol, err := indigoInit.LoadMoleculeFromString(s)
+		if err != nil {
+			t.Fatalf("failed to load molecule %s: %v", s, err)
+		}
+		mols = append(mols, mol)
+	}
+
+	t.Cleanup(func() {
+		for _, mol := range mols {
+			mol.Close()
+		}
+	})
+	return mols
+}
@@ -0,0 +1,47 @@
+// Package reaction_test provides tests for reaction fingerprints
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/15
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_fingerprint_test.go
+// @Software: GoLand
+package reaction_test
+
+import (
+	"testing"
+
+	"github.com/cx-luo/go-indigo/molecule"
+)
+
+// TestReactionFingerprint tests fingerprint calculation and comparison for reactions
+func TestReactionFingerprint(t *testing.T) {
+	r1, err := indigoInit.LoadReactionFromString("CC(=O)O.CCO>>CC(=O)OCC.O")
+	if err != nil {
+		t.Fatalf("failed to load reaction: %v", err)
+	}
+	defer r1.Close()
+
+	r2, err := indigoInit.LoadReactionFromString("CC(=O)O.CO>>CC(=O)OC.O")
+	if err != nil {
+		t.Fatalf("failed to load reaction: %v", err)
+	}
+	defer r2.Close()
+
+	fp1, err := r1.Fingerprint(molecule.FINGERPRINT_SIM)
+	if err != nil {
+		t.Fatalf("failed to calculate fingerprint: %v", err)
+	}
+	fp2, err := r2.Fingerprint(molecule.FINGERPRINT_SIM)
+	if err != nil {
+		t.Fatalf("failed to calculate fingerprint: %v", err)
+	}
+
+	sim, err := fp1.Tanimoto(fp2)
+	if err != nil {
+		t.Fatalf("failed to calculate similarity: %v", err)
+	}
+	if sim <= 0 || sim > 1 {
+		t.Errorf("expected similarity in (0, 1], got %f", sim)
+	}
+}
@@ -1,9 +1,12 @@
 package reaction_test
 
 import (
+	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/cx-luo/go-indigo/core"
+	"github.com/cx-luo/go-indigo/indigoerr"
 )
 
 var indigoInit *core.Indigo
@@ -197,3 +200,46 @@ func TestReactionGetMolecule(t *testing.T) {
 		t.Error("expected error when getting molecule beyond bounds")
 	}
 }
+
+func TestReactionProperties(t *testing.T) {
+	rxn, err := indigoInit.LoadReactionFromString("CCO>>CC=O")
+	if err != nil {
+		t.Fatalf("failed to load reaction: %v", err)
+	}
+	defer rxn.Close()
+
+	if err := rxn.SetProperties(map[string]string{"YIELD": "0.85", "STEPS": "2"}); err != nil {
+		t.Fatalf("failed to set properties: %v", err)
+	}
+
+	props, err := rxn.Properties()
+	if err != nil {
+		t.Fatalf("failed to list properties: %v", err)
+	}
+	if len(props) != 2 || props["STEPS"] != "2" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+
+	yield, err := rxn.PropertyFloat("YIELD")
+	if err != nil || yield != 0.85 {
+		t.Errorf("expected YIELD 0.85, got %v (%v)", yield, err)
+	}
+	if _, err := rxn.PropertyBool("STEPS"); !errors.Is(err, indigoerr.ErrParse) || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected ErrParse wrapping strconv.ErrSyntax for non-boolean property, got %v", err)
+	}
+
+	if err := rxn.RemoveProperty("STEPS"); err != nil {
+		t.Fatalf("failed to remove property: %v", err)
+	}
+	if has, _ := rxn.HasProperty("STEPS"); has {
+		t.Error("expected STEPS to be removed")
+	}
+
+	if err := rxn.ClearProperties(); err != nil {
+		t.Fatalf("failed to clear properties: %v", err)
+	}
+	props, _ = rxn.Properties()
+	if len(props) != 0 {
+		t.Errorf("expected no properties after clear, got %v", props)
+	}
+}
@@ -0,0 +1,68 @@
+// Package reaction_test provides tests for RDF readers and writers
+// coding=utf-8
+// @Project : go-indigo
+// @Time    : 2025/11/17
+// @Author  : chengxiang.luo
+// @Email   : chengxiang.luo@foxmail.com
+// @File    : reaction_writer_test.go
+// @Software: GoLand
+package reaction_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRDFWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := indigoInit.NewRDFWriter(&buf)
+	if err != nil {
+		t.Fatalf("failed to create RDF writer: %v", err)
+	}
+
+	for _, smiles := range []string{"CCO>>CC=O", "CC(=O)O.CN>>CC(=O)NC"} {
+		rxn, err := indigoInit.LoadReactionFromString(smiles)
+		if err != nil {
+			t.Fatalf("failed to load reaction: %v", err)
+		}
+		err = w.Write(rxn, map[string]string{"SOURCE": smiles})
+		rxn.Close()
+		if err != nil {
+			t.Fatalf("failed to write reaction: %v", err)
+		}
+	}
+	w.Close()
+
+	if !strings.HasPrefix(buf.String(), "$RDFILE") {
+		t.Errorf("expected RDF header, got:\n%s", buf.String())
+	}
+
+	rd, err := indigoInit.IterateRDF(&buf)
+	if err != nil {
+		t.Fatalf("failed to create RDF reader: %v", err)
+	}
+	defer rd.Close()
+
+	count := 0
+	for rd.Next() {
+		rec := rd.Record()
+		if rec.Err != nil {
+			t.Fatalf("failed to read record %d: %v", rec.Index, rec.Err)
+		}
+		if rec.Reaction == nil {
+			t.Fatalf("expected reaction in record %d", rec.Index)
+		}
+		if rec.Properties["SOURCE"] == "" {
+			t.Errorf("expected SOURCE property in record %d", rec.Index)
+		}
+		rec.Reaction.Close()
+		count++
+	}
+	if err := rd.Err(); err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 reactions, got %d", count)
+	}
+}